@@ -2,11 +2,10 @@ package config
 
 import (
 	"context"
-	"fmt"
-	"log"
+	"log/slog"
 	"os"
 	"time"
-    
+
     "go.mongodb.org/mongo-driver/bson"
 	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,8 +14,13 @@ import (
 
 var DB *mongo.Database
 
-func ConnectDB() {
+// LoadEnv loads variables from .env without overriding the real environment
+func LoadEnv() {
     godotenv.Load()
+}
+
+func ConnectDB() {
+    LoadEnv()
     mongoURI := os.Getenv("MONGODB_URI")
     
     // Set client options
@@ -28,15 +32,17 @@ func ConnectDB() {
     
     client, err := mongo.Connect(ctx, clientOptions)
     if err != nil {
-        log.Fatal("Failed to connect to MongoDB:", err)
+        slog.Error("failed to connect to MongoDB", "error", err)
+        os.Exit(1)
     }
     
     err = client.Ping(ctx, nil)
     if err != nil {
-        log.Fatal("Failed to ping MongoDB:", err)
+        slog.Error("failed to ping MongoDB", "error", err)
+        os.Exit(1)
     }
     
-    fmt.Println("✅ Connected to MongoDB!")
+    slog.Info("connected to MongoDB")
     
     DB = client.Database("builder_db")
 }
@@ -56,6 +62,59 @@ func CreateIndexes() {
     }
     _, err := componentCollection.Indexes().CreateOne(ctx, indexModel)
     if err != nil {
-        log.Println("Failed to create index:", err)
+        slog.Error("failed to create index", "collection", "components", "error", err)
+    }
+
+    // Components are looked up by the function they define and the
+    // functions they call, see the usages and dependencies endpoints
+    referenceIndexes := []mongo.IndexModel{
+        {Keys: bson.D{{Key: "language", Value: 1}, {Key: "function", Value: 1}}},
+        {Keys: bson.D{{Key: "language", Value: 1}, {Key: "calls", Value: 1}}},
+    }
+    _, err = componentCollection.Indexes().CreateMany(ctx, referenceIndexes)
+    if err != nil {
+        slog.Error("failed to create index", "collection", "components", "error", err)
+    }
+
+    // Template names are unique within a workspace
+    templateIndex := mongo.IndexModel{
+        Keys:    bson.D{{Key: "workspace", Value: 1}, {Key: "name", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    }
+    _, err = GetCollection("templates").Indexes().CreateOne(ctx, templateIndex)
+    if err != nil {
+        slog.Error("failed to create index", "collection", "templates", "error", err)
+    }
+
+    // Stages are referred to by name and run in order
+    stageIndexes := []mongo.IndexModel{
+        {Keys: bson.D{{Key: "name", Value: 1}}, Options: options.Index().SetUnique(true)},
+        {Keys: bson.D{{Key: "order", Value: 1}}, Options: options.Index().SetUnique(true)},
+    }
+    _, err = GetCollection("stages").Indexes().CreateMany(ctx, stageIndexes)
+    if err != nil {
+        slog.Error("failed to create index", "collection", "stages", "error", err)
+    }
+
+    // Custom type names are unique
+    typeIndex := mongo.IndexModel{
+        Keys:    bson.D{{Key: "name", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    }
+    _, err = GetCollection("types").Indexes().CreateOne(ctx, typeIndex)
+    if err != nil {
+        slog.Error("failed to create index", "collection", "types", "error", err)
+    }
+
+    // Datasets are listed per workspace, shared by content hash and swept
+    // once they expire
+    datasetIndexes := []mongo.IndexModel{
+        {Keys: bson.D{{Key: "workspace", Value: 1}, {Key: "created_at", Value: -1}}},
+        {Keys: bson.D{{Key: "sha256", Value: 1}}},
+        {Keys: bson.D{{Key: "expires_at", Value: 1}}},
+    }
+    _, err = GetCollection("datasets").Indexes().CreateMany(ctx, datasetIndexes)
+    if err != nil {
+        slog.Error("failed to create index", "collection", "datasets", "error", err)
     }
 }
\ No newline at end of file
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"log/slog"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"builder.ai/src/models"
+	"builder.ai/src/storage"
+)
+
+// DatasetSettings configures dataset storage and retention
+type DatasetSettings struct {
+	Dir           string        // DATASET_DIR, where the local blob store keeps files
+	MaxSize       int64         // DATASET_MAX_SIZE, in bytes
+	Retention     string        // DATASET_RETENTION, for uploads without a retention
+	TempRetention string        // DATASET_TEMP_RETENTION, for data sent inline with a workflow
+	SweepInterval time.Duration // DATASET_SWEEP_INTERVAL, how often expired datasets are removed
+}
+
+var (
+	datasetsOnce     sync.Once
+	datasetSettings  DatasetSettings
+	datasetBlobStore storage.BlobStore
+)
+
+// Datasets returns the dataset settings read from the environment
+func Datasets() DatasetSettings {
+	loadDatasets()
+	return datasetSettings
+}
+
+// BlobStore returns the store holding dataset contents
+func BlobStore() storage.BlobStore {
+	loadDatasets()
+	return datasetBlobStore
+}
+
+// Retentions used when DATASET_RETENTION or DATASET_TEMP_RETENTION is unset
+// or invalid
+const (
+	defaultDatasetRetention     = "30d"
+	defaultDatasetTempRetention = "24h"
+)
+
+func loadDatasets() {
+	datasetsOnce.Do(func() {
+		datasetSettings = readDatasetSettings()
+		datasetBlobStore = storage.NewLocalStore(datasetSettings.Dir)
+	})
+}
+
+// readDatasetSettings reads the settings from the environment, keeping the
+// default of a setting whose value is invalid
+func readDatasetSettings() DatasetSettings {
+	settings := DatasetSettings{
+		Dir:           envOr("DATASET_DIR", "data/datasets"),
+		MaxSize:       100 << 20,
+		Retention:     defaultDatasetRetention,
+		TempRetention: defaultDatasetTempRetention,
+		SweepInterval: time.Hour,
+	}
+	if raw := os.Getenv("DATASET_MAX_SIZE"); raw != "" {
+		if size, err := strconv.ParseInt(raw, 10, 64); err == nil && size > 0 {
+			settings.MaxSize = size
+		} else {
+			slog.Warn("ignoring DATASET_MAX_SIZE, expected a positive number of bytes", "value", raw)
+		}
+	}
+	if raw := os.Getenv("DATASET_SWEEP_INTERVAL"); raw != "" {
+		if interval, err := time.ParseDuration(raw); err == nil && interval > 0 {
+			settings.SweepInterval = interval
+		} else {
+			slog.Warn("ignoring DATASET_SWEEP_INTERVAL, expected a duration such as 1h", "value", raw)
+		}
+	}
+	for _, retention := range []struct {
+		key   string
+		value *string
+	}{
+		{"DATASET_RETENTION", &settings.Retention},
+		{"DATASET_TEMP_RETENTION", &settings.TempRetention},
+	} {
+		if raw := os.Getenv(retention.key); raw != "" {
+			if _, err := models.ParseRetention(raw); err == nil {
+				*retention.value = raw
+			} else {
+				slog.Warn("ignoring "+retention.key+", keeping "+*retention.value, "value", raw, "error", err)
+			}
+		}
+	}
+	return settings
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,18 @@
+package config
+
+import "testing"
+
+func TestReadDatasetSettings(t *testing.T) {
+	t.Setenv("DATASET_RETENTION", "7d")
+	t.Setenv("DATASET_TEMP_RETENTION", "2h")
+	if got := readDatasetSettings(); got.Retention != "7d" || got.TempRetention != "2h" {
+		t.Errorf("valid retentions: got %q and %q", got.Retention, got.TempRetention)
+	}
+
+	// A mistyped retention must not keep datasets forever
+	t.Setenv("DATASET_RETENTION", "30 days")
+	t.Setenv("DATASET_TEMP_RETENTION", "forever-ish")
+	if got := readDatasetSettings(); got.Retention != defaultDatasetRetention || got.TempRetention != defaultDatasetTempRetention {
+		t.Errorf("invalid retentions: got %q and %q, want the defaults", got.Retention, got.TempRetention)
+	}
+}
@@ -0,0 +1,239 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// migration changes existing documents once; applied migrations are
+// recorded by ID in the migrations collection
+type migration struct {
+	ID  string
+	Run func(ctx context.Context, db *mongo.Database) error
+}
+
+// migrations run in order, append new ones at the end
+var migrations = []migration{
+	{ID: "0001_component_roles", Run: backfillComponentRoles},
+	{ID: "0002_default_stages", Run: seedDefaultStages},
+	{ID: "0003_canonical_types", Run: canonicalizeComponentTypes},
+	{ID: "0004_component_references", Run: indexComponentReferences},
+	{ID: "0005_blob_references", Run: countBlobReferences},
+}
+
+// RunMigrations applies the migrations that have not run yet
+func RunMigrations() {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	applied := GetCollection("migrations")
+	for _, m := range migrations {
+		err := applied.FindOne(ctx, bson.M{"_id": m.ID}).Err()
+		if err == nil {
+			continue
+		}
+		if err != mongo.ErrNoDocuments {
+			slog.Error("failed to check migration", "migration", m.ID, "error", err)
+			return
+		}
+
+		if err := m.Run(ctx, DB); err != nil {
+			slog.Error("migration failed", "migration", m.ID, "error", err)
+			return
+		}
+		if _, err := applied.InsertOne(ctx, bson.M{"_id": m.ID, "applied_at": time.Now()}); err != nil {
+			slog.Error("failed to record migration", "migration", m.ID, "error", err)
+			return
+		}
+		slog.Info("applied migration", "migration", m.ID)
+	}
+}
+
+// backfillComponentRoles infers the role of components saved before roles
+// existed from their stage and function name
+func backfillComponentRoles(ctx context.Context, db *mongo.Database) error {
+	components := db.Collection("components")
+	cursor, err := components.Find(ctx, bson.M{"$or": bson.A{
+		bson.M{"role": bson.M{"$exists": false}},
+		bson.M{"role": ""},
+	}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var component models.Component
+		if err := cursor.Decode(&component); err != nil {
+			return err
+		}
+		stage, _ := utils.GetStage(component.Stage)
+		component.Role = utils.InferRole(utils.FunctionName(component.Code), stage.Order)
+
+		_, err := components.UpdateOne(ctx, bson.M{"_id": component.ID}, bson.M{"$set": bson.M{"role": component.Role}})
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component.ID.Hex(), err)
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("backfilled component roles", "components", updated)
+	return nil
+}
+
+// seedDefaultStages stores the built-in stages so they can be edited
+// through the stages API
+func seedDefaultStages(ctx context.Context, db *mongo.Database) error {
+	stages := db.Collection("stages")
+	count, err := stages.CountDocuments(ctx, bson.M{})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	now := time.Now()
+	var docs []interface{}
+	for _, stage := range utils.DefaultStages() {
+		docs = append(docs, models.Stage{StageDefinition: stage, CreatedAt: now, UpdatedAt: now})
+	}
+	if _, err := stages.InsertMany(ctx, docs); err != nil {
+		return err
+	}
+
+	slog.Info("seeded default stages", "stages", len(docs))
+	return nil
+}
+
+// canonicalizeComponentTypes replaces type aliases saved before the type
+// registry, such as "dataframe", with the canonical type names
+func canonicalizeComponentTypes(ctx context.Context, db *mongo.Database) error {
+	components := db.Collection("components")
+	cursor, err := components.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"inputs": 1, "output": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var component models.Component
+		if err := cursor.Decode(&component); err != nil {
+			return err
+		}
+		before := component.InputTypes()
+		output := ""
+		if component.Output != nil {
+			output = component.Output.Type
+		}
+
+		utils.NormalizeComponentTypes(&component)
+		changed := component.Output != nil && component.Output.Type != output
+		for _, input := range component.Inputs {
+			changed = changed || before[input.Name] != input.Type
+		}
+		if !changed {
+			continue
+		}
+
+		_, err := components.UpdateOne(ctx, bson.M{"_id": component.ID}, bson.M{"$set": bson.M{
+			"inputs": component.Inputs,
+			"output": component.Output,
+		}})
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component.ID.Hex(), err)
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("canonicalized component types", "components", updated)
+	return nil
+}
+
+// indexComponentReferences records the function defined and the functions
+// called by components saved before references were indexed
+func indexComponentReferences(ctx context.Context, db *mongo.Database) error {
+	components := db.Collection("components")
+	cursor, err := components.Find(ctx, bson.M{}, options.Find().SetProjection(bson.M{"code": 1, "language": 1}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	updated := 0
+	for cursor.Next(ctx) {
+		var component models.Component
+		if err := cursor.Decode(&component); err != nil {
+			return err
+		}
+		utils.IndexComponentReferences(&component)
+
+		_, err := components.UpdateOne(ctx, bson.M{"_id": component.ID}, bson.M{"$set": bson.M{
+			"function": component.Function,
+			"calls":    component.Calls,
+		}})
+		if err != nil {
+			return fmt.Errorf("component %s: %w", component.ID.Hex(), err)
+		}
+		updated++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("indexed component references", "components", updated)
+	return nil
+}
+
+// countBlobReferences records how many datasets share each blob, for
+// datasets stored before blobs were reference counted
+func countBlobReferences(ctx context.Context, db *mongo.Database) error {
+	cursor, err := db.Collection("datasets").Aggregate(ctx, mongo.Pipeline{
+		{{Key: "$group", Value: bson.M{"_id": "$sha256", "refs": bson.M{"$sum": 1}}}},
+	})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	blobs := db.Collection("blobs")
+	counted := 0
+	for cursor.Next(ctx) {
+		var blob struct {
+			Key  string `bson:"_id"`
+			Refs int    `bson:"refs"`
+		}
+		if err := cursor.Decode(&blob); err != nil {
+			return err
+		}
+		if blob.Key == "" {
+			continue
+		}
+
+		_, err := blobs.UpdateOne(ctx, bson.M{"_id": blob.Key}, bson.M{"$set": bson.M{"refs": blob.Refs}}, options.Update().SetUpsert(true))
+		if err != nil {
+			return fmt.Errorf("blob %s: %w", blob.Key, err)
+		}
+		counted++
+	}
+	if err := cursor.Err(); err != nil {
+		return err
+	}
+
+	slog.Info("counted blob references", "blobs", counted)
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+func TestBackfillComponentRoles(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("infers roles from stage and function name", func(mt *mtest.T) {
+		components := []struct {
+			id          primitive.ObjectID
+			stage, code string
+			want        string
+		}{
+			{primitive.NewObjectID(), "stage1", "def remove_outliers(df):\n    return df\n", "row_filter"},
+			{primitive.NewObjectID(), "stage2", "def train_test_split_data(df, target):\n    pass\n", "splitter"},
+			{primitive.NewObjectID(), "stage3", "def fit(X, y):\n    pass\n", "trainer"},
+			{primitive.NewObjectID(), "stage4", "def kfold_scores(model, X, y):\n    pass\n", "cross_validator"},
+		}
+
+		ns := mt.Coll.Database().Name() + ".components"
+		docs := make([]bson.D, len(components))
+		for i, component := range components {
+			docs[i] = bson.D{{Key: "_id", Value: component.id}, {Key: "stage", Value: component.stage}, {Key: "code", Value: component.code}}
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, docs...))
+		for range components {
+			mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}))
+		}
+
+		if err := backfillComponentRoles(context.Background(), mt.DB); err != nil {
+			mt.Fatal(err)
+		}
+
+		// The find is followed by one update per component
+		events := mt.GetAllStartedEvents()
+		if len(events) != 1+len(components) {
+			mt.Fatalf("%d commands, want %d", len(events), 1+len(components))
+		}
+		for i, component := range components {
+			update := events[1+i].Command.Lookup("updates").Array().Index(0).Value().Document()
+			if id := update.Lookup("q", "_id").ObjectID(); id != component.id {
+				mt.Errorf("update %d matches %s, want %s", i, id.Hex(), component.id.Hex())
+			}
+			if role := update.Lookup("u", "$set", "role").StringValue(); role != component.want {
+				mt.Errorf("%s: role = %q, want %q", component.code, role, component.want)
+			}
+		}
+	})
+
+	mt.Run("fails on a failed update", func(mt *mtest.T) {
+		ns := mt.Coll.Database().Name() + ".components"
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "stage", Value: "stage1"}}),
+			mtest.CreateCommandErrorResponse(mtest.CommandError{Code: 11600, Message: "interrupted"}),
+		)
+
+		if err := backfillComponentRoles(context.Background(), mt.DB); err == nil {
+			mt.Error("backfillComponentRoles() error = nil, want the update error")
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// defaultRegistryRefresh is how often the registries are reloaded when
+// REGISTRY_REFRESH_INTERVAL is not set
+const defaultRegistryRefresh = 30 * time.Second
+
+// RegistryRefreshInterval returns REGISTRY_REFRESH_INTERVAL, how long an
+// instance may serve stages and types another instance has since changed
+func RegistryRefreshInterval() time.Duration {
+	raw := os.Getenv("REGISTRY_REFRESH_INTERVAL")
+	if raw == "" {
+		return defaultRegistryRefresh
+	}
+	interval, err := time.ParseDuration(raw)
+	if err != nil || interval <= 0 {
+		slog.Warn("ignoring REGISTRY_REFRESH_INTERVAL, expected a duration such as 30s", "value", raw)
+		return defaultRegistryRefresh
+	}
+	return interval
+}
+
+// RefreshRegistries reloads the stages and types from the database every
+// interval until ctx is done. The stage and type handlers reload them on
+// every change, but only in the instance serving the change. A failed
+// reload keeps the previous registry.
+func RefreshRegistries(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloadRegistries(ctx)
+		}
+	}
+}
+
+func reloadRegistries(ctx context.Context) {
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if err := LoadStages(ctx); err != nil {
+		slog.Error("failed to reload stages", "error", err)
+	}
+	if err := LoadTypes(ctx); err != nil {
+		slog.Error("failed to reload types", "error", err)
+	}
+}
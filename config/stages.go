@@ -0,0 +1,39 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// LoadStages replaces the registered stages with the stages collection.
+// The built-in stages are registered while the collection is empty.
+func LoadStages(ctx context.Context) error {
+	cursor, err := GetCollection("stages").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "order", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var stored []models.Stage
+	if err := cursor.All(ctx, &stored); err != nil {
+		return err
+	}
+	if len(stored) == 0 {
+		return utils.SetStages(utils.DefaultStages())
+	}
+
+	stages := make([]utils.Stage, len(stored))
+	for i, stage := range stored {
+		stages[i] = stage.StageDefinition
+	}
+	if err := utils.SetStages(stages); err != nil {
+		return fmt.Errorf("stored stages: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package config
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"builder.ai/src/utils"
+)
+
+func TestLoadStages(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+	db := DB
+	t.Cleanup(func() {
+		DB = db
+		utils.SetStages(utils.DefaultStages())
+	})
+
+	stage := func(name string, order int) bson.D {
+		return bson.D{{Key: "name", Value: name}, {Key: "order", Value: order}, {Key: "roles", Value: bson.A{utils.RoleTransform}}}
+	}
+
+	mt.Run("registers the stored stages", func(mt *mtest.T) {
+		DB = mt.DB
+		ns := mt.DB.Name() + ".stages"
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch, stage("clean", 1), stage("publish", 2)))
+
+		if err := LoadStages(context.Background()); err != nil {
+			mt.Fatal(err)
+		}
+		if got := strings.Join(utils.StageNames(), ","); got != "clean,publish" {
+			mt.Errorf("StageNames() = %s, want clean,publish", got)
+		}
+	})
+
+	mt.Run("restores the built-in stages once the stored ones are gone", func(mt *mtest.T) {
+		DB = mt.DB
+		if err := utils.SetStages([]utils.Stage{{Name: "clean", Order: 1, Roles: []string{utils.RoleTransform}}}); err != nil {
+			mt.Fatal(err)
+		}
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".stages", mtest.FirstBatch))
+
+		if err := LoadStages(context.Background()); err != nil {
+			mt.Fatal(err)
+		}
+		if got := strings.Join(utils.StageNames(), ","); got != "stage1,stage2,stage3,stage4" {
+			mt.Errorf("StageNames() = %s, want the built-in stages", got)
+		}
+	})
+}
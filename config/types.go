@@ -0,0 +1,36 @@
+package config
+
+import (
+	"context"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// LoadTypes registers the user-defined types of the types collection next
+// to the built-in types
+func LoadTypes(ctx context.Context) error {
+	cursor, err := GetCollection("types").Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var stored []models.DataType
+	if err := cursor.All(ctx, &stored); err != nil {
+		return err
+	}
+
+	types := make([]utils.DataType, len(stored))
+	for i, t := range stored {
+		types[i] = t.DataTypeDefinition
+	}
+	if err := utils.SetCustomTypes(types); err != nil {
+		return fmt.Errorf("stored types: %w", err)
+	}
+	return nil
+}
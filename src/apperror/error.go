@@ -0,0 +1,143 @@
+// src/apperror/error.go
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"builder.ai/src/envelope"
+)
+
+// Kind classifies an error and decides its HTTP status
+type Kind string
+
+const (
+	KindBadRequest Kind = "bad_request"
+	KindValidation Kind = "validation"
+	KindNotFound   Kind = "not_found"
+	KindConflict   Kind = "conflict"
+	KindForbidden  Kind = "forbidden"
+	KindInternal   Kind = "internal"
+)
+
+// Stable machine-readable codes shared by several handlers
+const (
+	CodeInvalidJSON      = envelope.CodeInvalidJSON
+	CodeInvalidID        = envelope.CodeInvalidID
+	CodeValidationFailed = envelope.CodeValidationFailed
+	CodeInternal         = envelope.CodeInternal
+)
+
+// Status returns the HTTP status code for the kind
+func (k Kind) Status() int {
+	switch k {
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict:
+		return http.StatusConflict
+	case KindForbidden:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// FieldError describes a problem with a single request field, as it is
+// returned in the error envelope
+type FieldError = envelope.FieldError
+
+// Error is a domain error carrying a stable code and a client-safe message.
+// The wrapped Err is only logged, never returned to clients.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s: %v", e.Code, e.Message, e.Err)
+	}
+	return fmt.Sprintf("%s: %s", e.Code, e.Message)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the HTTP status code for the error
+func (e *Error) Status() int {
+	return e.Kind.Status()
+}
+
+// Wrap attaches an underlying cause to the error
+func (e *Error) Wrap(err error) *Error {
+	e.Err = err
+	return e
+}
+
+// BadRequest reports a malformed request
+func BadRequest(code, message string) *Error {
+	return &Error{Kind: KindBadRequest, Code: code, Message: message}
+}
+
+// NotFound reports a missing resource
+func NotFound(code, message string) *Error {
+	return &Error{Kind: KindNotFound, Code: code, Message: message}
+}
+
+// Conflict reports a write that clashes with existing state
+func Conflict(code, message string) *Error {
+	return &Error{Kind: KindConflict, Code: code, Message: message}
+}
+
+// Forbidden reports an operation the caller may not perform
+func Forbidden(code, message string) *Error {
+	return &Error{Kind: KindForbidden, Code: code, Message: message}
+}
+
+// Validation reports field-level problems with the request body
+func Validation(message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: CodeValidationFailed, Message: message, Fields: fields}
+}
+
+// Internal hides err behind a generic message
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "Internal server error", Err: err}
+}
+
+// InvalidID reports a path ID that is not a valid ObjectID
+func InvalidID() *Error {
+	return BadRequest(CodeInvalidID, "Invalid ID format")
+}
+
+// Field builds a FieldError
+func Field(field, code, message string) FieldError {
+	return FieldError{Field: field, Code: code, Message: message}
+}
+
+// PrefixFields returns fields with prefix prepended to every field path
+func PrefixFields(prefix string, fields []FieldError) []FieldError {
+	prefixed := make([]FieldError, len(fields))
+	for i, f := range fields {
+		f.Field = prefix + f.Field
+		prefixed[i] = f
+	}
+	return prefixed
+}
+
+// As converts any error into an *Error, treating unknown errors as internal
+func As(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
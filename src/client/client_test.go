@@ -0,0 +1,195 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return New(server.URL, WithRetries(2, time.Millisecond, 5*time.Millisecond))
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func TestGetComponent(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/components/abc" {
+			t.Errorf("path = %s", r.URL.Path)
+		}
+		writeJSON(w, http.StatusOK, models.Component{Name: "Remove Duplicates", Stage: "stage1"})
+	})
+
+	component, err := c.GetComponent(context.Background(), "abc")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if component.Name != "Remove Duplicates" || component.Stage != "stage1" {
+		t.Fatalf("unexpected component: %+v", component)
+	}
+}
+
+func TestTypedErrors(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Request-ID", "req-1")
+		writeJSON(w, http.StatusNotFound, map[string]interface{}{
+			"error": map[string]interface{}{"code": "component_not_found", "message": "Component not found"},
+		})
+	})
+
+	_, err := c.GetComponent(context.Background(), "missing")
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("err = %v, want ErrNotFound", err)
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err is %T, want *APIError", err)
+	}
+	if apiErr.Code != "component_not_found" || apiErr.RequestID != "req-1" {
+		t.Fatalf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestValidationDetails(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusUnprocessableEntity, map[string]interface{}{
+			"error": map[string]interface{}{
+				"code":    "validation_failed",
+				"message": "Invalid component",
+				"details": []map[string]string{{"field": "stage", "code": "oneof", "message": "must be one of: stage1"}},
+			},
+		})
+	})
+
+	_, err := c.CreateComponent(context.Background(), models.Component{Name: "x"})
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || !errors.Is(err, ErrValidation) {
+		t.Fatalf("err = %v, want validation APIError", err)
+	}
+	if len(apiErr.Details) != 1 || apiErr.Details[0].Field != "stage" {
+		t.Fatalf("details = %+v", apiErr.Details)
+	}
+}
+
+func TestRetriesIdempotentRequests(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]interface{}{"count": 0, "users": []models.User{}})
+	})
+
+	if _, err := c.ListUsers(context.Background()); err != nil {
+		t.Fatalf("ListUsers: %v", err)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestDoesNotRetryPost(t *testing.T) {
+	var calls int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	_, err := c.CreateUser(context.Background(), models.User{Name: "a", Email: "a@example.com"})
+	if !errors.Is(err, ErrServer) {
+		t.Fatalf("err = %v, want ErrServer", err)
+	}
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestContextCancellationStopsRetries(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	c.minBackoff, c.maxBackoff = time.Hour, time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := c.ListUsers(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v, want deadline exceeded", err)
+	}
+}
+
+func TestSearchAllComponentsPaginates(t *testing.T) {
+	const totalPages = 3
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("name") != "rem" {
+			t.Errorf("name = %q", r.URL.Query().Get("name"))
+		}
+		page, _ := strconv.Atoi(r.URL.Query().Get("page"))
+		writeJSON(w, http.StatusOK, ComponentPage{
+			Data: []models.Component{{Name: "page" + strconv.Itoa(page)}},
+			Pagination: Pagination{
+				Page: page, Limit: 1, Total: totalPages, TotalPages: totalPages,
+				HasNext: page < totalPages, HasPrev: page > 1,
+			},
+		})
+	})
+
+	var names []string
+	for component, err := range c.SearchAllComponents(context.Background(), SearchComponentsOptions{Name: "rem", Limit: 1}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, component.Name)
+	}
+
+	if len(names) != totalPages || names[0] != "page1" || names[2] != "page3" {
+		t.Fatalf("names = %v", names)
+	}
+}
+
+func TestGenerateScriptSendsEncodedWorkflow(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var request models.GenerateScriptRequest
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			t.Fatal(err)
+		}
+		var workflow utils.WorkflowConfig
+		if err := json.Unmarshal([]byte(request.WorkflowConfig), &workflow); err != nil {
+			t.Fatalf("workflow_config is not JSON: %v", err)
+		}
+		if len(workflow.Nodes) != 1 || request.ComponentCode != "def f(x): return x" {
+			t.Errorf("unexpected request: %+v", request)
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"script": "#!/usr/bin/env python3"})
+	})
+
+	script, err := c.GenerateScript(context.Background(), utils.WorkflowConfig{
+		Version: "1.0",
+		Nodes:   []utils.Node{{ID: "n1", Name: "f", Stage: 1, Code: "f"}},
+	}, "def f(x): return x")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if script != "#!/usr/bin/env python3" {
+		t.Fatalf("script = %q", script)
+	}
+}
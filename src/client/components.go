@@ -0,0 +1,266 @@
+// src/client/components.go
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// ListComponentsOptions filters ListComponents; empty fields are ignored
+type ListComponentsOptions struct {
+	Stage      string
+	Role       string
+	Language   string
+	OutputType string
+	HasOutput  *bool
+}
+
+// SearchComponentsOptions controls SearchComponents
+type SearchComponentsOptions struct {
+	Name  string // required name prefix
+	Stage string
+	Page  int
+	Limit int
+	Sort  string
+	Desc  bool
+	// Verified keeps only components whose latest test run passed
+	Verified bool
+}
+
+// Pagination describes a page of search results
+type Pagination struct {
+	Page       int   `json:"page"`
+	Limit      int   `json:"limit"`
+	Total      int64 `json:"total"`
+	TotalPages int   `json:"totalPages"`
+	HasNext    bool  `json:"hasNext"`
+	HasPrev    bool  `json:"hasPrev"`
+}
+
+// ComponentPage is one page of SearchComponents results
+type ComponentPage struct {
+	Data       []models.Component `json:"data"`
+	Pagination Pagination         `json:"pagination"`
+}
+
+// StageStat is the number of components in a stage
+type StageStat struct {
+	Stage string `json:"_id"`
+	Count int    `json:"count"`
+}
+
+// Language is a language components can be written in, with the checker
+// the server compiles their code with
+type Language struct {
+	Name      string `json:"name"`
+	Extension string `json:"extension"`
+	Image     string `json:"image"`
+	Checker   string `json:"checker"`
+}
+
+type componentList struct {
+	Count      int                `json:"count"`
+	Components []models.Component `json:"components"`
+}
+
+// ListComponents returns all components matching opts, newest first
+func (c *Client) ListComponents(ctx context.Context, opts ListComponentsOptions) ([]models.Component, error) {
+	query := url.Values{}
+	setIf(query, "stage", opts.Stage)
+	setIf(query, "role", opts.Role)
+	setIf(query, "language", opts.Language)
+	setIf(query, "output_type", opts.OutputType)
+	if opts.HasOutput != nil {
+		query.Set("has_output", strconv.FormatBool(*opts.HasOutput))
+	}
+
+	var out componentList
+	if err := c.do(ctx, http.MethodGet, "/components", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Components, nil
+}
+
+// GetComponent returns the component with the given ID
+func (c *Client) GetComponent(ctx context.Context, id string) (*models.Component, error) {
+	var out models.Component
+	if err := c.do(ctx, http.MethodGet, "/components/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateComponent creates a single component and returns it with its ID
+func (c *Client) CreateComponent(ctx context.Context, component models.Component) (*models.Component, error) {
+	var out componentList
+	if err := c.do(ctx, http.MethodPost, "/components", nil, component, &out); err != nil {
+		return nil, err
+	}
+	if len(out.Components) == 0 {
+		return nil, ErrServer
+	}
+	return &out.Components[0], nil
+}
+
+// CreateComponents creates several components in one request
+func (c *Client) CreateComponents(ctx context.Context, components []models.Component) ([]models.Component, error) {
+	var out componentList
+	if err := c.do(ctx, http.MethodPost, "/components", nil, components, &out); err != nil {
+		return nil, err
+	}
+	return out.Components, nil
+}
+
+// UpdateComponent replaces the fields of the component with the given ID
+func (c *Client) UpdateComponent(ctx context.Context, id string, component models.Component) error {
+	return c.do(ctx, http.MethodPut, "/components/"+url.PathEscape(id), nil, component, &MessageResponse{})
+}
+
+// DeleteComponent deletes the component with the given ID
+func (c *Client) DeleteComponent(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/components/"+url.PathEscape(id), nil, nil, &MessageResponse{})
+}
+
+// ForceUpdateComponent updates the component even when components calling
+// its function would break, and returns warnings naming them
+func (c *Client) ForceUpdateComponent(ctx context.Context, id string, component models.Component) ([]string, error) {
+	var out MessageResponse
+	if err := c.do(ctx, http.MethodPut, "/components/"+url.PathEscape(id), url.Values{"force": {"true"}}, component, &out); err != nil {
+		return nil, err
+	}
+	return out.Warnings, nil
+}
+
+// ForceDeleteComponent deletes the component even when components calling
+// its function would break, and returns warnings naming them
+func (c *Client) ForceDeleteComponent(ctx context.Context, id string) ([]string, error) {
+	var out MessageResponse
+	if err := c.do(ctx, http.MethodDelete, "/components/"+url.PathEscape(id), url.Values{"force": {"true"}}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Warnings, nil
+}
+
+// ListComponentUsages returns the components whose code calls the function
+// of the component with the given ID
+func (c *Client) ListComponentUsages(ctx context.Context, id string) ([]models.Component, error) {
+	var out componentList
+	if err := c.do(ctx, http.MethodGet, "/components/"+url.PathEscape(id)+"/usages", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Components, nil
+}
+
+// ListComponentDependencies returns the components defining functions the
+// code of the component with the given ID calls
+func (c *Client) ListComponentDependencies(ctx context.Context, id string) ([]models.Component, error) {
+	var out componentList
+	if err := c.do(ctx, http.MethodGet, "/components/"+url.PathEscape(id)+"/dependencies", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Components, nil
+}
+
+// SearchComponents returns one page of components whose name starts with opts.Name
+func (c *Client) SearchComponents(ctx context.Context, opts SearchComponentsOptions) (*ComponentPage, error) {
+	query := url.Values{}
+	query.Set("name", opts.Name)
+	setIf(query, "stage", opts.Stage)
+	setIf(query, "sort", opts.Sort)
+	if opts.Page > 0 {
+		query.Set("page", strconv.Itoa(opts.Page))
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Desc {
+		query.Set("order", "desc")
+	}
+	if opts.Verified {
+		query.Set("verified", "true")
+	}
+
+	var out ComponentPage
+	if err := c.do(ctx, http.MethodGet, "/components/search", query, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// SearchAllComponents iterates over every search result, fetching pages
+// lazily starting at opts.Page. Iteration stops at the first error.
+func (c *Client) SearchAllComponents(ctx context.Context, opts SearchComponentsOptions) iter.Seq2[models.Component, error] {
+	return func(yield func(models.Component, error) bool) {
+		if opts.Page < 1 {
+			opts.Page = 1
+		}
+		for {
+			page, err := c.SearchComponents(ctx, opts)
+			if err != nil {
+				yield(models.Component{}, err)
+				return
+			}
+			for _, component := range page.Data {
+				if !yield(component, nil) {
+					return
+				}
+			}
+			if !page.Pagination.HasNext || len(page.Data) == 0 {
+				return
+			}
+			opts.Page++
+		}
+	}
+}
+
+// TestRunResponse is the outcome of RunComponentTests
+type TestRunResponse struct {
+	Message  string                 `json:"message"`
+	ID       string                 `json:"id"`
+	Verified bool                   `json:"verified"`
+	Run      utils.ComponentTestRun `json:"run"`
+}
+
+// RunComponentTests runs the tests of the component with the given ID and
+// returns the recorded run
+func (c *Client) RunComponentTests(ctx context.Context, id string) (*TestRunResponse, error) {
+	var out TestRunResponse
+	if err := c.do(ctx, http.MethodPost, "/components/"+url.PathEscape(id)+"/test", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetStageStats returns the number of components per stage
+func (c *Client) GetStageStats(ctx context.Context) ([]StageStat, error) {
+	var out struct {
+		Stats []StageStat `json:"stats"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/components/stats", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Stats, nil
+}
+
+// ListLanguages returns the languages components can be written in
+func (c *Client) ListLanguages(ctx context.Context) ([]Language, error) {
+	var out struct {
+		Languages []Language `json:"languages"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/components/languages", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Languages, nil
+}
+
+func setIf(query url.Values, key, value string) {
+	if value != "" {
+		query.Set(key, value)
+	}
+}
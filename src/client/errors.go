@@ -0,0 +1,80 @@
+// src/client/errors.go
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"builder.ai/src/envelope"
+)
+
+// Sentinel errors matched by APIError.Is, e.g. errors.Is(err, client.ErrNotFound)
+var (
+	ErrBadRequest = errors.New("bad request")
+	ErrValidation = errors.New("validation failed")
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrForbidden  = errors.New("forbidden")
+	ErrServer     = errors.New("server error")
+)
+
+// APIError is the decoded error envelope of a failed request
+type APIError struct {
+	StatusCode int
+	Code       string
+	Message    string
+	Details    []envelope.FieldError
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("builder api: %d %s: %s", e.StatusCode, e.Code, e.Message)
+	for _, d := range e.Details {
+		msg += fmt.Sprintf("; %s %s", d.Field, d.Message)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request %s)", e.RequestID)
+	}
+	return msg
+}
+
+// Is maps the status code to the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrValidation:
+		return e.StatusCode == http.StatusUnprocessableEntity
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrServer:
+		return e.StatusCode >= 500
+	}
+	return false
+}
+
+func newAPIError(resp *http.Response, body []byte) *APIError {
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Code:       "http_error",
+		Message:    http.StatusText(resp.StatusCode),
+		RequestID:  resp.Header.Get(envelope.RequestIDHeader),
+	}
+
+	var envelope envelope.ErrorResponse
+	if err := json.Unmarshal(body, &envelope); err == nil && envelope.Error.Code != "" {
+		apiErr.Code = envelope.Error.Code
+		apiErr.Message = envelope.Error.Message
+		apiErr.Details = envelope.Error.Details
+		if envelope.Error.RequestID != "" {
+			apiErr.RequestID = envelope.Error.RequestID
+		}
+	}
+	return apiErr
+}
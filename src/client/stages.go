@@ -0,0 +1,66 @@
+// src/client/stages.go
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"builder.ai/src/models"
+)
+
+type stageResponse struct {
+	Stage models.Stage `json:"stage"`
+}
+
+// ListStages returns the stages in order
+func (c *Client) ListStages(ctx context.Context) ([]models.Stage, error) {
+	var out struct {
+		Stages []models.Stage `json:"stages"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/stages", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Stages, nil
+}
+
+// GetStage returns the stage with the given name
+func (c *Client) GetStage(ctx context.Context, name string) (*models.Stage, error) {
+	var out models.Stage
+	if err := c.do(ctx, http.MethodGet, "/stages/"+url.PathEscape(name), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateStage adds a stage and returns it with its ID
+func (c *Client) CreateStage(ctx context.Context, stage models.Stage) (*models.Stage, error) {
+	var out stageResponse
+	if err := c.do(ctx, http.MethodPost, "/stages", nil, stage, &out); err != nil {
+		return nil, err
+	}
+	return &out.Stage, nil
+}
+
+// UpdateStage replaces the description, roles and codegen of a stage
+func (c *Client) UpdateStage(ctx context.Context, name string, update models.StageUpdate) (*models.Stage, error) {
+	var out stageResponse
+	if err := c.do(ctx, http.MethodPut, "/stages/"+url.PathEscape(name), nil, update, &out); err != nil {
+		return nil, err
+	}
+	return &out.Stage, nil
+}
+
+// DeleteStage deletes a stage no component belongs to
+func (c *Client) DeleteStage(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/stages/"+url.PathEscape(name), nil, nil, &MessageResponse{})
+}
+
+// ListStageComponents returns the components of a stage such as "stage1"
+func (c *Client) ListStageComponents(ctx context.Context, stage string) ([]models.Component, error) {
+	var out componentList
+	if err := c.do(ctx, http.MethodGet, "/stages/"+url.PathEscape(stage)+"/components", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Components, nil
+}
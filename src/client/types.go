@@ -0,0 +1,58 @@
+// src/client/types.go
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+type typeResponse struct {
+	Type models.DataType `json:"type"`
+}
+
+// ListTypes returns the built-in types followed by the custom types
+func (c *Client) ListTypes(ctx context.Context) ([]utils.DataType, error) {
+	var out struct {
+		Types []utils.DataType `json:"types"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/types", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Types, nil
+}
+
+// GetType returns the type with the given name or alias
+func (c *Client) GetType(ctx context.Context, name string) (*utils.DataType, error) {
+	var out utils.DataType
+	if err := c.do(ctx, http.MethodGet, "/types/"+url.PathEscape(name), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateType adds a custom type and returns it with its ID
+func (c *Client) CreateType(ctx context.Context, t models.DataType) (*models.DataType, error) {
+	var out typeResponse
+	if err := c.do(ctx, http.MethodPost, "/types", nil, t, &out); err != nil {
+		return nil, err
+	}
+	return &out.Type, nil
+}
+
+// UpdateType replaces the definition of a custom type
+func (c *Client) UpdateType(ctx context.Context, name string, update models.DataTypeUpdate) (*models.DataType, error) {
+	var out typeResponse
+	if err := c.do(ctx, http.MethodPut, "/types/"+url.PathEscape(name), nil, update, &out); err != nil {
+		return nil, err
+	}
+	return &out.Type, nil
+}
+
+// DeleteType deletes a custom type no component or other type uses
+func (c *Client) DeleteType(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/types/"+url.PathEscape(name), nil, nil, &MessageResponse{})
+}
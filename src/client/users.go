@@ -0,0 +1,63 @@
+// src/client/users.go
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"builder.ai/src/models"
+)
+
+type userList struct {
+	Count int           `json:"count"`
+	Users []models.User `json:"users"`
+}
+
+// ListUsers returns all users
+func (c *Client) ListUsers(ctx context.Context) ([]models.User, error) {
+	var out userList
+	if err := c.do(ctx, http.MethodGet, "/users", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Users, nil
+}
+
+// GetUser returns the user with the given ID
+func (c *Client) GetUser(ctx context.Context, id string) (*models.User, error) {
+	var out models.User
+	if err := c.do(ctx, http.MethodGet, "/users/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// CreateUser creates a user and returns it with its ID
+func (c *Client) CreateUser(ctx context.Context, user models.User) (*models.User, error) {
+	var out struct {
+		User models.User `json:"user"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/users", nil, user, &out); err != nil {
+		return nil, err
+	}
+	return &out.User, nil
+}
+
+// UpdateUser replaces the fields of the user with the given ID
+func (c *Client) UpdateUser(ctx context.Context, id string, user models.User) error {
+	return c.do(ctx, http.MethodPut, "/users/"+url.PathEscape(id), nil, user, &MessageResponse{})
+}
+
+// DeleteUser deletes the user with the given ID
+func (c *Client) DeleteUser(ctx context.Context, id string) error {
+	return c.do(ctx, http.MethodDelete, "/users/"+url.PathEscape(id), nil, nil, &MessageResponse{})
+}
+
+// SearchUsers returns users whose name contains name, case-insensitively
+func (c *Client) SearchUsers(ctx context.Context, name string) ([]models.User, error) {
+	var out userList
+	if err := c.do(ctx, http.MethodGet, "/users/search", url.Values{"name": {name}}, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Users, nil
+}
@@ -0,0 +1,52 @@
+// src/client/workflow.go
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// GenerateScript generates an executable Python script for the workflow
+// using componentCode as the component function definitions
+func (c *Client) GenerateScript(ctx context.Context, workflow utils.WorkflowConfig, componentCode string) (string, error) {
+	config, err := json.Marshal(workflow)
+	if err != nil {
+		return "", fmt.Errorf("encode workflow: %w", err)
+	}
+
+	request := models.GenerateScriptRequest{
+		WorkflowConfig: string(config),
+		ComponentCode:  componentCode,
+	}
+
+	var out struct {
+		Script string `json:"script"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/workflow/generate-script", nil, request, &out); err != nil {
+		return "", err
+	}
+	return out.Script, nil
+}
+
+// RunCode substitutes variables into each item's code and concatenates them
+func (c *Client) RunCode(ctx context.Context, request models.RunCodeRequest) (*models.RunCodeResult, error) {
+	var out models.RunCodeResult
+	if err := c.do(ctx, http.MethodPost, "/workflow/run", nil, request, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Export builds a workflow from code items and generates its script
+func (c *Client) Export(ctx context.Context, request models.RunCodeRequest) (*models.ExportResult, error) {
+	var out models.ExportResult
+	if err := c.do(ctx, http.MethodPost, "/workflow/export", nil, request, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
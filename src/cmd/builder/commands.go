@@ -0,0 +1,507 @@
+// src/cmd/builder/commands.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"builder.ai/src/client"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// stringList is a repeatable string flag
+type stringList []string
+
+func (s *stringList) String() string     { return strings.Join(*s, ",") }
+func (s *stringList) Set(v string) error { *s = append(*s, v); return nil }
+
+func runList(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("list", flag.ContinueOnError)
+	stage := fset.String("stage", "", "only components of this stage")
+	role := fset.String("role", "", "only components with this role")
+	language := fset.String("language", "", "only components in this language")
+	asJSON := fset.Bool("json", false, "print JSON instead of a table")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	components, err := a.client.ListComponents(ctx, client.ListComponentsOptions{Stage: *stage, Role: *role, Language: *language})
+	if err != nil {
+		return err
+	}
+	return printComponents(components, *asJSON)
+}
+
+func runSearch(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("search", flag.ContinueOnError)
+	stage := fset.String("stage", "", "only components of this stage")
+	asJSON := fset.Bool("json", false, "print JSON instead of a table")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() != 1 {
+		return errors.New("usage: builder search [flags] NAME")
+	}
+
+	var components []models.Component
+	for component, err := range a.client.SearchAllComponents(ctx, client.SearchComponentsOptions{Name: fset.Arg(0), Stage: *stage}) {
+		if err != nil {
+			return err
+		}
+		components = append(components, component)
+	}
+	return printComponents(components, *asJSON)
+}
+
+func runCreate(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("create", flag.ContinueOnError)
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		return errors.New("usage: builder create FILE...")
+	}
+
+	components, _, err := loadComponents(fset.Args())
+	if err != nil {
+		return err
+	}
+
+	created, err := a.client.CreateComponents(ctx, components)
+	if err != nil {
+		return err
+	}
+	for _, component := range created {
+		fmt.Printf("created %s %s\n", component.ID.Hex(), component.Name)
+	}
+	return nil
+}
+
+func runPush(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("push", flag.ContinueOnError)
+	dryRun := fset.Bool("dry-run", false, "only print what would change")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if fset.NArg() == 0 {
+		return errors.New("usage: builder push [flags] FILE_OR_DIR...")
+	}
+
+	components, files, err := loadComponents(fset.Args())
+	if err != nil {
+		return err
+	}
+
+	for i, component := range components {
+		existing, err := findByName(ctx, a.client, component.Name)
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case existing == nil && *dryRun:
+			fmt.Printf("would create %s (%s)\n", component.Name, files[i])
+		case existing == nil:
+			created, err := a.client.CreateComponent(ctx, component)
+			if err != nil {
+				return fmt.Errorf("%s: %w", files[i], err)
+			}
+			fmt.Printf("created %s %s\n", created.ID.Hex(), created.Name)
+		case *dryRun:
+			fmt.Printf("would update %s %s (%s)\n", existing.ID.Hex(), component.Name, files[i])
+		default:
+			if err := a.client.UpdateComponent(ctx, existing.ID.Hex(), component); err != nil {
+				return fmt.Errorf("%s: %w", files[i], err)
+			}
+			fmt.Printf("updated %s %s\n", existing.ID.Hex(), component.Name)
+		}
+	}
+	return nil
+}
+
+func runPull(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("pull", flag.ContinueOnError)
+	dir := fset.String("dir", "components", "directory to write component files into")
+	stage := fset.String("stage", "", "only components of this stage")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+
+	components, err := a.client.ListComponents(ctx, client.ListComponentsOptions{Stage: *stage})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(*dir, 0755); err != nil {
+		return err
+	}
+
+	names := fileNames(components)
+	for i, component := range components {
+		path := filepath.Join(*dir, names[i])
+		if err := os.WriteFile(path, []byte(formatComponentFile(component)), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("wrote %s\n", path)
+	}
+	return nil
+}
+
+func runGenerate(ctx context.Context, a *app, args []string) error {
+	fset := flag.NewFlagSet("generate", flag.ContinueOnError)
+	workflowPath := fset.String("workflow", "", "workflow JSON file exported from the builder (required)")
+	out := fset.String("out", "", "write the script to this file instead of stdout")
+	format := fset.String("format", utils.DefaultExportFormat, "export format: "+strings.Join(utils.ExportFormats(), ", "))
+	bundle := fset.Bool("bundle", false, "write a zip project bundle (artifact, dependency manifest, Dockerfile, README, workflow JSON); required for workflows mixing languages over a bridge")
+	dataPath := fset.String("data", "", "data file the workflow runs on (CSV, TSV, JSON Lines, Parquet or XLSX): its columns are checked before generating, and a bundle includes it, e.g. as data.csv")
+	seed := fset.Int64("seed", -1, "seed random, numpy and every component's random_state (default: unseeded)")
+	timestamp := fset.String("timestamp", "", "RFC 3339 timestamp written to the header instead of the current time")
+	templatePath := fset.String("template", "", "custom script template that redefines blocks of the built-in one")
+	taskType := fset.String("task", "", "task type overriding the workflow's: "+strings.Join(utils.TaskTypes, ", "))
+	target := fset.String("target", "", "target column overriding the workflow's, comma-separated for multi-output")
+	var codePaths stringList
+	fset.Var(&codePaths, "code", "component source file or directory (.py, .js or .go); repeatable")
+	if err := fset.Parse(args); err != nil {
+		return err
+	}
+	if *workflowPath == "" || len(codePaths) == 0 {
+		return errors.New("usage: builder generate -workflow FILE -code FILE_OR_DIR [-code ...] [-format FORMAT] [-bundle] [-data FILE] [-seed N] [-timestamp RFC3339] [-template FILE] [-task TYPE] [-target COLUMN] [-out FILE]")
+	}
+
+	var opts utils.GenerateOptions
+	if *seed >= 0 {
+		opts.Seed = seed
+	}
+	if *timestamp != "" {
+		t, err := time.Parse(time.RFC3339, *timestamp)
+		if err != nil {
+			return fmt.Errorf("invalid -timestamp: %w", err)
+		}
+		opts.Timestamp = t
+	}
+	if *templatePath != "" {
+		source, err := os.ReadFile(*templatePath)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(*templatePath), filepath.Ext(*templatePath))
+		if opts.Template, err = utils.ParseScriptTemplate(name, string(source)); err != nil {
+			return fmt.Errorf("%s: %w", *templatePath, err)
+		}
+	}
+
+	data, err := os.ReadFile(*workflowPath)
+	if err != nil {
+		return err
+	}
+	var workflow utils.WorkflowConfig
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		return fmt.Errorf("%s: invalid workflow JSON: %w", *workflowPath, err)
+	}
+	if *taskType != "" {
+		workflow.TaskType = *taskType
+	}
+	if !utils.IsValidTaskType(workflow.TaskType) {
+		return fmt.Errorf("unknown task type %q, must be one of: %s", workflow.TaskType, strings.Join(utils.TaskTypes, ", "))
+	}
+	if *target != "" {
+		workflow.TargetColumn = *target
+	}
+	if *dataPath != "" {
+		dataFormat, err := checkColumns(workflow, *dataPath)
+		if err != nil {
+			return err
+		}
+		if workflow.DataFormat == "" {
+			workflow.DataFormat = dataFormat
+		}
+	}
+
+	code, err := loadCode(codePaths)
+	if err != nil {
+		return err
+	}
+
+	// Workflows mixing languages export as a bundle with a program per
+	// language
+	language, err := utils.WorkflowLanguage(workflow)
+	if err != nil {
+		return err
+	}
+	for _, name := range utils.WorkflowLanguages(workflow) {
+		if !utils.SupportsDataFormat(name, workflow.DataFormat) {
+			return fmt.Errorf("%s components cannot read %s data, use csv, tsv or jsonl", name, workflow.DataFormat)
+		}
+	}
+	if language == "" {
+		if !*bundle || *format != utils.DefaultExportFormat {
+			return fmt.Errorf("the workflow mixes languages over its %s bridge; export it with -bundle in the %s format", workflow.Bridge, utils.DefaultExportFormat)
+		}
+		return writeBundle(*dataPath, *out, func(sample string) (*utils.Bundle, error) {
+			return utils.NewBridgeBundle(workflow, code, sample, opts)
+		})
+	}
+
+	exporter, ok := utils.GetExporterFor(*format, language)
+	if !ok {
+		if language != utils.LanguagePython {
+			return fmt.Errorf("format %q is not available for %s components, use %s", *format, language, utils.DefaultExportFormat)
+		}
+		return fmt.Errorf("unknown format %q, must be one of: %s", *format, strings.Join(utils.ExportFormats(), ", "))
+	}
+
+	if *bundle {
+		return writeBundle(*dataPath, *out, func(sample string) (*utils.Bundle, error) {
+			return utils.NewBundle(exporter, workflow, code[language], sample, opts)
+		})
+	}
+
+	artifact, err := exporter.Generate(workflow, code[language], opts)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		_, err = os.Stdout.Write(artifact)
+		return err
+	}
+	mode := os.FileMode(0644)
+	if exporter.Format == utils.DefaultExportFormat && language != utils.LanguageGo {
+		mode = 0755
+	}
+	if err := os.WriteFile(*out, artifact, mode); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s (%d components)\n", *out, len(workflow.Nodes))
+	return nil
+}
+
+// checkColumns checks the workflow's column variables and target column
+// against the columns and types of a data file, and returns the file's
+// format as detected from its name and first bytes
+func checkColumns(workflow utils.WorkflowConfig, dataPath string) (string, error) {
+	f, err := os.Open(dataPath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	format, err := utils.SniffDatasetFormat(dataPath, f)
+	if err != nil {
+		return "", err
+	}
+
+	profile, err := utils.ProfileDataset(f, format)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", dataPath, err)
+	}
+	issues := utils.CheckColumns(workflow, profile)
+	if len(issues) == 0 {
+		return format, nil
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "workflow does not match %s:", dataPath)
+	for _, issue := range issues {
+		field := issue.Field
+		if issue.NodeID != "" {
+			field = fmt.Sprintf("node %s %s", issue.NodeID, issue.Field)
+		}
+		fmt.Fprintf(&sb, "\n  %s: %s", field, issue.Message)
+	}
+	return "", errors.New(sb.String())
+}
+
+// writeBundle writes the zip project bundle built from the sample data to
+// out, or stdout when empty
+func writeBundle(dataPath, out string, build func(sample string) (*utils.Bundle, error)) error {
+	var sample []byte
+	if dataPath != "" {
+		var err error
+		if sample, err = os.ReadFile(dataPath); err != nil {
+			return err
+		}
+	}
+
+	project, err := build(string(sample))
+	if err != nil {
+		return err
+	}
+
+	if out == "" {
+		return project.WriteZip(os.Stdout)
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	if err := project.WriteZip(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "wrote %s (%d files)\n", out, len(project.Files))
+	return nil
+}
+
+func printComponents(components []models.Component, asJSON bool) error {
+	if asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(components)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tSTAGE\tLANGUAGE\tNAME")
+	for _, component := range components {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", component.ID.Hex(), component.Stage, component.Language, component.Name)
+	}
+	return w.Flush()
+}
+
+// loadComponents parses every component file found in paths, descending
+// into directories
+func loadComponents(paths []string) ([]models.Component, []string, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	components := make([]models.Component, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		component, err := parseComponentFile(string(data))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", file, err)
+		}
+		components = append(components, component)
+	}
+	return components, files, nil
+}
+
+// loadCode concatenates component sources by language, dropping
+// front-matter blocks. The language is the front matter's, or that of the
+// file extension for files without one.
+func loadCode(paths []string) (map[string]string, error) {
+	files, err := expandPaths(paths)
+	if err != nil {
+		return nil, err
+	}
+
+	blocks := map[string][]string{}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		code := string(data)
+		language := sourceLanguages[filepath.Ext(file)]
+		if component, err := parseComponentFile(code); err == nil {
+			code, language = component.Code, component.Language
+		}
+		if language == "" {
+			language = utils.DefaultLanguage
+		}
+		blocks[language] = append(blocks[language], strings.TrimSpace(code))
+	}
+
+	code := make(map[string]string, len(blocks))
+	for language, sources := range blocks {
+		code[language] = strings.Join(sources, "\n\n")
+	}
+	return code, nil
+}
+
+// sourceLanguages are the languages of component files by extension
+var sourceLanguages = map[string]string{".py": utils.LanguagePython, ".js": utils.LanguageJavaScript, ".go": utils.LanguageGo}
+
+var sourceExtensions = map[string]bool{".py": true, ".js": true, ".go": true}
+
+func expandPaths(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && sourceExtensions[filepath.Ext(p)] {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
+
+// findByName returns the component whose name matches exactly, if any
+func findByName(ctx context.Context, c *client.Client, name string) (*models.Component, error) {
+	for component, err := range c.SearchAllComponents(ctx, client.SearchComponentsOptions{Name: regexp.QuoteMeta(name)}) {
+		if err != nil {
+			return nil, err
+		}
+		if component.Name == name {
+			return &component, nil
+		}
+	}
+	return nil, nil
+}
+
+var nonWord = regexp.MustCompile(`[^a-z0-9]+`)
+
+// fileNames returns a file name per component. Names are slugified, so
+// components whose names slugify the same way get their ID appended to
+// keep one from overwriting the other whatever order they are listed in.
+func fileNames(components []models.Component) []string {
+	count := make(map[string]int)
+	for _, component := range components {
+		count[fileName(component, false)]++
+	}
+	names := make([]string, len(components))
+	for i, component := range components {
+		names[i] = fileName(component, count[fileName(component, false)] > 1)
+	}
+	return names
+}
+
+func fileName(component models.Component, withID bool) string {
+	base := strings.Trim(nonWord.ReplaceAllString(strings.ToLower(component.Name), "_"), "_")
+	if base == "" {
+		base = component.ID.Hex()
+	} else if withID {
+		base += "_" + component.ID.Hex()
+	}
+	switch component.Language {
+	case "javascript":
+		return base + ".js"
+	case "go":
+		return base + ".go"
+	default:
+		return base + ".py"
+	}
+}
@@ -0,0 +1,206 @@
+// src/cmd/builder/frontmatter.go
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"builder.ai/src/models"
+)
+
+// Component files are plain source files that start with a metadata block
+// written as comments, for example:
+//
+//	# ---
+//	# name: Remove Duplicates
+//	# description: Removes duplicate rows from dataset
+//	# stage: stage1
+//	# role: row_filter
+//	# language: python
+//	# tags: cleaning, duplicates
+//	# input: data DataFrame required "Input DataFrame"
+//	# output: DataFrame "DataFrame without duplicate rows"
+//	# ---
+//	def remove_duplicates(data):
+//	    return data.drop_duplicates()
+//
+// "role" is optional and inferred by the server when missing. "input" may
+// be repeated; each takes a name, a type, optionally "required" or
+// "optional" (default required) and an optional quoted description.
+const frontMatterDelimiter = "---"
+
+// parseComponentFile reads the metadata block and returns the component
+// with Code set to everything after the block
+func parseComponentFile(source string) (models.Component, error) {
+	component := models.Component{Language: "python"}
+
+	inBlock := false
+	offset := 0
+	for i, raw := range strings.SplitAfter(source, "\n") {
+		lineNo := i + 1
+		offset += len(raw)
+
+		trimmed := strings.TrimSpace(raw)
+		if !inBlock {
+			if trimmed == "" {
+				continue
+			}
+			if commentBody(trimmed) != frontMatterDelimiter {
+				return component, fmt.Errorf("line %d: file must start with a %q front-matter block", lineNo, "# ---")
+			}
+			inBlock = true
+			continue
+		}
+
+		body := commentBody(trimmed)
+		if body == frontMatterDelimiter {
+			component.Code = strings.TrimLeft(source[offset:], "\r\n")
+			return component, nil
+		}
+		if body == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(body, ":")
+		if !ok {
+			return component, fmt.Errorf("line %d: expected \"key: value\", got %q", lineNo, body)
+		}
+		if err := applyField(&component, strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)); err != nil {
+			return component, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+	}
+	return component, fmt.Errorf("front-matter block is not closed with %q", "# ---")
+}
+
+// commentBody strips a leading "#" or "//" comment marker
+func commentBody(line string) string {
+	for _, marker := range []string{"#", "//"} {
+		if strings.HasPrefix(line, marker) {
+			return strings.TrimSpace(strings.TrimPrefix(line, marker))
+		}
+	}
+	return line
+}
+
+func applyField(component *models.Component, key, value string) error {
+	switch key {
+	case "name":
+		component.Name = value
+	case "description":
+		component.Description = value
+	case "stage":
+		component.Stage = value
+	case "role":
+		component.Role = value
+	case "language":
+		component.Language = value
+	case "tags":
+		for _, tag := range strings.Split(value, ",") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				component.Tags = append(component.Tags, tag)
+			}
+		}
+	case "input":
+		fields, err := splitFields(value)
+		if err != nil {
+			return err
+		}
+		if len(fields) < 2 {
+			return fmt.Errorf("input needs at least a name and a type")
+		}
+		input := models.ComponentInput{Name: fields[0], Type: fields[1], Required: true}
+		rest := fields[2:]
+		if len(rest) > 0 && (rest[0] == "required" || rest[0] == "optional") {
+			input.Required = rest[0] == "required"
+			rest = rest[1:]
+		}
+		input.Description = strings.Join(rest, " ")
+		component.Inputs = append(component.Inputs, input)
+	case "output":
+		fields, err := splitFields(value)
+		if err != nil {
+			return err
+		}
+		if len(fields) == 0 {
+			return fmt.Errorf("output needs a type")
+		}
+		component.Output = &models.ComponentOutput{Type: fields[0], Description: strings.Join(fields[1:], " ")}
+	default:
+		return fmt.Errorf("unknown front-matter key %q", key)
+	}
+	return nil
+}
+
+// splitFields splits on whitespace while keeping double-quoted text together
+func splitFields(value string) ([]string, error) {
+	var fields []string
+	for value = strings.TrimSpace(value); value != ""; value = strings.TrimSpace(value) {
+		if value[0] == '"' {
+			quoted, err := strconv.QuotedPrefix(value)
+			if err != nil {
+				return nil, fmt.Errorf("unterminated quote in %q", value)
+			}
+			unquoted, _ := strconv.Unquote(quoted)
+			fields = append(fields, unquoted)
+			value = value[len(quoted):]
+			continue
+		}
+		end := strings.IndexAny(value, " \t")
+		if end < 0 {
+			end = len(value)
+		}
+		fields = append(fields, value[:end])
+		value = value[end:]
+	}
+	return fields, nil
+}
+
+// formatComponentFile is the inverse of parseComponentFile
+func formatComponentFile(component models.Component) string {
+	marker := "#"
+	if component.Language == "go" || component.Language == "javascript" {
+		marker = "//"
+	}
+
+	var sb strings.Builder
+	line := func(format string, args ...interface{}) {
+		sb.WriteString(marker + " " + fmt.Sprintf(format, args...) + "\n")
+	}
+
+	line(frontMatterDelimiter)
+	line("name: %s", component.Name)
+	if component.Description != "" {
+		line("description: %s", component.Description)
+	}
+	line("stage: %s", component.Stage)
+	if component.Role != "" {
+		line("role: %s", component.Role)
+	}
+	line("language: %s", component.Language)
+	if len(component.Tags) > 0 {
+		line("tags: %s", strings.Join(component.Tags, ", "))
+	}
+	for _, input := range component.Inputs {
+		required := "optional"
+		if input.Required {
+			required = "required"
+		}
+		if input.Description != "" {
+			line("input: %s %s %s %s", input.Name, input.Type, required, strconv.Quote(input.Description))
+		} else {
+			line("input: %s %s %s", input.Name, input.Type, required)
+		}
+	}
+	if component.Output != nil {
+		if component.Output.Description != "" {
+			line("output: %s %s", component.Output.Type, strconv.Quote(component.Output.Description))
+		} else {
+			line("output: %s", component.Output.Type)
+		}
+	}
+	line(frontMatterDelimiter)
+
+	sb.WriteString(strings.TrimRight(component.Code, "\n") + "\n")
+	return sb.String()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+
+	"builder.ai/src/models"
+)
+
+func TestComponentFileRoundTrip(t *testing.T) {
+	tests := []models.Component{
+		{
+			Name:        "Remove Duplicates",
+			Description: "Removes duplicate rows from dataset",
+			Stage:       "stage1",
+			Role:        "row_filter",
+			Language:    "python",
+			Tags:        []string{"cleaning", "duplicates"},
+			Inputs: []models.ComponentInput{
+				{Name: "data", Type: "DataFrame", Required: true, Description: "Input \"raw\" DataFrame"},
+				{Name: "subset", Type: "list", Required: false},
+			},
+			Output: &models.ComponentOutput{Type: "DataFrame", Description: "DataFrame without duplicate rows"},
+			Code:   "def remove_duplicates(data, subset=None):\n    return data.drop_duplicates(subset=subset)\n",
+		},
+		{
+			Name:     "Scale",
+			Stage:    "stage2",
+			Language: "go",
+			Inputs:   []models.ComponentInput{{Name: "t", Type: "DataFrame", Required: true}},
+			Output:   &models.ComponentOutput{Type: "DataFrame"},
+			Code:     "func Scale(t Table) (Table, error) {\n\treturn t, nil\n}\n",
+		},
+		{
+			Name:     "Log Rows",
+			Stage:    "stage1",
+			Language: "javascript",
+			Code:     "function logRows(rows) {\n  console.log(rows.length);\n}\n",
+		},
+	}
+
+	for _, want := range tests {
+		t.Run(want.Name, func(t *testing.T) {
+			source := formatComponentFile(want)
+			got, err := parseComponentFile(source)
+			if err != nil {
+				t.Fatalf("parseComponentFile() error = %v\n%s", err, source)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v\n%s", got, want, source)
+			}
+		})
+	}
+}
+
+func TestParseComponentFileErrors(t *testing.T) {
+	tests := []struct {
+		name, source, want string
+	}{
+		{"no block", "def f():\n    pass\n", "front-matter block"},
+		{"not closed", "# ---\n# name: f\n", "not closed"},
+		{"unknown key", "# ---\n# owner: me\n# ---\n", `unknown front-matter key "owner"`},
+		{"no value", "# ---\n# name\n# ---\n", `line 2: expected "key: value"`},
+		{"short input", "# ---\n# input: data\n# ---\n", "line 2: input needs at least a name and a type"},
+		{"open quote", "# ---\n# output: DataFrame \"rows\n# ---\n", "unterminated quote"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseComponentFile(tt.source); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseComponentFile() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileNamesDoNotCollide(t *testing.T) {
+	a, b := primitive.NewObjectID(), primitive.NewObjectID()
+	components := []models.Component{
+		{ID: a, Name: "Remove Duplicates", Language: "python"},
+		{ID: b, Name: "remove-duplicates", Language: "python"},
+		{Name: "Remove Duplicates", Language: "javascript"},
+		{Name: "Scale", Language: "go"},
+	}
+
+	want := []string{
+		"remove_duplicates_" + a.Hex() + ".py",
+		"remove_duplicates_" + b.Hex() + ".py",
+		"remove_duplicates.js",
+		"scale.go",
+	}
+	if got := fileNames(components); !reflect.DeepEqual(got, want) {
+		t.Errorf("fileNames() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+// src/cmd/builder/main.go
+//
+// builder is a command-line tool for managing components and generating
+// pipeline scripts.
+//
+//	builder [global flags] <command> [flags] [args]
+//
+// Commands:
+//
+//	list      List components on the server
+//	search    Search components by name prefix
+//	create    Create components from front-matter source files
+//	push      Create or update components from files or directories, matched by name
+//	pull      Download components into front-matter source files
+//	generate  Generate a pipeline script, notebook or project bundle from a local workflow JSON (offline)
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"builder.ai/src/client"
+	"builder.ai/src/envelope"
+)
+
+type command struct {
+	name    string
+	summary string
+	run     func(ctx context.Context, app *app, args []string) error
+}
+
+var commands = []command{
+	{"list", "List components on the server", runList},
+	{"search", "Search components by name prefix", runSearch},
+	{"create", "Create components from front-matter source files", runCreate},
+	{"push", "Create or update components from files or directories, matched by name", runPush},
+	{"pull", "Download components into front-matter source files", runPull},
+	{"generate", "Generate a pipeline script from a local workflow JSON (offline)", runGenerate},
+}
+
+// app holds the global options shared by every command
+type app struct {
+	server string
+	user   string
+	client *client.Client
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	global := flag.NewFlagSet("builder", flag.ContinueOnError)
+	server := global.String("server", envOr("BUILDER_URL", "http://localhost:8080"), "API base URL (env BUILDER_URL)")
+	user := global.String("user", os.Getenv("BUILDER_USER"), "user ID sent as "+envelope.UserHeader+" (env BUILDER_USER)")
+	timeout := global.Duration("timeout", 60*time.Second, "overall timeout for the command")
+	global.Usage = func() { usage(global) }
+
+	if err := global.Parse(args); err != nil {
+		return 2
+	}
+	if global.NArg() == 0 {
+		usage(global)
+		return 2
+	}
+
+	name := global.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		opts := []client.Option{}
+		if *user != "" {
+			opts = append(opts, client.WithHeader(envelope.UserHeader, *user))
+		}
+		a := &app{server: *server, user: *user, client: client.New(*server, opts...)}
+
+		ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+		defer cancel()
+
+		if err := cmd.run(ctx, a, global.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "builder %s: %v\n", name, err)
+			return 1
+		}
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "builder: unknown command %q\n\n", name)
+	usage(global)
+	return 2
+}
+
+func usage(global *flag.FlagSet) {
+	out := global.Output()
+	fmt.Fprintln(out, "Usage: builder [global flags] <command> [flags] [args]")
+	fmt.Fprintln(out, "\nCommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(out, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(out, "\nGlobal flags:")
+	global.PrintDefaults()
+	fmt.Fprintln(out, "\nRun 'builder <command> -h' for command flags.")
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
@@ -0,0 +1,14 @@
+// Starts Swagger UI on the API's document. The UI is served from the
+// binary; see swagger.go for how it is vendored.
+window.onload = function () {
+  if (typeof SwaggerUIBundle === "undefined") {
+    document.getElementById("swagger-ui").textContent =
+      "Swagger UI is not vendored in this build: run go generate ./src/docs and rebuild.";
+    return;
+  }
+  window.ui = SwaggerUIBundle({
+    url: "openapi.json",
+    dom_id: "#swagger-ui",
+    deepLinking: true,
+  });
+};
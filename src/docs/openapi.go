@@ -0,0 +1,231 @@
+// src/docs/openapi.go
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BasePath is the prefix shared by every documented route
+const BasePath = "/api/v1"
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers"`
+	Tags       []Tag               `json:"tags"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// PathItem maps lower-case HTTP methods to operations
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string             `json:"tags"`
+	Summary     string               `json:"summary"`
+	OperationID string               `json:"operationId"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Response struct {
+	Ref         string               `json:"$ref,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas   map[string]*Schema   `json:"schemas"`
+	Responses map[string]*Response `json:"responses"`
+}
+
+var (
+	specOnce sync.Once
+	spec     *Document
+
+	ginParam = regexp.MustCompile(`[:*]([A-Za-z0-9_]+)`)
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// Spec returns the OpenAPI document for the REST API
+func Spec() *Document {
+	specOnce.Do(func() {
+		spec = build()
+	})
+	return spec
+}
+
+// OpenAPIPath converts a gin route path such as /components/:id into the
+// OpenAPI form /components/{id}
+func OpenAPIPath(ginPath string) string {
+	return ginParam.ReplaceAllString(ginPath, "{$1}")
+}
+
+// HasOperation reports whether the spec documents method on the gin route path
+func (d *Document) HasOperation(method, ginPath string) bool {
+	item, ok := d.Paths[OpenAPIPath(strings.TrimPrefix(ginPath, BasePath))]
+	if !ok {
+		return false
+	}
+	_, ok = item[strings.ToLower(method)]
+	return ok
+}
+
+// ServeSpec responds with the OpenAPI document as JSON
+func ServeSpec(c *gin.Context) {
+	c.JSON(http.StatusOK, Spec())
+}
+
+// ServeUI responds with the interactive documentation page
+func ServeUI(c *gin.Context) {
+	c.Header("Content-Security-Policy", docsPolicy)
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+func build() *Document {
+	reg := newSchemaRegistry()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info: Info{
+			Title:       "Builder API",
+			Description: "Manage pipeline components and users, and generate executable Python pipelines from workflows.",
+			Version:     "1.0.0",
+		},
+		Servers: []Server{{URL: BasePath}},
+		Tags: []Tag{
+			{Name: "components", Description: "Reusable pipeline components"},
+			{Name: "stages", Description: "Pipeline stages and the components of each"},
+			{Name: "types", Description: "Types of component inputs and outputs, built-in and custom"},
+			{Name: "users", Description: "User accounts"},
+			{Name: "workflow", Description: "Script generation from workflows"},
+			{Name: "templates", Description: "Script templates, built-in and per workspace"},
+			{Name: "datasets", Description: "Uploaded datasets workflows run on"},
+			{Name: "docs", Description: "API documentation"},
+		},
+		Paths: map[string]PathItem{},
+	}
+
+	for _, e := range endpoints(reg) {
+		path := OpenAPIPath(e.Path)
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = PathItem{}
+		}
+		doc.Paths[path][strings.ToLower(e.Method)] = e.operation()
+	}
+
+	doc.Components = Components{
+		Schemas:   reg.schemas,
+		Responses: errorResponses(reg),
+	}
+	return doc
+}
+
+// endpoint describes one route in a compact form
+type endpoint struct {
+	Method      string
+	Path        string // gin-style, relative to BasePath
+	Tag         string
+	ID          string
+	Summary     string
+	Query       []Parameter
+	Body        *Schema
+	BodyType    string // content type of Body, defaults to application/json
+	Status      int
+	Response    *Schema
+	ContentType string   // defaults to application/json
+	Downloads   []string // alternative content types returned as file downloads
+	Errors      []string
+}
+
+func (e endpoint) operation() *Operation {
+	op := &Operation{
+		Tags:        []string{e.Tag},
+		Summary:     e.Summary,
+		OperationID: e.ID,
+		Responses:   map[string]*Response{},
+	}
+
+	for _, m := range ginParam.FindAllStringSubmatch(e.Path, -1) {
+		op.Parameters = append(op.Parameters, Parameter{
+			Name: m[1], In: "path", Required: true, Schema: &Schema{Type: "string"},
+		})
+	}
+	op.Parameters = append(op.Parameters, e.Query...)
+
+	if e.Body != nil {
+		bodyType := e.BodyType
+		if bodyType == "" {
+			bodyType = "application/json"
+		}
+		op.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{bodyType: {Schema: e.Body}},
+		}
+	}
+
+	status := e.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	contentType := e.ContentType
+	if contentType == "" {
+		contentType = "application/json"
+	}
+	op.Responses[strconv.Itoa(status)] = &Response{
+		Description: http.StatusText(status),
+		Content:     map[string]MediaType{contentType: {Schema: e.Response}},
+	}
+	for _, download := range e.Downloads {
+		op.Responses[strconv.Itoa(status)].Content[download] = MediaType{Schema: &Schema{Type: "string", Format: "binary"}}
+	}
+
+	for _, name := range e.Errors {
+		op.Responses[errorStatus[name]] = &Response{Ref: "#/components/responses/" + name}
+	}
+	op.Responses[errorStatus[errInternal]] = &Response{Ref: "#/components/responses/" + errInternal}
+
+	return op
+}
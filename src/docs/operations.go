@@ -0,0 +1,406 @@
+// src/docs/operations.go
+package docs
+
+import (
+	"net/http"
+
+	"builder.ai/src/middleware"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+// Names of the shared error responses under components/responses
+const (
+	errBadRequest = "BadRequest"
+	errNotFound   = "NotFound"
+	errConflict   = "Conflict"
+	errValidation = "ValidationError"
+	errInternal   = "InternalError"
+)
+
+var errorStatus = map[string]string{
+	errBadRequest: "400",
+	errNotFound:   "404",
+	errConflict:   "409",
+	errValidation: "422",
+	errInternal:   "500",
+}
+
+func errorResponses(reg *schemaRegistry) map[string]*Response {
+	envelope := reg.ref(middleware.ErrorResponse{})
+	describe := map[string]string{
+		errBadRequest: "Malformed request, e.g. invalid JSON or an invalid ID",
+		errNotFound:   "The resource does not exist",
+		errConflict:   "The write conflicts with an existing resource",
+		errValidation: "One or more fields failed validation; see error.details",
+		errInternal:   "Unexpected server error",
+	}
+
+	responses := map[string]*Response{}
+	for name, description := range describe {
+		responses[name] = &Response{
+			Description: description,
+			Content:     map[string]MediaType{"application/json": {Schema: envelope}},
+		}
+	}
+	return responses
+}
+
+func str() *Schema              { return &Schema{Type: "string"} }
+func integer() *Schema          { return &Schema{Type: "integer"} }
+func boolean() *Schema          { return &Schema{Type: "boolean"} }
+func arrayOf(s *Schema) *Schema { return &Schema{Type: "array", Items: s} }
+
+func object(props map[string]*Schema) *Schema {
+	return &Schema{Type: "object", Properties: props}
+}
+
+func enum(values ...string) *Schema {
+	return &Schema{Type: "string", Enum: values}
+}
+
+func query(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+func header(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: schema}
+}
+
+func requiredQuery(name, description string, schema *Schema) Parameter {
+	p := query(name, description, schema)
+	p.Required = true
+	return p
+}
+
+func message() *Schema {
+	return object(map[string]*Schema{"message": str(), "id": str()})
+}
+
+// workspaceHeader selects the workspace of stored templates and datasets
+func workspaceHeader() Parameter {
+	return header(middleware.WorkspaceHeader, "Workspace of the templates and datasets (default "+middleware.DefaultWorkspace+")", str())
+}
+
+// generationQuery lists the parameters that control generated code
+func generationQuery() []Parameter {
+	return append([]Parameter{
+		query("template", "Script template of the workspace to render with (default "+utils.DefaultTemplateName+")", str()),
+		workspaceHeader(),
+	}, reproducibilityQuery()...)
+}
+
+// reproducibilityQuery lists the query parameters that pin generated code
+func reproducibilityQuery() []Parameter {
+	return []Parameter{
+		query("seed", "Seed random, numpy and the random_state of every component that accepts it (0 to 4294967295)", integer()),
+		query("timestamp", "RFC 3339 timestamp written to the header instead of the current time", &Schema{Type: "string", Format: "date-time"}),
+	}
+}
+
+// exportContentTypes lists the content types of the downloadable formats
+func exportContentTypes() []string {
+	var types []string
+	for _, format := range utils.ExportFormats() {
+		if exporter, _ := utils.GetExporter(format); format != utils.DefaultExportFormat {
+			types = append(types, exporter.ContentType)
+		}
+	}
+	return types
+}
+
+func endpoints(reg *schemaRegistry) []endpoint {
+	component := reg.ref(models.Component{})
+	user := reg.ref(models.User{})
+	scriptTemplate := reg.ref(models.ScriptTemplate{})
+	dataset := reg.ref(models.Dataset{})
+	datasetProfile := reg.ref(utils.DatasetProfile{})
+	codeItemsRequest := reg.ref(models.RunCodeRequest{})
+	testRun := reg.ref(utils.ComponentTestRun{})
+	stage := reg.ref(models.Stage{})
+	dataType := reg.ref(models.DataType{})
+
+	// Workflow configs travel as JSON strings but are documented as models
+	reg.ref(utils.WorkflowConfig{})
+
+	componentList := object(map[string]*Schema{"count": integer(), "components": arrayOf(component)})
+	componentRefs := object(map[string]*Schema{
+		"id": str(), "function": str(), "count": integer(), "components": arrayOf(component),
+	})
+	componentChange := object(map[string]*Schema{"message": str(), "id": str(), "warnings": arrayOf(str())})
+	force := query("force", "Proceed even though components calling this one's function would break; they are listed in warnings", enum("true"))
+	userList := object(map[string]*Schema{"count": integer(), "users": arrayOf(user)})
+	stageParam := str()
+	stageParam.Description = "Stage name, one of listStages"
+	datasetFormat := enum(utils.DatasetFormats()...)
+	datasetFormat.Description = "Dataset format (default detected from the file name and content)"
+
+	return []endpoint{
+		// Users
+		{Method: http.MethodGet, Path: "/users", Tag: "users", ID: "listUsers",
+			Summary: "List all users", Response: userList},
+		{Method: http.MethodGet, Path: "/users/:id", Tag: "users", ID: "getUser",
+			Summary: "Get a user by ID", Response: user, Errors: []string{errBadRequest, errNotFound}},
+		{Method: http.MethodPost, Path: "/users", Tag: "users", ID: "createUser",
+			Summary: "Create a user", Body: user, Status: http.StatusCreated,
+			Response: object(map[string]*Schema{"message": str(), "user": user}),
+			Errors:   []string{errBadRequest, errValidation, errConflict}},
+		{Method: http.MethodPut, Path: "/users/:id", Tag: "users", ID: "updateUser",
+			Summary: "Replace a user's fields", Body: user, Response: message(),
+			Errors: []string{errBadRequest, errValidation, errNotFound, errConflict}},
+		{Method: http.MethodDelete, Path: "/users/:id", Tag: "users", ID: "deleteUser",
+			Summary: "Delete a user", Response: message(), Errors: []string{errBadRequest, errNotFound}},
+		{Method: http.MethodGet, Path: "/users/search", Tag: "users", ID: "searchUsers",
+			Summary:  "Case-insensitive search of users by name",
+			Query:    []Parameter{requiredQuery("name", "Substring to match against the name", str())},
+			Response: userList, Errors: []string{errValidation}},
+
+		// Components
+		{Method: http.MethodGet, Path: "/components", Tag: "components", ID: "listComponents",
+			Summary: "List components, newest first, with optional filters",
+			Query: []Parameter{
+				query("stage", "Only components of this stage", stageParam),
+				query("language", "Only components in this language", str()),
+				query("role", "Only components with this role", enum(utils.Roles...)),
+				query("output_type", "Only components with this output type", str()),
+				query("has_output", "Only components with (true) or without (false) an output", enum("true", "false")),
+			},
+			Response: componentList},
+		{Method: http.MethodGet, Path: "/components/:id", Tag: "components", ID: "getComponent",
+			Summary: "Get a component by ID", Response: component, Errors: []string{errBadRequest, errNotFound}},
+		{Method: http.MethodPost, Path: "/components", Tag: "components", ID: "createComponents",
+			Summary:  "Create one component or an array of at most 100; code with syntax errors is rejected with their line and column",
+			Body:     &Schema{OneOf: []*Schema{component, arrayOf(component)}},
+			Status:   http.StatusCreated,
+			Response: object(map[string]*Schema{"message": str(), "components": arrayOf(component)}),
+			Errors:   []string{errBadRequest, errValidation, errConflict}},
+		{Method: http.MethodPut, Path: "/components/:id", Tag: "components", ID: "updateComponent",
+			Summary: "Replace a component's fields; code with syntax errors is rejected with their line and column, and renaming the function other components call is a conflict",
+			Query:   []Parameter{force},
+			Body:    component, Response: componentChange,
+			Errors: []string{errBadRequest, errValidation, errNotFound, errConflict}},
+		{Method: http.MethodDelete, Path: "/components/:id", Tag: "components", ID: "deleteComponent",
+			Summary: "Delete a component; deleting one whose function other components call is a conflict",
+			Query:   []Parameter{force}, Response: componentChange,
+			Errors: []string{errBadRequest, errNotFound, errConflict}},
+		{Method: http.MethodGet, Path: "/components/:id/usages", Tag: "components", ID: "listComponentUsages",
+			Summary:  "List the components whose code calls the component's function; workflows are not stored, so none are listed",
+			Response: componentRefs, Errors: []string{errBadRequest, errNotFound}},
+		{Method: http.MethodGet, Path: "/components/:id/dependencies", Tag: "components", ID: "listComponentDependencies",
+			Summary:  "List the components defining functions the component's code calls",
+			Response: componentRefs, Errors: []string{errBadRequest, errNotFound}},
+		{Method: http.MethodPost, Path: "/components/:id/test", Tag: "components", ID: "runComponentTests",
+			Summary:  "Run a component's tests in the sandboxed executor and record the run; the component is verified while its latest run passes",
+			Response: object(map[string]*Schema{"message": str(), "id": str(), "verified": boolean(), "run": testRun}),
+			Errors:   []string{errBadRequest, errValidation, errNotFound, errConflict, errInternal}},
+		{Method: http.MethodGet, Path: "/components/search", Tag: "components", ID: "searchComponents",
+			Summary: "Paginated prefix search of components by name",
+			Query: []Parameter{
+				requiredQuery("name", "Case-insensitive name prefix", str()),
+				query("stage", "Only components of this stage", stageParam),
+				query("page", "Page number, starting at 1", integer()),
+				query("limit", "Page size between 1 and 100 (default 50)", integer()),
+				query("sort", "Field to sort by (default name)", str()),
+				query("order", "Sort order", enum("asc", "desc")),
+				query("verified", "Only components whose latest test run passed", enum("true")),
+			},
+			Response: object(map[string]*Schema{
+				"data": arrayOf(component),
+				"pagination": object(map[string]*Schema{
+					"page": integer(), "limit": integer(), "total": integer(),
+					"totalPages": integer(), "hasNext": boolean(), "hasPrev": boolean(),
+				}),
+			}),
+			Errors: []string{errValidation}},
+		{Method: http.MethodGet, Path: "/components/stats", Tag: "components", ID: "getStageStats",
+			Summary: "Count components per stage",
+			Response: object(map[string]*Schema{
+				"stats": arrayOf(object(map[string]*Schema{"_id": str(), "count": integer()})),
+			})},
+		{Method: http.MethodGet, Path: "/components/languages", Tag: "components", ID: "listLanguages",
+			Summary: "List the languages components can be written in and the checker their syntax goes through",
+			Response: object(map[string]*Schema{
+				"count": integer(),
+				"languages": arrayOf(object(map[string]*Schema{
+					"name": enum(utils.Languages...), "extension": str(), "image": str(),
+					"checker": str(),
+				})),
+			})},
+
+		// Stages
+		{Method: http.MethodGet, Path: "/stages", Tag: "stages", ID: "listStages",
+			Summary: "List the stages in order, with the codegen behaviours a stage can have",
+			Response: object(map[string]*Schema{
+				"count": integer(), "codegens": arrayOf(enum(utils.StageCodegens...)), "stages": arrayOf(stage),
+			})},
+		{Method: http.MethodPost, Path: "/stages", Tag: "stages", ID: "createStage",
+			Summary: "Add a stage; names and orders are unique and workflow nodes refer to a stage by its order",
+			Body:    stage, Status: http.StatusCreated,
+			Response: object(map[string]*Schema{"message": str(), "stage": stage}),
+			Errors:   []string{errBadRequest, errValidation, errConflict}},
+		{Method: http.MethodGet, Path: "/stages/:stage", Tag: "stages", ID: "getStage",
+			Summary: "Get a stage by name", Response: stage, Errors: []string{errNotFound}},
+		{Method: http.MethodPut, Path: "/stages/:stage", Tag: "stages", ID: "updateStage",
+			Summary:  "Change the description, allowed roles or codegen of a stage; its order cannot change, and its roles must allow those of its components",
+			Body:     reg.ref(models.StageUpdate{}),
+			Response: object(map[string]*Schema{"message": str(), "stage": stage}),
+			Errors:   []string{errBadRequest, errValidation, errNotFound, errConflict}},
+		{Method: http.MethodDelete, Path: "/stages/:stage", Tag: "stages", ID: "deleteStage",
+			Summary:  "Delete a stage no component belongs to",
+			Response: object(map[string]*Schema{"message": str(), "name": str()}),
+			Errors:   []string{errNotFound, errConflict}},
+		{Method: http.MethodGet, Path: "/stages/:stage/components", Tag: "stages", ID: "listStageComponents",
+			Summary: "List the components of a stage",
+			Response: object(map[string]*Schema{
+				"stage": str(), "count": integer(), "components": arrayOf(component),
+			}),
+			Errors: []string{errValidation}},
+
+		// Types
+		{Method: http.MethodGet, Path: "/types", Tag: "types", ID: "listTypes",
+			Summary: "List the built-in types followed by the custom types, with the literal kinds variables can be written as",
+			Response: object(map[string]*Schema{
+				"count": integer(), "literals": arrayOf(enum(utils.LiteralKinds...)), "types": arrayOf(dataType),
+			})},
+		{Method: http.MethodPost, Path: "/types", Tag: "types", ID: "createType",
+			Summary: "Add a custom type extending a built-in or custom parent; names and aliases are unique across types",
+			Body:    dataType, Status: http.StatusCreated,
+			Response: object(map[string]*Schema{"message": str(), "type": dataType}),
+			Errors:   []string{errBadRequest, errValidation, errConflict}},
+		{Method: http.MethodGet, Path: "/types/:name", Tag: "types", ID: "getType",
+			Summary: "Get a type by name or alias", Response: dataType, Errors: []string{errNotFound}},
+		{Method: http.MethodPut, Path: "/types/:name", Tag: "types", ID: "updateType",
+			Summary:  "Replace the definition of a custom type",
+			Body:     reg.ref(models.DataTypeUpdate{}),
+			Response: object(map[string]*Schema{"message": str(), "type": dataType}),
+			Errors:   []string{errBadRequest, errValidation, errNotFound, errConflict}},
+		{Method: http.MethodDelete, Path: "/types/:name", Tag: "types", ID: "deleteType",
+			Summary:  "Delete a custom type no component or other type uses",
+			Response: object(map[string]*Schema{"message": str(), "name": str()}),
+			Errors:   []string{errNotFound, errConflict}},
+
+		// Workflow
+		{Method: http.MethodPost, Path: "/workflow/run", Tag: "workflow", ID: "runCode",
+			Summary: "Substitute variables into each item's code and concatenate the results; inline CSV, TSV or JSON Lines data is kept as a temporary dataset. Items in several languages need a bridge",
+			Query:   []Parameter{workspaceHeader()},
+			Body:    codeItemsRequest,
+			Response: object(map[string]*Schema{
+				"message":           str(),
+				"total_items":       integer(),
+				"concatenated_code": str(),
+				"components":        arrayOf(&Schema{Type: "object", AdditionalProperties: &Schema{}}),
+				"dataset_id":        str(),
+				"csv_file":          str(),
+				"data_format":       enum(utils.DatasetFormats()...),
+				"profile":           datasetProfile,
+			}),
+			Errors: []string{errBadRequest, errValidation, errNotFound}},
+		{Method: http.MethodPost, Path: "/workflow/generate-script", Tag: "workflow", ID: "generateScript",
+			Summary:  "Generate an executable program from a JSON-encoded WorkflowConfig and component code: a Python script, or a Node.js or Go harness when the nodes are in that language; column variables and the target column are checked against the workflow's dataset",
+			Query:    generationQuery(),
+			Body:     reg.ref(models.GenerateScriptRequest{}),
+			Response: object(map[string]*Schema{"script": str(), "language": enum(utils.Languages...), "requirements": arrayOf(str()), "message": str()}),
+			Errors:   []string{errBadRequest, errValidation}},
+		{Method: http.MethodPost, Path: "/workflow/export", Tag: "workflow", ID: "exportWorkflow",
+			Summary: "Build a workflow from code items and export it; the default script format returns JSON, other formats download a file. Column variables and the target column are checked against the data",
+			Query: append([]Parameter{
+				query("format", "Export format (default script, the only format of JavaScript and Go components)", enum(utils.ExportFormats()...)),
+				query("bundle", "Stream a zip archive with the artifact, its package manifest, Dockerfile, README, workflow JSON and sample data. Required for items in several languages, which export as one program per language and a run.sh passing the data between them in the bridge format", enum(utils.BundleFormat)),
+			}, generationQuery()...),
+			Body: codeItemsRequest,
+			Response: object(map[string]*Schema{
+				"script":            str(),
+				"language":          enum(utils.Languages...),
+				"concatenated_code": str(),
+				"message":           str(),
+				"total_components":  integer(),
+				"requirements":      arrayOf(str()),
+			}),
+			Downloads: append(exportContentTypes(), "application/zip"),
+			Errors:    []string{errBadRequest, errValidation, errNotFound}},
+
+		// Templates
+		{Method: http.MethodGet, Path: "/templates", Tag: "templates", ID: "listTemplates",
+			Summary: "List the built-in template and the workspace's templates, without their source",
+			Query:   []Parameter{workspaceHeader()},
+			Response: object(map[string]*Schema{
+				"count": integer(), "blocks": arrayOf(enum(utils.ScriptBlocks...)), "templates": arrayOf(scriptTemplate),
+			}),
+			Errors: []string{errValidation}},
+		{Method: http.MethodGet, Path: "/templates/:name", Tag: "templates", ID: "getTemplate",
+			Summary:  "Get a template with its source; " + utils.DefaultTemplateName + " returns the built-in source to start from",
+			Query:    []Parameter{workspaceHeader()},
+			Response: scriptTemplate, Errors: []string{errValidation, errNotFound}},
+		{Method: http.MethodPut, Path: "/templates/:name", Tag: "templates", ID: "uploadTemplate",
+			Summary:  "Create or replace a workspace template; the content may only redefine the blocks listed by listTemplates",
+			Query:    []Parameter{workspaceHeader()},
+			Body:     reg.ref(models.TemplateUpload{}),
+			Response: object(map[string]*Schema{"message": str(), "template": scriptTemplate}),
+			Errors:   []string{errBadRequest, errValidation}},
+		{Method: http.MethodDelete, Path: "/templates/:name", Tag: "templates", ID: "deleteTemplate",
+			Summary: "Delete a workspace template", Query: []Parameter{workspaceHeader()},
+			Response: object(map[string]*Schema{"message": str(), "name": str()}),
+			Errors:   []string{errValidation, errNotFound}},
+		{Method: http.MethodPost, Path: "/templates/preview", Tag: "templates", ID: "previewTemplate",
+			Summary: "Render a stored or unsaved template against a workflow, or a sample workflow, without saving it",
+			Query:   append([]Parameter{workspaceHeader()}, reproducibilityQuery()...),
+			Body:    reg.ref(models.TemplatePreviewRequest{}),
+			Response: object(map[string]*Schema{
+				"template": str(), "blocks": arrayOf(str()), "script": str(),
+			}),
+			Errors: []string{errBadRequest, errValidation, errNotFound}},
+
+		// Datasets
+		{Method: http.MethodPost, Path: "/datasets", Tag: "datasets", ID: "uploadDataset",
+			Summary:  "Upload a CSV, TSV, JSON Lines, Parquet or XLSX dataset; identical content is stored once",
+			Query:    []Parameter{workspaceHeader()},
+			BodyType: "multipart/form-data",
+			Body: &Schema{Type: "object", Required: []string{"file"}, Properties: map[string]*Schema{
+				"file":      {Type: "string", Format: "binary"},
+				"name":      {Type: "string", Description: "Display name (default the file name)"},
+				"retention": {Type: "string", Description: `How long the dataset is kept after its last use, e.g. "72h", "30d" or "forever"`},
+				"format":    datasetFormat,
+			}},
+			Status:   http.StatusCreated,
+			Response: object(map[string]*Schema{"message": str(), "dataset": dataset}),
+			Errors:   []string{errValidation, errConflict}},
+		{Method: http.MethodPost, Path: "/datasets/profile", Tag: "datasets", ID: "profileDataset",
+			Summary:  "Infer the column types and statistics of a dataset file without storing it",
+			BodyType: "multipart/form-data",
+			Body: &Schema{Type: "object", Required: []string{"file"}, Properties: map[string]*Schema{
+				"file":   {Type: "string", Format: "binary"},
+				"format": datasetFormat,
+			}},
+			Response: datasetProfile,
+			Errors:   []string{errValidation}},
+		{Method: http.MethodGet, Path: "/datasets", Tag: "datasets", ID: "listDatasets",
+			Summary: "List the workspace's datasets, newest first",
+			Query: []Parameter{
+				workspaceHeader(),
+				query("temporary", "Only temporary (true) or uploaded (false) datasets", enum("true", "false")),
+			},
+			Response: object(map[string]*Schema{"count": integer(), "datasets": arrayOf(dataset)}),
+			Errors:   []string{errValidation}},
+		{Method: http.MethodGet, Path: "/datasets/:id", Tag: "datasets", ID: "getDataset",
+			Summary: "Get the metadata of a dataset", Query: []Parameter{workspaceHeader()},
+			Response: dataset, Errors: []string{errBadRequest, errValidation, errNotFound}},
+		{Method: http.MethodGet, Path: "/datasets/:id/download", Tag: "datasets", ID: "downloadDataset",
+			Summary: "Download the content of a dataset; restarts its retention period",
+			Query:   []Parameter{workspaceHeader()}, ContentType: "application/octet-stream",
+			Response: &Schema{Type: "string", Format: "binary"},
+			Errors:   []string{errBadRequest, errValidation, errNotFound}},
+		{Method: http.MethodDelete, Path: "/datasets/:id", Tag: "datasets", ID: "deleteDataset",
+			Summary: "Delete a dataset, and its content unless another dataset shares it",
+			Query:   []Parameter{workspaceHeader()}, Response: message(),
+			Errors: []string{errBadRequest, errValidation, errNotFound}},
+
+		// Docs
+		{Method: http.MethodGet, Path: "/openapi.json", Tag: "docs", ID: "getOpenAPISpec",
+			Summary: "This OpenAPI document", Response: &Schema{Type: "object"}},
+		{Method: http.MethodGet, Path: "/docs", Tag: "docs", ID: "getDocsPage",
+			Summary: "Interactive API documentation", ContentType: "text/html", Response: str()},
+		{Method: http.MethodGet, Path: "/docs/assets/*file", Tag: "docs", ID: "getDocsAsset",
+			Summary: "A script or stylesheet of the documentation page, served with the API", ContentType: "application/octet-stream", Response: str(),
+			Errors: []string{errNotFound}},
+	}
+}
@@ -0,0 +1,133 @@
+//go:build ignore
+
+// swagger_gen.go vendors the files of Swagger UI the docs page needs into
+// assets. It downloads the swagger-ui-dist package of SwaggerUIVersion from
+// the npm registry and checks it against the integrity the registry
+// publishes for it. Run it with go generate ./src/docs.
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// vendored maps the files of the package to keep to their name in assets
+var vendored = map[string]string{
+	"package/swagger-ui.css":       "swagger-ui.css",
+	"package/swagger-ui-bundle.js": "swagger-ui-bundle.js",
+	"package/LICENSE":              "LICENSE.swagger-ui",
+}
+
+var client = &http.Client{Timeout: time.Minute}
+
+func main() {
+	version, err := pinnedVersion("swagger.go")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var meta struct {
+		Dist struct {
+			Tarball   string `json:"tarball"`
+			Integrity string `json:"integrity"`
+		} `json:"dist"`
+	}
+	body, err := get("https://registry.npmjs.org/swagger-ui-dist/" + version)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := json.Unmarshal(body, &meta); err != nil {
+		log.Fatalf("registry metadata: %v", err)
+	}
+	want, found := strings.CutPrefix(meta.Dist.Integrity, "sha512-")
+	if !found || !strings.HasPrefix(meta.Dist.Tarball, "https://registry.npmjs.org/") {
+		log.Fatalf("registry metadata: unexpected dist %+v", meta.Dist)
+	}
+
+	tarball, err := get(meta.Dist.Tarball)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sum := sha512.Sum512(tarball)
+	if got := base64.StdEncoding.EncodeToString(sum[:]); got != want {
+		log.Fatalf("swagger-ui-dist %s: integrity sha512-%s, the registry publishes sha512-%s", version, got, want)
+	}
+
+	if err := extract(tarball, "assets"); err != nil {
+		log.Fatal(err)
+	}
+	log.Printf("vendored swagger-ui-dist %s into assets", version)
+}
+
+// pinnedVersion reads SwaggerUIVersion from the package source
+func pinnedVersion(path string) (string, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	m := regexp.MustCompile(`SwaggerUIVersion = "([0-9.]+)"`).FindSubmatch(src)
+	if m == nil {
+		return "", fmt.Errorf("%s does not set SwaggerUIVersion", path)
+	}
+	return string(m[1]), nil
+}
+
+func get(url string) ([]byte, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// extract writes the vendored files of the package tarball into dir
+func extract(tarball []byte, dir string) error {
+	gz, err := gzip.NewReader(bytes.NewReader(tarball))
+	if err != nil {
+		return err
+	}
+	tr := tar.NewReader(gz)
+	missing := len(vendored)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		name, ok := vendored[header.Name]
+		if !ok || header.Typeflag != tar.TypeReg {
+			continue
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return err
+		}
+		missing--
+	}
+	if missing > 0 {
+		return fmt.Errorf("the package lacks %d of the vendored files", missing)
+	}
+	return nil
+}
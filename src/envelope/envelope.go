@@ -0,0 +1,51 @@
+// src/envelope/envelope.go
+
+// Package envelope defines the JSON error envelope the API returns and the
+// client decodes. It has no dependencies so that client binaries do not
+// pull in the server's.
+package envelope
+
+// RequestIDHeader is the header used to receive and return request IDs
+const RequestIDHeader = "X-Request-ID"
+
+// UserHeader identifies the calling user until authentication is in place
+const UserHeader = "X-User-ID"
+
+// Stable machine-readable codes shared by several handlers
+const (
+	CodeInvalidJSON      = "invalid_json"
+	CodeInvalidID        = "invalid_id"
+	CodeValidationFailed = "validation_failed"
+	CodeInternal         = "internal_error"
+)
+
+// ErrorResponse is the envelope returned for every failed request
+type ErrorResponse struct {
+	Error ErrorBody `json:"error"`
+}
+
+// ErrorBody carries the stable code, a client-safe message and field details
+type ErrorBody struct {
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError describes a problem with a single request field. Suggestion,
+// when set, is a value that would fix it. Line and Column locate the problem
+// inside a field holding source code.
+type FieldError struct {
+	Field      string `json:"field"`
+	Code       string `json:"code"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	Line       int    `json:"line,omitempty"`
+	Column     int    `json:"column,omitempty"`
+}
+
+// At returns the field error located at line and column of the field's code
+func (f FieldError) At(line, column int) FieldError {
+	f.Line, f.Column = line, column
+	return f
+}
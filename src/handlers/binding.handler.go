@@ -0,0 +1,110 @@
+// src/handlers/binding.handler.go
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"builder.ai/src/apperror"
+)
+
+func init() {
+	// Report validation failures using JSON field names instead of Go names
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+	}
+}
+
+// fromBinding converts JSON decoding and binding-tag failures into a
+// bad request or a validation error with field details
+func fromBinding(err error) *apperror.Error {
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		fields := make([]apperror.FieldError, 0, len(validationErrs))
+		for _, fe := range validationErrs {
+			fields = append(fields, apperror.Field(fieldPath(fe.Namespace()), fe.Tag(), validationMessage(fe)))
+		}
+		return apperror.Validation("Request validation failed", fields...).Wrap(err)
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		field := typeErr.Field
+		if field == "" {
+			field = "body"
+		}
+		return apperror.Validation("Request validation failed",
+			apperror.Field(field, "type", fmt.Sprintf("must be of type %s", jsonTypeName(typeErr.Type))),
+		).Wrap(err)
+	}
+
+	if errors.Is(err, io.EOF) {
+		return apperror.BadRequest(apperror.CodeInvalidJSON, "Empty request body").Wrap(err)
+	}
+
+	return apperror.BadRequest(apperror.CodeInvalidJSON, "Malformed JSON request body").Wrap(err)
+}
+
+// fieldPath drops the root struct name from a validator namespace
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "is required"
+	case "email":
+		return "must be a valid email address"
+	case "oneof":
+		return fmt.Sprintf("must be one of: %s", strings.ReplaceAll(fe.Param(), " ", ", "))
+	case "min":
+		return fmt.Sprintf("must have at least %s item(s)", fe.Param())
+	default:
+		return fmt.Sprintf("failed the %q check", fe.Tag())
+	}
+}
+
+func jsonTypeName(t reflect.Type) string {
+	if t == nil {
+		return "unknown"
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	default:
+		return "object"
+	}
+}
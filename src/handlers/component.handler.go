@@ -11,6 +11,11 @@ import (
     "io"
     "sync"
     "encoding/json"
+    "errors"
+    "os"
+    "os/exec"
+    "path/filepath"
+    "strings"
     "github.com/gin-gonic/gin"
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/bson/primitive"
@@ -18,7 +23,9 @@ import (
     "go.mongodb.org/mongo-driver/mongo/options"
 
     "builder.ai/config"
+    "builder.ai/src/apperror"
     "builder.ai/src/models"
+    "builder.ai/src/utils"
 )
 
 type ComponentHandler struct {
@@ -48,6 +55,10 @@ func (h *ComponentHandler) GetAll(c *gin.Context) {
     if language := c.Query("language"); language != "" {
         filter["language"] = language
     }
+
+    if role := c.Query("role"); role != "" {
+        filter["role"] = role
+    }
     
     // Filter by output type
     if outputType := c.Query("output_type"); outputType != "" {
@@ -65,13 +76,13 @@ func (h *ComponentHandler) GetAll(c *gin.Context) {
 
     cursor, err := h.collection.Find(ctx, filter, opts)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &components); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -89,18 +100,14 @@ func (h *ComponentHandler) GetByID(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
         return
     }
 
     var component models.Component
     err = h.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&component)
     if err != nil {
-        if err == mongo.ErrNoDocuments {
-            c.JSON(http.StatusNotFound, gin.H{"error": "Component not found"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(fromMongo(err, apperror.NotFound("component_not_found", "Component not found")))
         return
     }
 
@@ -114,16 +121,10 @@ func (h *ComponentHandler) GetByStage(c *gin.Context) {
 
     stage := c.Param("stage")
     
-    validStages := []string{"stage1", "stage2", "stage3", "stage4"}
-    isValid := false
-    for _, s := range validStages {
-        if stage == s {
-            isValid = true
-            break
-        }
-    }
-    if !isValid {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stage. Must be stage1, stage2, stage3, or stage4"})
+    if _, ok := utils.GetStage(stage); !ok {
+        c.Error(apperror.Validation("Invalid stage",
+            apperror.Field("stage", "oneof", "must be one of: "+strings.Join(utils.StageNames(), ", ")),
+        ))
         return
     }
 
@@ -131,13 +132,13 @@ func (h *ComponentHandler) GetByStage(c *gin.Context) {
 
     cursor, err := h.collection.Find(ctx, bson.M{"stage": stage})
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &components); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -148,6 +149,10 @@ func (h *ComponentHandler) GetByStage(c *gin.Context) {
     })
 }
 
+// maxComponentsPerCreate caps the components of one Create request, whose
+// code is checked by running each language's checker
+const maxComponentsPerCreate = 100
+
 // Create creates one or more new components
 func (h *ComponentHandler) Create(c *gin.Context) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -159,13 +164,13 @@ func (h *ComponentHandler) Create(c *gin.Context) {
 	// Peek first byte to check if it's an array or object
 	body, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		c.Error(apperror.BadRequest("unreadable_body", "Failed to read request body").Wrap(err))
 		return
 	}
 
 	// Determine whether input is an array or single object
 	if len(body) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Empty request body"})
+		c.Error(apperror.BadRequest(apperror.CodeInvalidJSON, "Empty request body"))
 		return
 	}
 
@@ -173,47 +178,55 @@ func (h *ComponentHandler) Create(c *gin.Context) {
 		// Single component
 		var single models.Component
 		if err := json.Unmarshal(body, &single); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(fromBinding(err))
 			return
 		}
 		components = append(components, single)
 	} else if body[0] == '[' {
 		// Array of components
 		if err := json.Unmarshal(body, &components); err != nil {
-			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			c.Error(fromBinding(err))
 			return
 		}
 	} else {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid JSON format"})
+		c.Error(apperror.BadRequest(apperror.CodeInvalidJSON, "Request body must be a JSON object or array"))
 		return
 	}
 
-	// Validation and insertion
-	var inserted []models.Component
-	for _, component := range components {
-		if !component.IsValidStage() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stage. Must be stage1, stage2, stage3, or stage4"})
-			return
-		}
-		if !component.ValidateInputTypes() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input type. Must be string, int, float, bool, list, dict, or any"})
-			return
-		}
-		if !component.ValidateOutputType() {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid output type. Must be string, int, float, bool, list, dict, any, or none"})
-			return
-		}
-		if len(component.Inputs) == 0 {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Component must have at least one input"})
-			return
+	if len(components) > maxComponentsPerCreate {
+		c.Error(apperror.BadRequest("too_many_components", fmt.Sprintf("At most %d components can be created at once", maxComponentsPerCreate)))
+		return
+	}
+
+	// Validate everything, including the syntax of the code, before
+	// inserting anything
+	var fieldErrs []apperror.FieldError
+	syntaxErrs := utils.CheckComponentsSyntax(c.Request.Context(), components)
+	for i, component := range components {
+		errs := append(utils.ValidateComponent(&component), syntaxErrs[i]...)
+		if body[0] == '[' {
+			errs = apperror.PrefixFields(fmt.Sprintf("[%d].", i), errs)
 		}
+		fieldErrs = append(fieldErrs, errs...)
+	}
+	if len(fieldErrs) > 0 {
+		c.Error(apperror.Validation("Invalid component", fieldErrs...))
+		return
+	}
 
+	// Insertion
+	var inserted []models.Component
+	for _, component := range components {
+		detectDependencies(&component)
+		component.TestRuns, component.Verified = nil, false
+		utils.NormalizeComponentTypes(&component)
+		utils.IndexComponentReferences(&component)
 		component.CreatedAt = time.Now()
 		component.UpdatedAt = time.Now()
 
 		result, err := h.collection.InsertOne(ctx, component)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Error(fromMongo(err, nil))
 			return
 		}
 
@@ -227,6 +240,35 @@ func (h *ComponentHandler) Create(c *gin.Context) {
 	})
 }
 
+// detectDependencies adds the packages imported by Python code to the
+// component's dependencies, keeping versions the client already pinned
+func detectDependencies(component *models.Component) {
+    if !strings.EqualFold(component.Language, "python") {
+        return
+    }
+    component.Dependencies = utils.MergeRequirements(component.Dependencies, utils.PythonRequirements(component.Code))
+}
+
+// GetLanguages lists the languages components can be written in, with the
+// syntax checker their code goes through on create and update
+func (h *ComponentHandler) GetLanguages(c *gin.Context) {
+    languages := make([]gin.H, 0, len(utils.Languages))
+    for _, name := range utils.Languages {
+        l, _ := utils.GetLanguage(name)
+        languages = append(languages, gin.H{
+            "name":      l.Name(),
+            "extension": l.Extension(),
+            "image":     l.Runtime().Image,
+            "checker":   utils.SyntaxChecker(l),
+        })
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "count":     len(languages),
+        "languages": languages,
+    })
+}
+
 // Update updates a component by ID
 func (h *ComponentHandler) Update(c *gin.Context) {
     ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
@@ -235,67 +277,213 @@ func (h *ComponentHandler) Update(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
         return
     }
 
     var component models.Component
     if err := c.ShouldBindJSON(&component); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(fromBinding(err))
         return
     }
 
-    if !component.IsValidStage() {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid stage"})
+    if fieldErrs := append(utils.ValidateComponent(&component), utils.CheckComponentSyntax(c.Request.Context(), &component)...); len(fieldErrs) > 0 {
+        c.Error(apperror.Validation("Invalid component", fieldErrs...))
         return
     }
 
-    if !component.ValidateInputTypes() {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid input type"})
+    // The component stays verified while its code and tests are unchanged;
+    // tests left out of the request are kept
+    var current models.Component
+    err = h.collection.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(bson.M{
+        "name": 1, "code": 1, "language": 1, "function": 1, "verified": 1, "tests": 1,
+    })).Decode(&current)
+    if err != nil {
+        c.Error(fromMongo(err, apperror.NotFound("component_not_found", "Component not found")))
+        return
+    }
+    if component.Tests == nil && len(current.Tests) > 0 && component.Language != utils.LanguagePython {
+        c.Error(apperror.Validation("Invalid component", apperror.Field("tests", "language", "tests run Python components only, remove them to change the language")))
         return
     }
 
-    if !component.ValidateOutputType() {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid output type"})
+    detectDependencies(&component)
+    utils.NormalizeComponentTypes(&component)
+    utils.IndexComponentReferences(&component)
+    component.UpdatedAt = time.Now()
+
+    // Renaming the function or changing the language breaks the components
+    // calling it
+    var warnings []string
+    if component.Function != current.Function || component.Language != current.Language {
+        warnings, err = h.checkCallers(ctx, c, current, "updated")
+        if err != nil {
+            c.Error(err)
+            return
+        }
+    }
+
+    set := bson.M{
+        "name":        component.Name,
+        "description": component.Description,
+        "code":        component.Code,
+        "language":    component.Language,
+        "stage":       component.Stage,
+        "role":        component.Role,
+        "tags":        component.Tags,
+        "inputs":      component.Inputs,
+        "output":      component.Output,
+        "dependencies": component.Dependencies,
+        "function":    component.Function,
+        "calls":       component.Calls,
+        "verified":    current.Verified && component.Tests == nil && current.Code == component.Code && current.Language == component.Language,
+        "updated_at":  component.UpdatedAt,
+    }
+    if component.Tests != nil {
+        set["tests"] = component.Tests
+    }
+
+    result, err := h.collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": set})
+    if err != nil {
+        c.Error(fromMongo(err, nil))
         return
     }
 
-    if len(component.Inputs) == 0 {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Component must have at least one input"})
+    if result.MatchedCount == 0 {
+        c.Error(apperror.NotFound("component_not_found", "Component not found"))
         return
     }
 
-    component.UpdatedAt = time.Now()
+    response := gin.H{
+        "message": "Component updated successfully",
+        "id":      id,
+    }
+    if len(warnings) > 0 {
+        response["warnings"] = warnings
+    }
+    c.JSON(http.StatusOK, response)
+}
 
-    update := bson.M{
-        "$set": bson.M{
-            "name":        component.Name,
-            "description": component.Description,
-            "code":        component.Code,
-            "language":    component.Language,
-            "stage":       component.Stage,
-            "tags":        component.Tags,
-            "inputs":      component.Inputs,
-            "output":      component.Output,
-            "updated_at":  component.UpdatedAt,
-        },
+// componentTestTimeout bounds a run of a component's tests, including
+// starting the executor's container
+const componentTestTimeout = 2 * time.Minute
+
+// maxTestRunError caps the executor output kept for a run that failed
+// before reporting results
+const maxTestRunError = 4000
+
+// RunTests runs the component's tests in the sandboxed executor and
+// records the run in the component's history. The component is verified
+// while its latest run passes. Tests run with the vetted packages of
+// utils.ExecutorPackages; components needing others cannot be tested.
+func (h *ComponentHandler) RunTests(c *gin.Context) {
+    ctx, cancel := context.WithTimeout(c.Request.Context(), componentTestTimeout)
+    defer cancel()
+
+    id := c.Param("id")
+    objectID, err := primitive.ObjectIDFromHex(id)
+    if err != nil {
+        c.Error(apperror.InvalidID())
+        return
     }
 
-    result, err := h.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
+    var component models.Component
+    err = h.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&component)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(fromMongo(err, apperror.NotFound("component_not_found", "Component not found")))
+        return
+    }
+    if len(component.Tests) == 0 {
+        c.Error(apperror.Validation("Component has no tests", apperror.Field("tests", "required", "add tests to the component before running them")))
         return
     }
 
-    if result.MatchedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Component not found"})
+    script, err := utils.GenerateComponentTests(component.Name, component.Code, component.InputTypes(), component.Tests)
+    if err != nil {
+        c.Error(apperror.Validation("Invalid component tests", apperror.Field("tests", "invalid", err.Error())).Wrap(err))
+        return
+    }
+
+    requirements := utils.MergeRequirements(utils.PythonRequirements(script), component.Dependencies)
+    if err := utils.CheckExecutorRequirements(requirements); err != nil {
+        c.Error(apperror.Validation("Component needs packages tests cannot use", apperror.Field("dependencies", "executor", err.Error())).Wrap(err))
+        return
+    }
+    // Only the harness, running as root in the container, can write the
+    // results: the directory is private to the user of the server
+    resultsDir, err := os.MkdirTemp("", "component_tests_*")
+    if err != nil {
+        c.Error(apperror.Internal(fmt.Errorf("run component tests: %w", err)))
+        return
+    }
+    defer os.RemoveAll(resultsDir)
+
+    output, err := executeInDocker(ctx, component.Language, script, resultsDir)
+    run := utils.ComponentTestRun{RanAt: time.Now()}
+    data, _ := os.ReadFile(filepath.Join(resultsDir, utils.ComponentTestResultsFile))
+    if results, ok := utils.ParseComponentTestResults(data); ok {
+        run = utils.NewComponentTestRun(component.Tests, results, run.RanAt)
+    } else {
+        if err == nil {
+            c.Error(apperror.Internal(fmt.Errorf("run component tests: no results written: %s", output)))
+            return
+        }
+        // Exit codes from 125 up are the executor's own failures
+        var exitErr *exec.ExitError
+        if !errors.As(err, &exitErr) || exitErr.ExitCode() >= 125 {
+            c.Error(apperror.Internal(fmt.Errorf("run component tests: %w: %s", err, output)))
+            return
+        }
+        if len(output) > maxTestRunError {
+            output = output[len(output)-maxTestRunError:]
+        }
+        run.Error = strings.TrimSpace(output)
+    }
+
+    if err := recordTestRun(ctx, h.collection, &component, run); err != nil {
+        c.Error(err)
         return
     }
 
     c.JSON(http.StatusOK, gin.H{
-        "message": "Component updated successfully",
-        "id":      id,
+        "message":  fmt.Sprintf("%d of %d test(s) passed", passedTests(run), len(component.Tests)),
+        "id":       id,
+        "verified": run.Passed,
+        "run":      run,
+    })
+}
+
+// recordTestRun adds the run to the component's history and verifies the
+// component when it passed. The run only counts for the code and tests it
+// ran: a component updated since it was read is left as is.
+func recordTestRun(ctx context.Context, collection *mongo.Collection, component *models.Component, run utils.ComponentTestRun) error {
+    var updatedAt interface{} = component.UpdatedAt
+    if component.UpdatedAt.IsZero() {
+        // Components never updated may have no updated_at
+        updatedAt = bson.M{"$in": bson.A{nil, component.UpdatedAt}}
+    }
+    result, err := collection.UpdateOne(ctx, bson.M{"_id": component.ID, "updated_at": updatedAt}, bson.M{
+        "$push": bson.M{"test_runs": bson.M{"$each": []utils.ComponentTestRun{run}, "$position": 0, "$slice": models.MaxTestRuns}},
+        "$set":  bson.M{"verified": run.Passed},
     })
+    if err != nil {
+        return fromMongo(err, nil)
+    }
+    if result.MatchedCount == 0 {
+        return apperror.Conflict("component_changed", "Component was updated or deleted while its tests ran, run them again")
+    }
+    return nil
+}
+
+// passedTests counts the tests of a run that passed
+func passedTests(run utils.ComponentTestRun) int {
+    passed := 0
+    for _, result := range run.Results {
+        if result.Passed {
+            passed++
+        }
+    }
+    return passed
 }
 
 // Delete deletes a component by ID
@@ -306,25 +494,159 @@ func (h *ComponentHandler) Delete(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
+        return
+    }
+
+    var current models.Component
+    err = h.collection.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(bson.M{
+        "name": 1, "language": 1, "function": 1,
+    })).Decode(&current)
+    if err != nil {
+        c.Error(fromMongo(err, apperror.NotFound("component_not_found", "Component not found")))
+        return
+    }
+
+    warnings, err := h.checkCallers(ctx, c, current, "deleted")
+    if err != nil {
+        c.Error(err)
         return
     }
 
     result, err := h.collection.DeleteOne(ctx, bson.M{"_id": objectID})
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
     if result.DeletedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "Component not found"})
+        c.Error(apperror.NotFound("component_not_found", "Component not found"))
         return
     }
 
-    c.JSON(http.StatusOK, gin.H{
+    response := gin.H{
         "message": "Component deleted successfully",
         "id":      id,
+    }
+    if len(warnings) > 0 {
+        response["warnings"] = warnings
+    }
+    c.JSON(http.StatusOK, response)
+}
+
+// GetUsages lists the components whose code calls the component's function.
+// Components are the only users the server knows of: workflows are not
+// stored, each run or export sends the code of its items, so they cannot
+// be listed or checked here.
+func (h *ComponentHandler) GetUsages(c *gin.Context) {
+    h.listReferences(c, func(component models.Component) bson.M {
+        return bson.M{"language": component.Language, "calls": component.Function}
+    })
+}
+
+// GetDependencies lists the components defining functions the component's
+// code calls
+func (h *ComponentHandler) GetDependencies(c *gin.Context) {
+    h.listReferences(c, func(component models.Component) bson.M {
+        return bson.M{"language": component.Language, "function": bson.M{"$in": component.Calls}}
+    })
+}
+
+// listReferences responds with the other components matching the filter
+// built from the component with the ID of the request
+func (h *ComponentHandler) listReferences(c *gin.Context, filter func(models.Component) bson.M) {
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+
+    id := c.Param("id")
+    objectID, err := primitive.ObjectIDFromHex(id)
+    if err != nil {
+        c.Error(apperror.InvalidID())
+        return
+    }
+
+    var component models.Component
+    err = h.collection.FindOne(ctx, bson.M{"_id": objectID}, options.FindOne().SetProjection(bson.M{
+        "language": 1, "function": 1, "calls": 1,
+    })).Decode(&component)
+    if err != nil {
+        c.Error(fromMongo(err, apperror.NotFound("component_not_found", "Component not found")))
+        return
+    }
+
+    components := []models.Component{}
+    if component.Function != "" || len(component.Calls) > 0 {
+        query := filter(component)
+        query["_id"] = bson.M{"$ne": objectID}
+        cursor, err := h.collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+        if err != nil {
+            c.Error(apperror.Internal(err))
+            return
+        }
+        defer cursor.Close(ctx)
+        if err := cursor.All(ctx, &components); err != nil {
+            c.Error(apperror.Internal(err))
+            return
+        }
+    }
+
+    c.JSON(http.StatusOK, gin.H{
+        "id":         id,
+        "function":   component.Function,
+        "count":      len(components),
+        "components": components,
+    })
+}
+
+// checkCallers looks for the components calling the function of current
+// before it is updated or deleted. When no other component defines the
+// function they would break: that is a conflict unless the request passes
+// force=true, in which case the broken callers are returned as warnings.
+// Workflows are not stored, so the ones using current cannot be checked.
+func (h *ComponentHandler) checkCallers(ctx context.Context, c *gin.Context, current models.Component, action string) ([]string, error) {
+    if current.Function == "" {
+        return nil, nil
+    }
+    others, err := h.collection.CountDocuments(ctx, bson.M{
+        "_id": bson.M{"$ne": current.ID}, "language": current.Language, "function": current.Function,
     })
+    if err != nil {
+        return nil, apperror.Internal(err)
+    }
+    if others > 0 {
+        return nil, nil
+    }
+
+    cursor, err := h.collection.Find(ctx, bson.M{
+        "_id": bson.M{"$ne": current.ID}, "language": current.Language, "calls": current.Function,
+    }, options.Find().SetProjection(bson.M{"name": 1}).SetSort(bson.D{{Key: "name", Value: 1}}))
+    if err != nil {
+        return nil, apperror.Internal(err)
+    }
+    defer cursor.Close(ctx)
+    var callers []models.Component
+    if err := cursor.All(ctx, &callers); err != nil {
+        return nil, apperror.Internal(err)
+    }
+    if len(callers) == 0 {
+        return nil, nil
+    }
+
+    names := make([]string, len(callers))
+    for i, caller := range callers {
+        names[i] = fmt.Sprintf("%s (%s)", caller.Name, caller.ID.Hex())
+    }
+    if c.Query("force") != "true" {
+        return nil, apperror.Conflict("component_in_use", fmt.Sprintf(
+            "%s() is called by %s; pass force=true to proceed anyway",
+            current.Function, strings.Join(names, ", "),
+        ))
+    }
+    warnings := make([]string, len(names))
+    for i, name := range names {
+        warnings[i] = fmt.Sprintf("%s calls %s(), which no component defines once this one is %s", name, current.Function, action)
+    }
+    return warnings, nil
 }
 
 // SearchByName searches components by name with pagination and optimizations
@@ -335,7 +657,7 @@ func (h *ComponentHandler) SearchByName(c *gin.Context) {
     // Get query parameters
     name := c.Query("name")
     if name == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Name query parameter is required"})
+        c.Error(apperror.Validation("Missing query parameter", apperror.Field("name", "required", "is required")))
         return
     }
 
@@ -371,6 +693,11 @@ func (h *ComponentHandler) SearchByName(c *gin.Context) {
         filter["stage"] = stage
     }
 
+    // Only components whose tests passed
+    if c.Query("verified") == "true" {
+        filter["verified"] = true
+    }
+
     // Get total count and results in parallel using goroutines
     var totalCount int64
     var components []models.Component
@@ -400,6 +727,7 @@ func (h *ComponentHandler) SearchByName(c *gin.Context) {
                 "inputs":      1,
                 "output":      1,
                 "code":        1,
+                "verified":    1,
             })
 
         cursor, err := h.collection.Find(ctx, filter, findOptions)
@@ -417,11 +745,11 @@ func (h *ComponentHandler) SearchByName(c *gin.Context) {
 
     // Check for errors
     if countErr != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to count documents"})
+        c.Error(apperror.Internal(fmt.Errorf("count components: %w", countErr)))
         return
     }
     if findErr != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to execute search"})
+        c.Error(apperror.Internal(fmt.Errorf("search components: %w", findErr)))
         return
     }
 
@@ -494,14 +822,14 @@ func (h *ComponentHandler) GetStageStats(c *gin.Context) {
 
     cursor, err := h.collection.Aggregate(ctx, pipeline)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     var results []bson.M
     if err = cursor.All(ctx, &results); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -517,22 +845,24 @@ func (h *ComponentHandler) GetByInputType(c *gin.Context) {
 
     inputType := c.Query("type")
     if inputType == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Type query parameter is required"})
+        c.Error(apperror.Validation("Missing query parameter", apperror.Field("type", "required", "is required")))
         return
     }
+    // Components store canonical type names, see utils.NormalizeComponentTypes
+    inputType = utils.CanonicalType(inputType)
 
     var components []models.Component
 
     filter := bson.M{"inputs.type": inputType}
     cursor, err := h.collection.Find(ctx, filter)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &components); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -550,22 +880,24 @@ func (h *ComponentHandler) GetByOutputType(c *gin.Context) {
 
     outputType := c.Query("type")
     if outputType == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Type query parameter is required"})
+        c.Error(apperror.Validation("Missing query parameter", apperror.Field("type", "required", "is required")))
         return
     }
+    // Components store canonical type names, see utils.NormalizeComponentTypes
+    outputType = utils.CanonicalType(outputType)
 
     var components []models.Component
 
     filter := bson.M{"output.type": outputType}
     cursor, err := h.collection.Find(ctx, filter)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &components); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
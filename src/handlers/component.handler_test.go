@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+func TestRecordTestRun(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	updatedAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	run := utils.ComponentTestRun{RanAt: updatedAt.Add(time.Hour), Passed: true}
+
+	mt.Run("verifies the component the tests ran on", func(mt *mtest.T) {
+		component := &models.Component{ID: primitive.NewObjectID(), UpdatedAt: updatedAt}
+		mt.AddMockResponses(updated(1))
+
+		if err := recordTestRun(context.Background(), mt.Coll, component, run); err != nil {
+			mt.Fatal(err)
+		}
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if got := update.Lookup("q", "updated_at").Time(); !got.Equal(updatedAt) {
+			mt.Errorf("filter updated_at = %s, want %s", got, updatedAt)
+		}
+		if !update.Lookup("u", "$set", "verified").Boolean() {
+			mt.Error("the component is not verified")
+		}
+	})
+
+	mt.Run("matches components never updated", func(mt *mtest.T) {
+		component := &models.Component{ID: primitive.NewObjectID()}
+		mt.AddMockResponses(updated(1))
+
+		if err := recordTestRun(context.Background(), mt.Coll, component, run); err != nil {
+			mt.Fatal(err)
+		}
+		update := mt.GetStartedEvent().Command.Lookup("updates").Array().Index(0).Value().Document()
+		if _, ok := update.Lookup("q", "updated_at", "$in").ArrayOK(); !ok {
+			mt.Errorf("filter = %s, want updated_at missing or zero", update.Lookup("q"))
+		}
+	})
+
+	mt.Run("conflicts when the component changed during the run", func(mt *mtest.T) {
+		component := &models.Component{ID: primitive.NewObjectID(), UpdatedAt: updatedAt}
+		mt.AddMockResponses(mtest.CreateSuccessResponse(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}))
+
+		err := recordTestRun(context.Background(), mt.Coll, component, run)
+		if apperror.As(err).Status() != http.StatusConflict {
+			mt.Fatalf("err = %v, want a conflict", err)
+		}
+	})
+}
+
+func TestUpdateKeepsTestsOfPythonComponentsOnly(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("rejects a language change keeping the tests", func(mt *mtest.T) {
+		id := primitive.NewObjectID()
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.Coll.Database().Name()+"."+mt.Coll.Name(), mtest.FirstBatch, bson.D{
+			{Key: "_id", Value: id},
+			{Key: "language", Value: utils.LanguagePython},
+			{Key: "tests", Value: bson.A{bson.D{{Key: "name", Value: "keeps rows"}}}},
+		}))
+
+		body := `{"name": "Drop sparse", "code": "function dropSparse(rows) { return rows }", "language": "javascript",
+			"stage": "stage1", "role": "transform", "inputs": [{"name": "rows", "type": "dataframe"}]}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/components/"+id.Hex(), strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: id.Hex()}}
+
+		(&ComponentHandler{collection: mt.Coll}).Update(c)
+
+		if len(c.Errors) == 0 {
+			mt.Fatal("Update() recorded no error")
+		}
+		err := apperror.As(c.Errors.Last().Err)
+		if err.Kind != apperror.KindValidation || len(err.Fields) != 1 || err.Fields[0].Field != "tests" {
+			mt.Fatalf("err = %+v, want a validation error on tests", err)
+		}
+		if got := commands(mt); len(got) != 1 {
+			mt.Errorf("commands = %v, want only the find", got)
+		}
+	})
+}
+
+func TestGetByTypeAcceptsAliases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	tests := []struct {
+		name  string
+		get   func(*ComponentHandler, *gin.Context)
+		field string
+	}{
+		{"input", (*ComponentHandler).GetByInputType, "inputs.type"},
+		{"output", (*ComponentHandler).GetByOutputType, "output.type"},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			ns := mt.Coll.Database().Name() + "." + mt.Coll.Name()
+			mt.AddMockResponses(mtest.CreateCursorResponse(0, ns, mtest.FirstBatch))
+
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/components/by-type?type=df", nil)
+
+			tt.get(&ComponentHandler{collection: mt.Coll}, c)
+
+			if len(c.Errors) > 0 {
+				mt.Fatal(c.Errors.Last())
+			}
+			filter := mt.GetStartedEvent().Command.Lookup("filter")
+			if got := filter.Document().Lookup(tt.field).StringValue(); got != utils.TypeDataFrame {
+				mt.Errorf("filter = %s, want %s: %s", filter, tt.field, utils.TypeDataFrame)
+			}
+			if !strings.Contains(w.Body.String(), `"`+utils.TypeDataFrame+`"`) {
+				mt.Errorf("body = %s, want the canonical type", w.Body)
+			}
+		})
+	}
+}
@@ -0,0 +1,527 @@
+// src/handlers/dataset.handler.go
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/config"
+	"builder.ai/src/apperror"
+	"builder.ai/src/middleware"
+	"builder.ai/src/models"
+	"builder.ai/src/storage"
+	"builder.ai/src/utils"
+)
+
+type DatasetHandler struct {
+	collection *mongo.Collection
+	blobs      *mongo.Collection
+	store      storage.BlobStore
+	settings   config.DatasetSettings
+}
+
+func NewDatasetHandler() *DatasetHandler {
+	return &DatasetHandler{
+		collection: config.GetCollection("datasets"),
+		blobs:      config.GetCollection("blobs"),
+		store:      config.BlobStore(),
+		settings:   config.Datasets(),
+	}
+}
+
+// Upload stores a multipart "file" as a dataset. The optional "name" field
+// defaults to the file name, "retention" to the configured retention and
+// "format" to the format detected from the file.
+func (h *DatasetHandler) Upload(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	header, err := datasetFile(c, h.settings.MaxSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	retention := c.DefaultPostForm("retention", h.settings.Retention)
+	if _, err := models.ParseRetention(retention); err != nil {
+		c.Error(apperror.Validation("Invalid dataset upload",
+			apperror.Field("retention", "retention", `must be a duration such as "72h", a number of days such as "30d", or "`+models.RetentionForever+`"`),
+		).Wrap(err))
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer file.Close()
+
+	format, err := datasetFormat(c, header, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filename := filepath.Base(header.Filename)
+	dataset := models.Dataset{
+		Workspace:   workspace,
+		Name:        c.DefaultPostForm("name", filename),
+		Filename:    filename,
+		ContentType: format.ContentType,
+		Format:      format.Name,
+		Retention:   retention,
+		CreatedBy:   c.GetHeader(middleware.UserHeader),
+	}
+
+	if err := saveDataset(ctx, h.collection, h.blobs, h.store, &dataset, file, h.settings.MaxSize); err != nil {
+		c.Error(err)
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "uploaded dataset", "dataset", dataset.ID.Hex(), "format", dataset.Format, "bytes", dataset.Size, "sha256", dataset.SHA256)
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Dataset uploaded successfully",
+		"dataset": dataset,
+	})
+}
+
+// Profile infers the column types and statistics of a multipart "file"
+// without storing it. The format is detected as on upload.
+func (h *DatasetHandler) Profile(c *gin.Context) {
+	header, err := datasetFile(c, h.settings.MaxSize)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer file.Close()
+
+	format, err := datasetFormat(c, header, file)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	profile, err := utils.ProfileDataset(file, format.Name)
+	if err != nil {
+		c.Error(apperror.Validation("Invalid dataset file",
+			apperror.Field("file", format.Name, err.Error()),
+		).Wrap(err))
+		return
+	}
+	c.JSON(http.StatusOK, profile)
+}
+
+// List returns the workspace's datasets, newest first. ?temporary=false
+// leaves out CSVs sent inline with workflows.
+func (h *DatasetHandler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	filter := bson.M{"workspace": workspace}
+	if raw := c.Query("temporary"); raw != "" {
+		temporary, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.Error(apperror.Validation("Invalid query",
+				apperror.Field("temporary", "boolean", "must be true or false"),
+			))
+			return
+		}
+		filter["temporary"] = temporary
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	cursor, err := h.collection.Find(ctx, filter, opts)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	datasets := []models.Dataset{}
+	if err := cursor.All(ctx, &datasets); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(datasets),
+		"datasets": datasets,
+	})
+}
+
+// Get returns the metadata of a dataset
+func (h *DatasetHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	dataset, err := findDataset(ctx, h.collection, workspace, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	c.JSON(http.StatusOK, dataset)
+}
+
+// Download streams the content of a dataset and restarts its retention
+func (h *DatasetHandler) Download(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	dataset, content, err := openDataset(ctx, h.collection, h.store, workspace, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer content.Close()
+
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": dataset.Filename}))
+	c.DataFromReader(http.StatusOK, dataset.Size, dataset.ContentType, content, nil)
+}
+
+// Delete removes a dataset, and its content when no other dataset shares it
+func (h *DatasetHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	dataset, err := findDataset(ctx, h.collection, workspace, c.Param("id"))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := deleteDataset(ctx, h.collection, h.blobs, h.store, dataset); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Dataset deleted successfully",
+		"id":      dataset.ID.Hex(),
+	})
+}
+
+// SweepExpired deletes the datasets whose retention has run out and
+// returns how many were deleted
+func (h *DatasetHandler) SweepExpired(ctx context.Context) (int, error) {
+	cursor, err := h.collection.Find(ctx, bson.M{"expires_at": bson.M{"$lte": time.Now()}})
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var expired []models.Dataset
+	if err := cursor.All(ctx, &expired); err != nil {
+		return 0, err
+	}
+
+	deleted := 0
+	for i := range expired {
+		if err := deleteDataset(ctx, h.collection, h.blobs, h.store, &expired[i]); err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	return deleted, nil
+}
+
+// RunSweeper calls SweepExpired every interval until ctx is done
+func (h *DatasetHandler) RunSweeper(ctx context.Context) {
+	ticker := time.NewTicker(h.settings.SweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := h.SweepExpired(ctx)
+			if err != nil {
+				slog.Error("failed to sweep expired datasets", "error", err)
+			} else if deleted > 0 {
+				slog.Info("swept expired datasets", "datasets", deleted)
+			}
+		}
+	}
+}
+
+// saveDataset stores content in the blob store and inserts the dataset,
+// filling in its ID, size, hash, retention and profile. The dataset holds a
+// reference to its blob in blobs from before it is inserted.
+func saveDataset(ctx context.Context, collection, blobs *mongo.Collection, store storage.BlobStore, dataset *models.Dataset, content io.Reader, maxSize int64) error {
+	key, size, err := store.Put(ctx, content, maxSize)
+	if errors.Is(err, storage.ErrTooLarge) {
+		return datasetTooLarge(maxSize)
+	}
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if err := retainBlob(ctx, blobs, store, key); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	dataset.SHA256 = key
+	dataset.Size = size
+	dataset.Profile = profileDataset(ctx, store, dataset)
+	dataset.CreatedAt = now
+	dataset.Touch(now)
+
+	result, err := collection.InsertOne(ctx, dataset)
+	if err != nil {
+		if releaseErr := releaseBlob(ctx, blobs, store, key); releaseErr != nil {
+			slog.WarnContext(ctx, "failed to release dataset content", "sha256", key, "error", releaseErr)
+		}
+		return fromMongo(err, nil)
+	}
+	dataset.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// retainBlob adds a reference to the blob with the key. A blob whose last
+// reference is being deleted cannot be retained, and neither can one
+// deleted between Put and the new reference: the upload has to be retried.
+// Once retainBlob returns nil the blob stays until the reference is
+// released.
+func retainBlob(ctx context.Context, blobs *mongo.Collection, store storage.BlobStore, key string) error {
+	_, err := blobs.UpdateOne(ctx,
+		bson.M{"_id": key, "deleting": bson.M{"$ne": true}},
+		bson.M{"$inc": bson.M{"refs": 1}},
+		options.Update().SetUpsert(true),
+	)
+	if mongo.IsDuplicateKeyError(err) {
+		return datasetConflict(err)
+	}
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	ok, err := store.Exists(ctx, key)
+	if err != nil || !ok {
+		if _, decErr := blobs.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$inc": bson.M{"refs": -1}}); decErr != nil {
+			slog.WarnContext(ctx, "failed to release dataset content", "sha256", key, "error", decErr)
+		}
+		return datasetConflict(err)
+	}
+	return nil
+}
+
+// releaseBlob removes a reference to the blob with the key and deletes the
+// blob with its last reference. The blob is marked as deleting first, so
+// uploads of the same content fail instead of referencing a blob about to
+// be removed.
+func releaseBlob(ctx context.Context, blobs *mongo.Collection, store storage.BlobStore, key string) error {
+	if _, err := blobs.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$inc": bson.M{"refs": -1}}); err != nil {
+		return err
+	}
+	marked, err := blobs.UpdateOne(ctx,
+		bson.M{"_id": key, "refs": bson.M{"$lte": 0}, "deleting": bson.M{"$ne": true}},
+		bson.M{"$set": bson.M{"deleting": true}},
+	)
+	if err != nil || marked.ModifiedCount == 0 {
+		return err
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		// Unmark the blob so that it can be retained or deleted again
+		if _, unmarkErr := blobs.UpdateOne(ctx, bson.M{"_id": key}, bson.M{"$unset": bson.M{"deleting": ""}}); unmarkErr != nil {
+			slog.WarnContext(ctx, "failed to unmark dataset content", "sha256", key, "error", unmarkErr)
+		}
+		return err
+	}
+	_, err = blobs.DeleteOne(ctx, bson.M{"_id": key, "deleting": true})
+	return err
+}
+
+// profileDataset profiles the stored content of a dataset in its format. A
+// file that cannot be profiled is still stored, without a profile.
+func profileDataset(ctx context.Context, store storage.BlobStore, dataset *models.Dataset) *utils.DatasetProfile {
+	content, err := store.Open(ctx, dataset.SHA256)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to open dataset for profiling", "sha256", dataset.SHA256, "error", err)
+		return nil
+	}
+	defer content.Close()
+
+	profile, err := utils.ProfileDataset(content, dataset.Format)
+	if err != nil {
+		slog.WarnContext(ctx, "failed to profile dataset", "sha256", dataset.SHA256, "format", dataset.Format, "error", err)
+		return nil
+	}
+	return profile
+}
+
+// datasetFile returns the multipart "file" of a request, limiting the body
+// to the maximum dataset size
+func datasetFile(c *gin.Context, maxSize int64) (*multipart.FileHeader, error) {
+	// Leave room for the multipart framing around the file
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize+1<<20)
+	header, err := c.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, datasetTooLarge(maxSize)
+		}
+		return nil, apperror.Validation("Invalid dataset upload",
+			apperror.Field("file", "required", "must be a multipart file field"),
+		).Wrap(err)
+	}
+	if header.Size > maxSize {
+		return nil, datasetTooLarge(maxSize)
+	}
+	return header, nil
+}
+
+// findDataset returns a dataset of the workspace by ID
+func findDataset(ctx context.Context, collection *mongo.Collection, workspace, id string) (*models.Dataset, error) {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, apperror.InvalidID()
+	}
+
+	var dataset models.Dataset
+	err = collection.FindOne(ctx, bson.M{"_id": objectID, "workspace": workspace}).Decode(&dataset)
+	if err != nil {
+		return nil, fromMongo(err, datasetNotFound())
+	}
+	return &dataset, nil
+}
+
+// openDataset returns a dataset of the workspace with its content, and
+// records the use so the dataset is kept for another retention period
+func openDataset(ctx context.Context, collection *mongo.Collection, store storage.BlobStore, workspace, id string) (*models.Dataset, io.ReadCloser, error) {
+	dataset, err := findDataset(ctx, collection, workspace, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := store.Open(ctx, dataset.SHA256)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, apperror.NotFound("dataset_content_missing", "Dataset content is missing from storage").Wrap(err)
+	}
+	if err != nil {
+		return nil, nil, apperror.Internal(err)
+	}
+
+	dataset.Touch(time.Now())
+	_, err = collection.UpdateOne(ctx, bson.M{"_id": dataset.ID}, bson.M{
+		"$set": bson.M{"last_used_at": dataset.LastUsedAt, "expires_at": dataset.ExpiresAt},
+	})
+	if err != nil {
+		// Failing to extend the retention should not fail the request
+		slog.WarnContext(ctx, "failed to record dataset use", "dataset", id, "error", err)
+	}
+	return dataset, content, nil
+}
+
+// readDataset returns the content of a dataset of the workspace
+func readDataset(ctx context.Context, collection *mongo.Collection, store storage.BlobStore, workspace, id string) (*models.Dataset, []byte, error) {
+	dataset, content, err := openDataset(ctx, collection, store, workspace, id)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer content.Close()
+
+	data, err := io.ReadAll(content)
+	if err != nil {
+		return nil, nil, apperror.Internal(err)
+	}
+	return dataset, data, nil
+}
+
+// deleteDataset removes a dataset and releases its blob, which is deleted
+// unless another dataset has the same content. A dataset already deleted
+// by a concurrent request releases nothing.
+func deleteDataset(ctx context.Context, collection, blobs *mongo.Collection, store storage.BlobStore, dataset *models.Dataset) error {
+	result, err := collection.DeleteOne(ctx, bson.M{"_id": dataset.ID})
+	if err != nil || result.DeletedCount == 0 {
+		return err
+	}
+	return releaseBlob(ctx, blobs, store, dataset.SHA256)
+}
+
+func datasetNotFound() *apperror.Error {
+	return apperror.NotFound("dataset_not_found", "Dataset not found")
+}
+
+func datasetConflict(err error) *apperror.Error {
+	return apperror.Conflict("dataset_conflict", "Dataset content was deleted during the upload, retry the upload").Wrap(err)
+}
+
+func datasetTooLarge(maxSize int64) *apperror.Error {
+	return apperror.Validation("Dataset too large",
+		apperror.Field("file", "max", fmt.Sprintf("must be at most %d bytes", maxSize)),
+	)
+}
+
+// datasetFormat returns the format of an uploaded file: the "format" form
+// field when set, otherwise the format detected from its name and first
+// bytes
+func datasetFormat(c *gin.Context, header *multipart.FileHeader, file multipart.File) (utils.DatasetFormat, error) {
+	name := c.PostForm("format")
+	if name == "" {
+		var err error
+		if name, err = utils.SniffDatasetFormat(header.Filename, file); err != nil {
+			return utils.DatasetFormat{}, apperror.Internal(err)
+		}
+	}
+
+	format, ok := utils.GetDatasetFormat(name)
+	if !ok {
+		return format, apperror.Validation("Invalid dataset upload",
+			apperror.Field("format", "oneof", "must be one of: "+strings.Join(utils.DatasetFormats(), ", ")),
+		)
+	}
+	return format, nil
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+	"builder.ai/src/storage"
+)
+
+// blobStore is a local store whose Exists and Delete can be made to fail
+type blobStore struct {
+	*storage.LocalStore
+	missing   bool
+	deleteErr error
+}
+
+func (s *blobStore) Exists(ctx context.Context, key string) (bool, error) {
+	if s.missing {
+		return false, nil
+	}
+	return s.LocalStore.Exists(ctx, key)
+}
+
+func (s *blobStore) Delete(ctx context.Context, key string) error {
+	if s.deleteErr != nil {
+		return s.deleteErr
+	}
+	return s.LocalStore.Delete(ctx, key)
+}
+
+func newBlobStore(t *testing.T, content string) (*blobStore, string) {
+	store := &blobStore{LocalStore: storage.NewLocalStore(t.TempDir())}
+	key, _, err := store.Put(context.Background(), strings.NewReader(content), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store, key
+}
+
+// commands returns the collection and name of the commands sent, such as
+// "blobs.update"
+func commands(mt *mtest.T) []string {
+	var names []string
+	for _, event := range mt.GetAllStartedEvents() {
+		collection, _ := event.Command.Lookup(event.CommandName).StringValueOK()
+		names = append(names, collection+"."+event.CommandName)
+	}
+	return names
+}
+
+func updated(n int) bson.D {
+	return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n}, bson.E{Key: "nModified", Value: n})
+}
+
+func TestSaveDataset(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("references the blob before inserting", func(mt *mtest.T) {
+		store := &blobStore{LocalStore: storage.NewLocalStore(mt.TempDir())}
+		mt.AddMockResponses(updated(1), mtest.CreateSuccessResponse())
+
+		dataset := &models.Dataset{Name: "iris", Format: "csv", Retention: "7d"}
+		err := saveDataset(context.Background(), mt.DB.Collection("datasets"), mt.DB.Collection("blobs"), store, dataset, strings.NewReader("a,b\n1,2\n"), 1<<20)
+		if err != nil {
+			mt.Fatal(err)
+		}
+		if dataset.ID.IsZero() || dataset.Size != 8 || dataset.Profile == nil || dataset.ExpiresAt == nil {
+			mt.Errorf("dataset = %+v, want its ID, size, profile and expiry filled in", dataset)
+		}
+		if got, want := commands(mt), []string{"blobs.update", "datasets.insert"}; !reflect.DeepEqual(got, want) {
+			mt.Errorf("commands = %v, want %v", got, want)
+		}
+
+		update := mt.GetAllStartedEvents()[0].Command.Lookup("updates").Array().Index(0).Value().Document()
+		if key := update.Lookup("q", "_id").StringValue(); key != dataset.SHA256 {
+			mt.Errorf("referenced blob %s, want %s", key, dataset.SHA256)
+		}
+		if !update.Lookup("upsert").Boolean() {
+			mt.Error("the reference is not upserted")
+		}
+	})
+
+	mt.Run("conflicts while the content is being deleted", func(mt *mtest.T) {
+		store := &blobStore{LocalStore: storage.NewLocalStore(mt.TempDir())}
+		mt.AddMockResponses(mtest.CreateWriteErrorsResponse(mtest.WriteError{Code: 11000, Message: "E11000 duplicate key error"}))
+
+		err := saveDataset(context.Background(), mt.DB.Collection("datasets"), mt.DB.Collection("blobs"), store, &models.Dataset{}, strings.NewReader("a\n1\n"), 1<<20)
+		if apperror.As(err).Status() != http.StatusConflict {
+			mt.Fatalf("err = %v, want a conflict", err)
+		}
+		if got, want := commands(mt), []string{"blobs.update"}; !reflect.DeepEqual(got, want) {
+			mt.Errorf("commands = %v, want %v", got, want)
+		}
+	})
+
+	mt.Run("conflicts when the content was deleted after Put", func(mt *mtest.T) {
+		store := &blobStore{LocalStore: storage.NewLocalStore(mt.TempDir()), missing: true}
+		mt.AddMockResponses(updated(1), updated(1))
+
+		err := saveDataset(context.Background(), mt.DB.Collection("datasets"), mt.DB.Collection("blobs"), store, &models.Dataset{}, strings.NewReader("a\n1\n"), 1<<20)
+		if apperror.As(err).Status() != http.StatusConflict {
+			mt.Fatalf("err = %v, want a conflict", err)
+		}
+		// The reference is dropped again and no dataset is inserted
+		if got, want := commands(mt), []string{"blobs.update", "blobs.update"}; !reflect.DeepEqual(got, want) {
+			mt.Errorf("commands = %v, want %v", got, want)
+		}
+	})
+}
+
+func TestDeleteDataset(t *testing.T) {
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	deleted := func(n int) bson.D {
+		return mtest.CreateSuccessResponse(bson.E{Key: "n", Value: n})
+	}
+
+	tests := []struct {
+		name      string
+		responses []bson.D
+		deleteErr error
+		commands  []string
+		kept      bool
+		wantErr   bool
+	}{
+		{
+			name:      "deletes the content with its last reference",
+			responses: []bson.D{deleted(1), updated(1), updated(1), deleted(1)},
+			commands:  []string{"datasets.delete", "blobs.update", "blobs.update", "blobs.delete"},
+		},
+		{
+			name:      "keeps content other datasets reference",
+			responses: []bson.D{deleted(1), updated(1), updated(0)},
+			commands:  []string{"datasets.delete", "blobs.update", "blobs.update"},
+			kept:      true,
+		},
+		{
+			name:      "releases nothing for a dataset already deleted",
+			responses: []bson.D{deleted(0)},
+			commands:  []string{"datasets.delete"},
+			kept:      true,
+		},
+		{
+			name:      "unmarks the content when it cannot be deleted",
+			responses: []bson.D{deleted(1), updated(1), updated(1), updated(1)},
+			deleteErr: errors.New("disk failure"),
+			commands:  []string{"datasets.delete", "blobs.update", "blobs.update", "blobs.update"},
+			kept:      true,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		mt.Run(tt.name, func(mt *mtest.T) {
+			store, key := newBlobStore(t, "a,b\n1,2\n")
+			store.deleteErr = tt.deleteErr
+			mt.AddMockResponses(tt.responses...)
+
+			dataset := &models.Dataset{ID: primitive.NewObjectID(), SHA256: key}
+			err := deleteDataset(context.Background(), mt.DB.Collection("datasets"), mt.DB.Collection("blobs"), store, dataset)
+			if (err != nil) != tt.wantErr {
+				mt.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+			if got := commands(mt); !reflect.DeepEqual(got, tt.commands) {
+				mt.Errorf("commands = %v, want %v", got, tt.commands)
+			}
+			if ok, _ := store.LocalStore.Exists(context.Background(), key); ok != tt.kept {
+				mt.Errorf("content kept = %v, want %v", ok, tt.kept)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+// src/handlers/mongo.handler.go
+package handlers
+
+import (
+	"errors"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"builder.ai/src/apperror"
+)
+
+// fromMongo maps driver errors to domain errors: missing documents become
+// notFound, duplicate keys become conflicts and everything else is
+// internal. It lives with the handlers so that apperror, which clients
+// import, does not depend on the driver.
+func fromMongo(err error, notFound *apperror.Error) *apperror.Error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, mongo.ErrNoDocuments) && notFound != nil {
+		return notFound.Wrap(err)
+	}
+	if mongo.IsDuplicateKeyError(err) {
+		return apperror.Conflict("duplicate_key", "A resource with the same unique fields already exists").Wrap(err)
+	}
+	return apperror.Internal(err)
+}
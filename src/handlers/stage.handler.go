@@ -0,0 +1,243 @@
+// src/handlers/stage.handler.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/config"
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+type StageHandler struct {
+	collection *mongo.Collection
+	components *mongo.Collection
+}
+
+func NewStageHandler() *StageHandler {
+	return &StageHandler{
+		collection: config.GetCollection("stages"),
+		components: config.GetCollection("components"),
+	}
+}
+
+// List returns the stages in order
+func (h *StageHandler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := h.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.D{{Key: "order", Value: 1}}))
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	stages := []models.Stage{}
+	if err := cursor.All(ctx, &stages); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(stages),
+		"codegens": utils.StageCodegens,
+		"stages":   stages,
+	})
+}
+
+// Get returns a stage by name
+func (h *StageHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stage models.Stage
+	err := h.collection.FindOne(ctx, bson.M{"name": c.Param("stage")}).Decode(&stage)
+	if err != nil {
+		c.Error(fromMongo(err, stageNotFound()))
+		return
+	}
+	c.JSON(http.StatusOK, stage)
+}
+
+// Create adds a stage after the existing ones or between them by order;
+// names and orders are unique
+func (h *StageHandler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var stage models.Stage
+	if err := c.ShouldBindJSON(&stage); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+	if fieldErrs := utils.ValidateStage(&stage); len(fieldErrs) > 0 {
+		c.Error(apperror.Validation("Invalid stage", fieldErrs...))
+		return
+	}
+
+	stage.ID = primitive.NilObjectID
+	utils.DefaultStageCodegen(&stage)
+	stage.CreatedAt = time.Now()
+	stage.UpdatedAt = stage.CreatedAt
+
+	result, err := h.collection.InsertOne(ctx, stage)
+	if err != nil {
+		c.Error(stageConflict(err))
+		return
+	}
+	stage.ID = result.InsertedID.(primitive.ObjectID)
+	h.reload(ctx)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Stage created successfully",
+		"stage":   stage,
+	})
+}
+
+// Update replaces the description, roles and codegen of a stage. The
+// order cannot change: workflows are not stored, so the nodes referring to
+// the stage by order cannot be moved with it. Roles must keep allowing the
+// roles of the stage's components.
+func (h *StageHandler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var update models.StageUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+
+	var current models.Stage
+	err := h.collection.FindOne(ctx, bson.M{"name": c.Param("stage")}).Decode(&current)
+	if err != nil {
+		c.Error(fromMongo(err, stageNotFound()))
+		return
+	}
+	if update.Order != 0 && update.Order != current.Order {
+		c.Error(apperror.Validation("Invalid stage", apperror.Field("order", "immutable",
+			fmt.Sprintf("cannot change from %d, workflow nodes refer to the stage by order; create a stage instead", current.Order))))
+		return
+	}
+
+	stage := models.Stage{StageDefinition: utils.Stage{
+		Name:        current.Name,
+		Order:       current.Order,
+		Description: update.Description,
+		Roles:       update.Roles,
+		Codegen:     update.Codegen,
+	}}
+	if fieldErrs := utils.ValidateStage(&stage); len(fieldErrs) > 0 {
+		c.Error(apperror.Validation("Invalid stage", fieldErrs...))
+		return
+	}
+	if len(stage.Roles) > 0 {
+		outside, err := h.components.CountDocuments(ctx, bson.M{"stage": stage.Name, "role": bson.M{"$nin": stage.Roles}})
+		if err != nil {
+			c.Error(apperror.Internal(err))
+			return
+		}
+		if outside > 0 {
+			c.Error(apperror.Conflict("stage_roles_in_use", fmt.Sprintf(
+				"%d component(s) of the stage have a role other than %s, change their role first",
+				outside, strings.Join(stage.Roles, ", "))))
+			return
+		}
+	}
+	utils.DefaultStageCodegen(&stage)
+
+	err = h.collection.FindOneAndUpdate(ctx, bson.M{"name": stage.Name}, bson.M{"$set": bson.M{
+		"description": stage.Description,
+		"roles":       stage.Roles,
+		"codegen":     stage.Codegen,
+		"updated_at":  time.Now(),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&stage)
+	if err != nil {
+		c.Error(stageConflict(err))
+		return
+	}
+	h.reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stage updated successfully",
+		"stage":   stage,
+	})
+}
+
+// Delete removes a stage no component belongs to; the last stage cannot
+// be deleted
+func (h *StageHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	name := c.Param("stage")
+	used, err := h.components.CountDocuments(ctx, bson.M{"stage": name})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if used > 0 {
+		c.Error(apperror.Conflict("stage_in_use", fmt.Sprintf("Stage is used by %d component(s), move them to another stage first", used)))
+		return
+	}
+
+	stages, err := h.collection.CountDocuments(ctx, bson.M{})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if stages == 1 {
+		if err := h.collection.FindOne(ctx, bson.M{"name": name}).Err(); err == nil {
+			c.Error(apperror.Conflict("last_stage", "The last stage cannot be deleted"))
+			return
+		}
+	}
+
+	result, err := h.collection.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.Error(stageNotFound())
+		return
+	}
+	h.reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Stage deleted successfully",
+		"name":    name,
+	})
+}
+
+// reload registers the stored stages; a failure keeps the previous ones
+func (h *StageHandler) reload(ctx context.Context) {
+	if err := config.LoadStages(ctx); err != nil {
+		slog.Error("failed to reload stages", "error", err)
+	}
+}
+
+func stageNotFound() *apperror.Error {
+	return apperror.NotFound("stage_not_found", "Stage not found")
+}
+
+// stageConflict reports a missing stage or one whose name or order is taken
+func stageConflict(err error) *apperror.Error {
+	if mongo.IsDuplicateKeyError(err) {
+		return apperror.Conflict("stage_conflict", "A stage with the same name or order already exists").Wrap(err)
+	}
+	return fromMongo(err, stageNotFound())
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"builder.ai/config"
+	"builder.ai/src/apperror"
+	"builder.ai/src/utils"
+)
+
+func TestUpdateStage(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	stored := bson.D{{Key: "name", Value: "clean"}, {Key: "order", Value: 2}, {Key: "roles", Value: bson.A{utils.RoleTransform}}}
+	update := func(mt *mtest.T, body string) *apperror.Error {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPut, "/stages/clean", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "stage", Value: "clean"}}
+
+		(&StageHandler{collection: mt.DB.Collection("stages"), components: mt.DB.Collection("components")}).Update(c)
+
+		if len(c.Errors) == 0 {
+			return nil
+		}
+		return apperror.As(c.Errors.Last().Err)
+	}
+
+	mt.Run("keeps the order", func(mt *mtest.T) {
+		mt.AddMockResponses(mtest.CreateCursorResponse(0, mt.DB.Name()+".stages", mtest.FirstBatch, stored))
+
+		err := update(mt, `{"order": 1, "roles": ["transform"]}`)
+		if err == nil || err.Kind != apperror.KindValidation || err.Fields[0].Field != "order" {
+			mt.Fatalf("err = %+v, want a validation error on order", err)
+		}
+		if got := commands(mt); len(got) != 1 {
+			mt.Errorf("commands = %v, want only the find", got)
+		}
+	})
+
+	mt.Run("rejects roles its components do not have", func(mt *mtest.T) {
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".stages", mtest.FirstBatch, stored),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".components", mtest.FirstBatch, bson.D{{Key: "n", Value: 3}}),
+		)
+
+		err := update(mt, `{"order": 2, "roles": ["trainer"]}`)
+		if err == nil || err.Status() != http.StatusConflict || err.Code != "stage_roles_in_use" {
+			mt.Fatalf("err = %+v, want a stage_roles_in_use conflict", err)
+		}
+		count := mt.GetAllStartedEvents()[1].Command.Lookup("pipeline").Array().Index(0).Value().Document()
+		if got := count.Lookup("$match", "role", "$nin").Array().Index(0).Value().StringValue(); got != utils.RoleTrainer {
+			mt.Errorf("count filter = %s, want roles outside [trainer]", count)
+		}
+	})
+
+	mt.Run("updates without moving the stage", func(mt *mtest.T) {
+		// The update reloads the stages from config.DB
+		db := config.DB
+		config.DB = mt.DB
+		defer func() {
+			config.DB = db
+			utils.SetStages(utils.DefaultStages())
+		}()
+		mt.AddMockResponses(
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".stages", mtest.FirstBatch, stored),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".components", mtest.FirstBatch, bson.D{{Key: "n", Value: 0}}),
+			mtest.CreateSuccessResponse(bson.E{Key: "value", Value: stored}),
+			mtest.CreateCursorResponse(0, mt.DB.Name()+".stages", mtest.FirstBatch, stored),
+		)
+
+		if err := update(mt, `{"description": "Cleaning", "roles": ["transform"]}`); err != nil {
+			mt.Fatalf("err = %+v", err)
+		}
+		set := mt.GetAllStartedEvents()[2].Command.Lookup("update", "$set").Document()
+		if _, err := set.LookupErr("order"); err == nil {
+			mt.Errorf("$set = %s, want the order left as is", set)
+		}
+	})
+}
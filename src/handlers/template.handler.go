@@ -0,0 +1,308 @@
+// src/handlers/template.handler.go
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/config"
+	"builder.ai/src/apperror"
+	"builder.ai/src/middleware"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+type TemplateHandler struct {
+	collection *mongo.Collection
+}
+
+func NewTemplateHandler() *TemplateHandler {
+	return &TemplateHandler{
+		collection: config.GetCollection("templates"),
+	}
+}
+
+// List returns the built-in template followed by the workspace's templates
+func (h *TemplateHandler) List(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetProjection(bson.M{"content": 0})
+	cursor, err := h.collection.Find(ctx, bson.M{"workspace": workspace}, opts)
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	defer cursor.Close(ctx)
+
+	var stored []models.ScriptTemplate
+	if err := cursor.All(ctx, &stored); err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	templates := append([]models.ScriptTemplate{builtinTemplate(workspace, false)}, stored...)
+	c.JSON(http.StatusOK, gin.H{
+		"count":     len(templates),
+		"blocks":    utils.ScriptBlocks,
+		"templates": templates,
+	})
+}
+
+// Get returns a template with its source; the built-in template's source
+// is the starting point for custom templates
+func (h *TemplateHandler) Get(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := c.Param("name")
+	if name == utils.DefaultTemplateName {
+		c.JSON(http.StatusOK, builtinTemplate(workspace, true))
+		return
+	}
+
+	var stored models.ScriptTemplate
+	err = h.collection.FindOne(ctx, bson.M{"workspace": workspace, "name": name}).Decode(&stored)
+	if err != nil {
+		c.Error(fromMongo(err, templateNotFound()))
+		return
+	}
+	c.JSON(http.StatusOK, stored)
+}
+
+// Upload creates or replaces a workspace template after checking that it
+// parses and renders
+func (h *TemplateHandler) Upload(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := c.Param("name")
+	if !models.IsValidTemplateName(name) || name == utils.DefaultTemplateName {
+		c.Error(apperror.Validation("Invalid template name",
+			apperror.Field("name", "template_name", "must be 1-64 lower-case letters, digits, '-' or '_', and not \""+utils.DefaultTemplateName+"\""),
+		))
+		return
+	}
+
+	var upload models.TemplateUpload
+	if err := c.ShouldBindJSON(&upload); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+
+	parsed, err := utils.ParseScriptTemplate(name, upload.Content)
+	if err != nil {
+		c.Error(invalidTemplate("content", err))
+		return
+	}
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"description": upload.Description,
+			"content":     upload.Content,
+			"blocks":      parsed.Blocks,
+			"updated_at":  now,
+		},
+		"$setOnInsert": bson.M{
+			"workspace":  workspace,
+			"name":       name,
+			"created_at": now,
+		},
+	}
+
+	var stored models.ScriptTemplate
+	err = h.collection.FindOneAndUpdate(ctx, bson.M{"workspace": workspace, "name": name}, update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&stored)
+	if err != nil {
+		c.Error(fromMongo(err, nil))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message":  "Template saved successfully",
+		"template": stored,
+	})
+}
+
+// Delete removes a workspace template
+func (h *TemplateHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	name := c.Param("name")
+	result, err := h.collection.DeleteOne(ctx, bson.M{"workspace": workspace, "name": name})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+
+	if result.DeletedCount == 0 {
+		c.Error(templateNotFound())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Template deleted successfully",
+		"name":    name,
+	})
+}
+
+// Preview renders a stored or unsaved template against the request's
+// workflow, or a sample workflow, without storing anything
+func (h *TemplateHandler) Preview(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	workspace, err := workspaceOf(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	var request models.TemplatePreviewRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+
+	opts, err := generateOptions(c)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	if request.Content != "" {
+		opts.Template, err = utils.ParseScriptTemplate("preview", request.Content)
+		if err != nil {
+			c.Error(invalidTemplate("content", err))
+			return
+		}
+	} else {
+		opts.Template, err = loadScriptTemplate(ctx, h.collection, workspace, request.Template)
+		if err != nil {
+			c.Error(err)
+			return
+		}
+	}
+
+	workflow := utils.SampleWorkflow()
+	if request.WorkflowConfig != "" {
+		if err := json.Unmarshal([]byte(request.WorkflowConfig), &workflow); err != nil {
+			c.Error(apperror.Validation("Invalid workflow config",
+				apperror.Field("workflow_config", "json", "must be a valid workflow config JSON document"),
+			).Wrap(err))
+			return
+		}
+	}
+
+	script, err := utils.GenerateExecutableScript(workflow, request.ComponentCode, opts)
+	if err != nil {
+		c.Error(generationError(err, func(nodeID string) string {
+			return "workflow_config.nodes[" + nodeID + "]"
+		}, "component_code"))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"template": opts.Template.Name,
+		"blocks":   opts.Template.Blocks,
+		"script":   script,
+	})
+}
+
+// loadScriptTemplate parses a workspace template by name; an empty name or
+// the default name selects the built-in template
+func loadScriptTemplate(ctx context.Context, collection *mongo.Collection, workspace, name string) (*utils.ScriptTemplate, error) {
+	if name == "" || name == utils.DefaultTemplateName {
+		return utils.DefaultScriptTemplate(), nil
+	}
+
+	var stored models.ScriptTemplate
+	err := collection.FindOne(ctx, bson.M{"workspace": workspace, "name": name}).Decode(&stored)
+	if err != nil {
+		return nil, fromMongo(err, templateNotFound())
+	}
+
+	parsed, err := utils.ParseScriptTemplate(stored.Name, stored.Content)
+	if err != nil {
+		// Stored templates were checked on upload, so the built-in
+		// template they build on has changed since
+		return nil, invalidTemplate("template", err)
+	}
+	return parsed, nil
+}
+
+// workspaceOf returns the caller's workspace or a validation error
+func workspaceOf(c *gin.Context) (string, error) {
+	workspace, ok := middleware.GetWorkspace(c)
+	if !ok {
+		return "", apperror.Validation("Invalid workspace",
+			apperror.Field(middleware.WorkspaceHeader, "workspace", "must be at most 128 letters, digits, '-', '_' or '.'"),
+		)
+	}
+	return workspace, nil
+}
+
+// builtinTemplate describes the built-in template, with its source when
+// withContent is set
+func builtinTemplate(workspace string, withContent bool) models.ScriptTemplate {
+	t := models.ScriptTemplate{
+		Workspace:   workspace,
+		Name:        utils.DefaultTemplateName,
+		Description: "Built-in pipeline script template",
+		Builtin:     true,
+	}
+	if withContent {
+		t.Content = utils.DefaultTemplateSource()
+	}
+	return t
+}
+
+func templateNotFound() *apperror.Error {
+	return apperror.NotFound("template_not_found", "Template not found")
+}
+
+// invalidTemplate reports a template that does not parse or render
+func invalidTemplate(field string, err error) error {
+	var templateErr *utils.TemplateError
+	if errors.As(err, &templateErr) {
+		err = templateErr.Err
+	}
+	return apperror.Validation("Invalid template",
+		apperror.Field(field, "template", err.Error()),
+	).Wrap(err)
+}
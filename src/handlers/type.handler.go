@@ -0,0 +1,277 @@
+// src/handlers/type.handler.go
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/config"
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+	"builder.ai/src/utils"
+)
+
+type TypeHandler struct {
+	collection *mongo.Collection
+	components *mongo.Collection
+}
+
+func NewTypeHandler() *TypeHandler {
+	return &TypeHandler{
+		collection: config.GetCollection("types"),
+		components: config.GetCollection("components"),
+	}
+}
+
+// List returns the built-in types followed by the custom types
+func (h *TypeHandler) List(c *gin.Context) {
+	types := utils.Types()
+	c.JSON(http.StatusOK, gin.H{
+		"count":    len(types),
+		"literals": utils.LiteralKinds,
+		"types":    types,
+	})
+}
+
+// Get returns a type by name or alias
+func (h *TypeHandler) Get(c *gin.Context) {
+	t, ok := utils.GetType(c.Param("name"))
+	if !ok {
+		c.Error(typeNotFound())
+		return
+	}
+	c.JSON(http.StatusOK, t)
+}
+
+// Create adds a custom type; its name and aliases must not be taken by
+// another type and its parent must exist
+func (h *TypeHandler) Create(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var t models.DataType
+	if err := c.ShouldBindJSON(&t); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+	if fieldErrs := utils.ValidateDataType(&t); len(fieldErrs) > 0 {
+		c.Error(apperror.Validation("Invalid type", fieldErrs...))
+		return
+	}
+	t.ID = primitive.NilObjectID
+	t.Custom = true
+	utils.DefaultPythonHint(&t)
+
+	stored, err := h.storedTypes(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	if err := utils.CheckCustomTypes(append(stored, t.DataTypeDefinition)); err != nil {
+		c.Error(invalidType(err))
+		return
+	}
+
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+	result, err := h.collection.InsertOne(ctx, t)
+	if err != nil {
+		c.Error(fromMongo(err, nil))
+		return
+	}
+	t.ID = result.InsertedID.(primitive.ObjectID)
+	h.reload(ctx)
+
+	c.JSON(http.StatusCreated, gin.H{
+		"message": "Type created successfully",
+		"type":    t,
+	})
+}
+
+// Update replaces the definition of a custom type
+func (h *TypeHandler) Update(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	name := c.Param("name")
+	if isBuiltinType(name) {
+		c.Error(builtinType())
+		return
+	}
+
+	var update models.DataTypeUpdate
+	if err := c.ShouldBindJSON(&update); err != nil {
+		c.Error(fromBinding(err))
+		return
+	}
+	t := models.DataType{DataTypeDefinition: utils.DataType{
+		Name:        name,
+		Aliases:     update.Aliases,
+		Parent:      update.Parent,
+		PythonHint:  update.PythonHint,
+		Literal:     update.Literal,
+		Description: update.Description,
+		OutputOnly:  update.OutputOnly,
+		Custom:      true,
+	}}
+	if fieldErrs := utils.ValidateDataType(&t); len(fieldErrs) > 0 {
+		c.Error(apperror.Validation("Invalid type", fieldErrs...))
+		return
+	}
+	utils.DefaultPythonHint(&t)
+
+	stored, err := h.storedTypes(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	found := false
+	for i := range stored {
+		if stored[i].Name == name {
+			stored[i], found = t.DataTypeDefinition, true
+		}
+	}
+	if !found {
+		c.Error(typeNotFound())
+		return
+	}
+	if err := utils.CheckCustomTypes(stored); err != nil {
+		c.Error(invalidType(err))
+		return
+	}
+
+	err = h.collection.FindOneAndUpdate(ctx, bson.M{"name": name}, bson.M{"$set": bson.M{
+		"aliases":     t.Aliases,
+		"parent":      t.Parent,
+		"python_hint": t.PythonHint,
+		"literal":     t.Literal,
+		"description": t.Description,
+		"output_only": t.OutputOnly,
+		"updated_at":  time.Now(),
+	}}, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&t)
+	if err != nil {
+		c.Error(fromMongo(err, typeNotFound()))
+		return
+	}
+	t.Custom = true
+	h.reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Type updated successfully",
+		"type":    t,
+	})
+}
+
+// Delete removes a custom type that no component or other type uses
+func (h *TypeHandler) Delete(c *gin.Context) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	name := c.Param("name")
+	if isBuiltinType(name) {
+		c.Error(builtinType())
+		return
+	}
+
+	used, err := h.components.CountDocuments(ctx, bson.M{"$or": bson.A{
+		bson.M{"inputs.type": name},
+		bson.M{"output.type": name},
+	}})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if used > 0 {
+		c.Error(apperror.Conflict("type_in_use", fmt.Sprintf("Type is used by %d component(s)", used)))
+		return
+	}
+
+	stored, err := h.storedTypes(ctx)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	var children []string
+	for _, t := range stored {
+		if t.Parent == name {
+			children = append(children, t.Name)
+		}
+	}
+	if len(children) > 0 {
+		c.Error(apperror.Conflict("type_in_use", "Type is the parent of "+strings.Join(children, ", ")))
+		return
+	}
+
+	result, err := h.collection.DeleteOne(ctx, bson.M{"name": name})
+	if err != nil {
+		c.Error(apperror.Internal(err))
+		return
+	}
+	if result.DeletedCount == 0 {
+		c.Error(typeNotFound())
+		return
+	}
+	h.reload(ctx)
+
+	c.JSON(http.StatusOK, gin.H{
+		"message": "Type deleted successfully",
+		"name":    name,
+	})
+}
+
+// storedTypes returns the definitions of the custom types
+func (h *TypeHandler) storedTypes(ctx context.Context) ([]utils.DataType, error) {
+	cursor, err := h.collection.Find(ctx, bson.M{})
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+	defer cursor.Close(ctx)
+
+	var stored []models.DataType
+	if err := cursor.All(ctx, &stored); err != nil {
+		return nil, apperror.Internal(err)
+	}
+	types := make([]utils.DataType, len(stored))
+	for i, t := range stored {
+		types[i] = t.DataTypeDefinition
+	}
+	return types, nil
+}
+
+// reload registers the stored types; a failure keeps the previous ones
+func (h *TypeHandler) reload(ctx context.Context) {
+	if err := config.LoadTypes(ctx); err != nil {
+		slog.Error("failed to reload types", "error", err)
+	}
+}
+
+// isBuiltinType reports whether name is a name or alias of a built-in type
+func isBuiltinType(name string) bool {
+	t, ok := utils.GetType(name)
+	return ok && !t.Custom
+}
+
+func typeNotFound() *apperror.Error {
+	return apperror.NotFound("type_not_found", "Type not found")
+}
+
+func builtinType() *apperror.Error {
+	return apperror.Conflict("builtin_type", "Built-in types cannot be changed or deleted")
+}
+
+// invalidType reports a custom type that clashes with the other types
+func invalidType(err error) *apperror.Error {
+	return apperror.Validation("Invalid type",
+		apperror.Field("type", "type_registry", err.Error()),
+	).Wrap(err)
+}
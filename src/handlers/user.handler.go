@@ -11,6 +11,7 @@ import (
     "go.mongodb.org/mongo-driver/mongo"
 
     "builder.ai/config"
+    "builder.ai/src/apperror"
     "builder.ai/src/models"
 )
 
@@ -33,13 +34,13 @@ func (h *UserHandler) GetAll(c *gin.Context) {
 
     cursor, err := h.collection.Find(ctx, bson.M{})
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &users); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -57,18 +58,14 @@ func (h *UserHandler) GetByID(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
         return
     }
 
     var user models.User
     err = h.collection.FindOne(ctx, bson.M{"_id": objectID}).Decode(&user)
     if err != nil {
-        if err == mongo.ErrNoDocuments {
-            c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
-            return
-        }
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(fromMongo(err, apperror.NotFound("user_not_found", "User not found")))
         return
     }
 
@@ -82,7 +79,7 @@ func (h *UserHandler) Create(c *gin.Context) {
 
     var user models.User
     if err := c.ShouldBindJSON(&user); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(fromBinding(err))
         return
     }
 
@@ -92,7 +89,7 @@ func (h *UserHandler) Create(c *gin.Context) {
 
     result, err := h.collection.InsertOne(ctx, user)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(fromMongo(err, nil))
         return
     }
 
@@ -112,13 +109,13 @@ func (h *UserHandler) Update(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
         return
     }
 
     var user models.User
     if err := c.ShouldBindJSON(&user); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(fromBinding(err))
         return
     }
 
@@ -136,12 +133,12 @@ func (h *UserHandler) Update(c *gin.Context) {
 
     result, err := h.collection.UpdateOne(ctx, bson.M{"_id": objectID}, update)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(fromMongo(err, nil))
         return
     }
 
     if result.MatchedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        c.Error(apperror.NotFound("user_not_found", "User not found"))
         return
     }
 
@@ -159,18 +156,18 @@ func (h *UserHandler) Delete(c *gin.Context) {
     id := c.Param("id")
     objectID, err := primitive.ObjectIDFromHex(id)
     if err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+        c.Error(apperror.InvalidID())
         return
     }
 
     result, err := h.collection.DeleteOne(ctx, bson.M{"_id": objectID})
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
     if result.DeletedCount == 0 {
-        c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+        c.Error(apperror.NotFound("user_not_found", "User not found"))
         return
     }
 
@@ -187,7 +184,7 @@ func (h *UserHandler) SearchByName(c *gin.Context) {
 
     name := c.Query("name")
     if name == "" {
-        c.JSON(http.StatusBadRequest, gin.H{"error": "Name query parameter is required"})
+        c.Error(apperror.Validation("Missing query parameter", apperror.Field("name", "required", "is required")))
         return
     }
 
@@ -197,13 +194,13 @@ func (h *UserHandler) SearchByName(c *gin.Context) {
     filter := bson.M{"name": bson.M{"$regex": name, "$options": "i"}}
     cursor, err := h.collection.Find(ctx, filter)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
     defer cursor.Close(ctx)
 
     if err = cursor.All(ctx, &users); err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+        c.Error(apperror.Internal(err))
         return
     }
 
@@ -3,81 +3,63 @@ package handlers
 
 import (
     "bytes"
+    "context"
     "encoding/json"
+    "errors"
     "fmt"
+    "log/slog"
+    "math"
     "net/http"
     "os"
     "os/exec"
     "path/filepath"
+    "strconv"
     "strings"
     "time"
 
     "github.com/gin-gonic/gin"
+    "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
 
     "builder.ai/config"
+    "builder.ai/src/apperror"
+    "builder.ai/src/middleware"
+    "builder.ai/src/models"
+    "builder.ai/src/storage"
     "builder.ai/src/utils"
 )
 
 type WorkflowHandler struct {
     collection *mongo.Collection
+    templates  *mongo.Collection
+    datasets   *mongo.Collection
+    blobs      *mongo.Collection
+    store      storage.BlobStore
+    settings   config.DatasetSettings
 }
 
 func NewWorkflowHandler() *WorkflowHandler {
     return &WorkflowHandler{
         collection: config.GetCollection("components"),
+        templates:  config.GetCollection("templates"),
+        datasets:   config.GetCollection("datasets"),
+        blobs:      config.GetCollection("blobs"),
+        store:      config.BlobStore(),
+        settings:   config.Datasets(),
     }
 }
 
-// Variable represents a single variable with name and value
-type Variable struct {
-    Name  string `json:"name"`
-    Value string `json:"value"`
-}
-
-// CodeItem represents a code block with its variables
-type CodeItem struct {
-    Code      string     `json:"code" binding:"required"`
-    Variables []Variable `json:"variables"`
-}
-
-// Use WorkflowConfig from utils package
-// type WorkflowConfig = utils.WorkflowConfig (alias, not needed with direct import)
-
 // RunCode handles the workflow execution and code concatenation
 func (h *WorkflowHandler) RunCode(c *gin.Context) {
-    var request struct {
-        Items []CodeItem `json:"items" binding:"required,min=1"`
-        Data  struct {
-            Schema string `json:"schema"` // raw CSV string
-        } `json:"data"`
-    }
+    var request models.RunCodeRequest
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(fromBinding(err))
         return
     }
 
-    projectDir, err := os.Getwd()
-    if err != nil {
-        fmt.Println("Error getting project directory:", err)
-        projectDir = "."
-    }
-
-    // Save CSV if provided
-    csvFile := filepath.Join(projectDir, fmt.Sprintf("temp/workflow_%d.csv", time.Now().Unix()))
-    if request.Data.Schema != "" {
-        err := os.MkdirAll(filepath.Dir(csvFile), 0755)
-        if err != nil {
-            fmt.Println("Error creating temp directory:", err)
-        }
-        err = os.WriteFile(csvFile, []byte(request.Data.Schema), 0644)
-        if err != nil {
-            fmt.Println("Error saving CSV:", err)
-        } else {
-            fmt.Println("CSV saved at:", csvFile)
-        }
-    }
+    ctx := c.Request.Context()
 
     // Process code items
     var codeBlocks []string
@@ -85,15 +67,13 @@ func (h *WorkflowHandler) RunCode(c *gin.Context) {
     
     for i, item := range request.Items {
         if item.Code == "" {
-            c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Empty code at index %d", i)})
+            c.Error(apperror.Validation("Invalid workflow items",
+                apperror.Field(fmt.Sprintf("items[%d].code", i), "required", "is required"),
+            ))
             return
         }
 
-        processedCode := item.Code
-        for _, variable := range item.Variables {
-            placeholder := fmt.Sprintf("{{%s}}", variable.Name)
-            processedCode = strings.ReplaceAll(processedCode, placeholder, variable.Value)
-        }
+        processedCode := substitutePlaceholders(item.Code, item.Variables)
 
         codeBlocks = append(codeBlocks, processedCode)
         componentDetails = append(componentDetails, map[string]interface{}{
@@ -106,90 +86,351 @@ func (h *WorkflowHandler) RunCode(c *gin.Context) {
 
     concatenatedCode := strings.Join(codeBlocks, "\n\n")
 
+    // Components in several languages only run with a bridge between them
+    workflow, _ := itemsWorkflow(request, time.Now())
+    if _, err := utils.WorkflowLanguage(workflow); err != nil {
+        c.Error(generationError(err, itemField, "items"))
+        return
+    }
+
+    // Keep inline data as a temporary dataset, or check the referenced one,
+    // once the items are known to be valid
+    dataset, err := h.workflowDataset(c, request)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+    var datasetID, csvFile, dataFormat string
+    var profile *utils.DatasetProfile
+    if dataset != nil {
+        datasetID = dataset.ID.Hex()
+        dataFormat = datasetFormatName(dataset.Format)
+        profile = dataset.Profile
+        if local, ok := h.store.(*storage.LocalStore); ok {
+            csvFile, _ = local.Path(dataset.SHA256)
+        }
+        slog.InfoContext(ctx, "workflow dataset", "dataset", datasetID, "bytes", dataset.Size, "temporary", dataset.Temporary)
+    }
+
     c.JSON(http.StatusOK, gin.H{
         "message":           "Code concatenated successfully",
         "total_items":       len(request.Items),
         "concatenated_code": concatenatedCode,
         "components":        componentDetails,
+        "dataset_id":        datasetID,
         "csv_file":          csvFile,
+        "data_format":       dataFormat,
+        "profile":           profile,
     })
 }
 
 // GenerateExecutableScript generates a complete runnable Python script
 func (h *WorkflowHandler) GenerateExecutableScript(c *gin.Context) {
-    var request struct {
-        WorkflowConfig string `json:"workflow_config" binding:"required"`
-        ComponentCode  string `json:"component_code" binding:"required"`
+    var request models.GenerateScriptRequest
+
+    ctx := c.Request.Context()
+
+    opts, err := generateOptions(c)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+    if opts.Template, err = h.scriptTemplate(c); err != nil {
+        c.Error(err)
+        return
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        fmt.Printf("Error binding JSON: %v\n", err)
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": fmt.Sprintf("Invalid request: %v", err),
-            "details": "workflow_config and component_code are required fields",
-        })
+        slog.WarnContext(ctx, "invalid generate-script request", "error", err)
+        c.Error(fromBinding(err))
         return
     }
 
-    fmt.Printf("Received workflow_config length: %d\n", len(request.WorkflowConfig))
-    fmt.Printf("Received component_code length: %d\n", len(request.ComponentCode))
+    slog.DebugContext(ctx, "received generate-script request",
+        "workflow_config_bytes", len(request.WorkflowConfig),
+        "component_code_bytes", len(request.ComponentCode))
 
     // Parse workflow config
     var workflow utils.WorkflowConfig
-    err := json.Unmarshal([]byte(request.WorkflowConfig), &workflow)
+    err = json.Unmarshal([]byte(request.WorkflowConfig), &workflow)
     if err != nil {
-        fmt.Printf("Error parsing workflow config: %v\n", err)
-        c.JSON(http.StatusBadRequest, gin.H{
-            "error": "Invalid workflow config JSON",
-            "details": err.Error(),
-        })
+        slog.WarnContext(ctx, "failed to parse workflow config", "error", err)
+        c.Error(apperror.Validation("Invalid workflow config",
+            apperror.Field("workflow_config", "json", "must be a valid workflow config JSON document"),
+        ).Wrap(err))
         return
     }
 
-    fmt.Printf("Parsed workflow: version=%s, nodes=%d\n", workflow.Version, len(workflow.Nodes))
+    slog.DebugContext(ctx, "parsed workflow config", "version", workflow.Version, "nodes", len(workflow.Nodes))
+
+    if !utils.IsValidTaskType(workflow.TaskType) {
+        c.Error(apperror.Validation("Invalid workflow config",
+            apperror.Field("workflow_config.task_type", "oneof", "must be one of: "+strings.Join(utils.TaskTypes, ", ")),
+        ))
+        return
+    }
+    if workflow.Bridge != "" && !utils.IsValidBridge(workflow.Bridge) {
+        c.Error(apperror.Validation("Invalid workflow config",
+            apperror.Field("workflow_config.bridge", "oneof", "must be one of: "+strings.Join(utils.Bridges, ", ")),
+        ))
+        return
+    }
+
+    nodeField := func(nodeID string) string {
+        return fmt.Sprintf("workflow_config.nodes[%s]", nodeID)
+    }
+
+    // Nodes take the role and dependencies of the stored component
+    if err := h.resolveComponents(&workflow); err != nil {
+        c.Error(err)
+        return
+    }
+
+    // Check the workflow's columns against the dataset it was exported
+    // with, unless the dataset has expired since
+    profile, format, err := h.datasetProfile(c, workflow.DatasetID, models.WorkflowData{})
+    if err != nil && apperror.As(err).Kind != apperror.KindNotFound {
+        c.Error(err)
+        return
+    }
+    if workflow.DataFormat == "" {
+        workflow.DataFormat = format
+    }
+    if err := checkColumns(workflow, profile, nodeField, "workflow_config.target_column"); err != nil {
+        c.Error(err)
+        return
+    }
+
+    // The script runs the components of a single language
+    name, err := utils.WorkflowLanguage(workflow)
+    if err != nil {
+        c.Error(generationError(err, nodeField, "component_code"))
+        return
+    }
+    if name == "" {
+        c.Error(apperror.Validation("Invalid workflow config",
+            apperror.Field("workflow_config.bridge", "bundle", "workflows with components in several languages are exported as a bundle"),
+        ))
+        return
+    }
+    if err := checkDataLanguages(workflow, "workflow_config.data_format"); err != nil {
+        c.Error(err)
+        return
+    }
+    language, _ := utils.GetLanguage(name)
 
     // Generate executable script
-    script, err := utils.GenerateExecutableScript(workflow, request.ComponentCode)
+    script, err := language.Generate(workflow, request.ComponentCode, opts)
     if err != nil {
-        fmt.Printf("Error generating script: %v\n", err)
-        c.JSON(http.StatusInternalServerError, gin.H{
-            "error": "Failed to generate script",
-            "details": err.Error(),
-        })
+        c.Error(generationError(err, nodeField, "component_code"))
         return
     }
 
-    fmt.Printf("Successfully generated script, length: %d\n", len(script))
+    slog.InfoContext(ctx, "generated script", "language", name, "nodes", len(workflow.Nodes), "script_bytes", len(script))
 
     c.JSON(http.StatusOK, gin.H{
-        "script":  script,
-        "message": "Executable script generated successfully",
+        "script":       string(script),
+        "language":     name,
+        "requirements": language.Requirements(workflow, request.ComponentCode),
+        "message":      "Executable script generated successfully",
     })
 }
 
-// GenerateAndDownloadScript generates script from workflow items
+// GenerateAndDownloadScript generates script from workflow items. The
+// default format returns the script as JSON; any other ?format= streams the
+// exported artifact as a file download. ?bundle=zip streams a zip archive
+// with the artifact and everything needed to run it offline.
 func (h *WorkflowHandler) GenerateAndDownloadScript(c *gin.Context) {
-    var request struct {
-        Items []CodeItem `json:"items" binding:"required,min=1"`
-        Data  struct {
-            Schema string `json:"schema"`
-        } `json:"data"`
+    var request models.RunCodeRequest
+
+    format := c.DefaultQuery("format", utils.DefaultExportFormat)
+    exporter, ok := utils.GetExporter(format)
+    if !ok {
+        c.Error(apperror.Validation("Unsupported export format",
+            apperror.Field("format", "oneof", "must be one of: "+strings.Join(utils.ExportFormats(), ", ")),
+        ))
+        return
+    }
+
+    bundle := c.Query("bundle")
+    if bundle != "" && bundle != utils.BundleFormat {
+        c.Error(apperror.Validation("Unsupported bundle format",
+            apperror.Field("bundle", "oneof", "must be one of: "+utils.BundleFormat),
+        ))
+        return
+    }
+
+    opts, err := generateOptions(c)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+    if opts.Template, err = h.scriptTemplate(c); err != nil {
+        c.Error(err)
+        return
     }
 
     if err := c.ShouldBindJSON(&request); err != nil {
-        c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+        c.Error(fromBinding(err))
+        return
+    }
+    if err := checkDataSource(request); err != nil {
+        c.Error(err)
+        return
+    }
+
+    // A fixed timestamp also pins the export time so bundles are byte-stable
+    exportedAt := time.Now()
+    if !opts.Timestamp.IsZero() {
+        exportedAt = opts.Timestamp.UTC()
+    }
+
+    workflowConfig, code := itemsWorkflow(request, exportedAt)
+    if err := h.resolveComponents(&workflowConfig); err != nil {
+        c.Error(err)
+        return
+    }
+
+    // Workflows in one language export in its format; mixed ones as a
+    // bundle with a program per language
+    language, err := utils.WorkflowLanguage(workflowConfig)
+    if err != nil {
+        c.Error(generationError(err, itemField, "items"))
+        return
+    }
+    if language == "" && bundle == "" {
+        c.Error(apperror.Validation("Invalid workflow",
+            apperror.Field("bundle", "required", "is required to export components in several languages"),
+        ))
+        return
+    }
+    if language != "" {
+        if exporter, ok = utils.GetExporterFor(format, language); !ok {
+            c.Error(apperror.Validation("Unsupported export format",
+                apperror.Field("format", "oneof", fmt.Sprintf("must be %s for %s components", utils.DefaultExportFormat, language)),
+            ))
+            return
+        }
+    } else if format != utils.DefaultExportFormat {
+        c.Error(apperror.Validation("Unsupported export format",
+            apperror.Field("format", "oneof", fmt.Sprintf("must be %s for components in several languages", utils.DefaultExportFormat)),
+        ))
         return
     }
+    concatenatedCode := code[language]
 
-    // Build workflow config from items
+    profile, format, err := h.datasetProfile(c, request.DatasetID, request.Data)
+    if err != nil {
+        c.Error(err)
+        return
+    }
+    workflowConfig.DataFormat = format
+    if err := checkColumns(workflowConfig, profile, itemField, "target_column"); err != nil {
+        c.Error(err)
+        return
+    }
+    if err := checkDataLanguages(workflowConfig, "dataset_id"); err != nil {
+        c.Error(err)
+        return
+    }
+
+    if bundle != "" {
+        sampleData := request.Data.Schema
+        if request.DatasetID != "" {
+            workspace, err := workspaceOf(c)
+            if err != nil {
+                c.Error(err)
+                return
+            }
+            ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+            defer cancel()
+            _, data, err := readDataset(ctx, h.datasets, h.store, workspace, request.DatasetID)
+            if err != nil {
+                c.Error(err)
+                return
+            }
+            sampleData = string(data)
+        }
+
+        var project *utils.Bundle
+        if language == "" {
+            project, err = utils.NewBridgeBundle(workflowConfig, code, sampleData, opts)
+        } else {
+            project, err = utils.NewBundle(exporter, workflowConfig, concatenatedCode, sampleData, opts)
+        }
+        if err != nil {
+            c.Error(generationError(err, itemField, "items"))
+            return
+        }
+
+        slog.InfoContext(c.Request.Context(), "exported workflow bundle", "format", format, "nodes", len(workflowConfig.Nodes), "files", len(project.Files))
+        c.Header("Content-Disposition", `attachment; filename="workflow.zip"`)
+        c.Header("Content-Type", "application/zip")
+        c.Status(http.StatusOK)
+        if err := project.WriteZip(c.Writer); err != nil {
+            // The response has started, so the error can only be logged
+            slog.ErrorContext(c.Request.Context(), "failed to stream workflow bundle", "error", err)
+        }
+        return
+    }
+
+    if format != utils.DefaultExportFormat {
+        artifact, err := exporter.Generate(workflowConfig, concatenatedCode, opts)
+        if err != nil {
+            c.Error(generationError(fmt.Errorf("export %s: %w", format, err), itemField, "items"))
+            return
+        }
+
+        slog.InfoContext(c.Request.Context(), "exported workflow", "format", format, "nodes", len(workflowConfig.Nodes), "bytes", len(artifact))
+        c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="workflow%s"`, exporter.Extension))
+        c.Data(http.StatusOK, exporter.ContentType, artifact)
+        return
+    }
+
+    // Generate executable script
+    script, err := exporter.Generate(workflowConfig, concatenatedCode, opts)
+    if err != nil {
+        c.Error(generationError(err, itemField, "items"))
+        return
+    }
+    componentLanguage, _ := utils.GetLanguage(language)
+
+    c.JSON(http.StatusOK, gin.H{
+        "script":            string(script),
+        "language":          language,
+        "concatenated_code": concatenatedCode,
+        "message":           "Executable script generated successfully",
+        "total_components":  len(request.Items),
+        "requirements":      componentLanguage.Requirements(workflowConfig, concatenatedCode),
+    })
+}
+
+// itemsWorkflow builds the workflow of code items, two items to a stage,
+// and returns it with the concatenated code of each language
+func itemsWorkflow(request models.RunCodeRequest, exportedAt time.Time) (utils.WorkflowConfig, map[string]string) {
     workflowConfig := utils.WorkflowConfig{
-        Version:    "1.0",
-        ExportedAt: time.Now().Format(time.RFC3339),
-        Nodes:      []utils.Node{},
+        Version:      "1.0",
+        ExportedAt:   exportedAt.Format(time.RFC3339),
+        TaskType:     request.TaskType,
+        DatasetID:    request.DatasetID,
+        TargetColumn: request.TargetColumn,
+        Bridge:       request.Bridge,
+        Nodes:        []utils.Node{},
     }
 
-    var codeBlocks []string
-    stageNum := 1
+    // Items only go to stages that run in the generated code
+    var stageNums []int
+    for _, stage := range utils.Stages() {
+        if stage.Codegen != utils.StageCodegenSkip {
+            stageNums = append(stageNums, stage.Order)
+        }
+    }
+    if len(stageNums) == 0 {
+        stageNums = []int{1}
+    }
+
+    codeBlocks := map[string][]string{}
+    stageIndex := 0
     
     for i, item := range request.Items {
         if item.Code == "" {
@@ -197,49 +438,331 @@ func (h *WorkflowHandler) GenerateAndDownloadScript(c *gin.Context) {
         }
 
         // Process variables
-        processedCode := item.Code
+        processedCode := substitutePlaceholders(item.Code, item.Variables)
         variablesMap := make(map[string]interface{})
         
         for _, variable := range item.Variables {
-            placeholder := fmt.Sprintf("{{%s}}", variable.Name)
-            processedCode = strings.ReplaceAll(processedCode, placeholder, variable.Value)
             variablesMap[variable.Name] = variable.Value
         }
 
-        codeBlocks = append(codeBlocks, processedCode)
+        language := item.Language
+        if language == "" {
+            language = utils.DefaultLanguage
+        }
+        codeBlocks[language] = append(codeBlocks[language], processedCode)
 
         // Create node
         node := utils.Node{
             ID:        fmt.Sprintf("node_%d", i),
-            Name:      extractFunctionName(item.Code),
-            Stage:     stageNum,
-            Code:      extractFunctionName(item.Code),
+            Name:      itemFunctionName(item),
+            Stage:     stageNums[stageIndex],
+            Code:      itemFunctionName(item),
             Variables: variablesMap,
         }
+        if language != utils.DefaultLanguage {
+            node.Language = language
+        }
 
         workflowConfig.Nodes = append(workflowConfig.Nodes, node)
         
-        // Increment stage every 2 components (or use your logic)
-        if (i+1)%2 == 0 && stageNum < 4 {
-            stageNum++
+        // Move to the next stage every 2 components
+        if (i+1)%2 == 0 && stageIndex < len(stageNums)-1 {
+            stageIndex++
         }
     }
 
-    concatenatedCode := strings.Join(codeBlocks, "\n\n")
+    code := map[string]string{}
+    for language, blocks := range codeBlocks {
+        code[language] = strings.Join(blocks, "\n\n")
+    }
+    return workflowConfig, code
+}
 
-    // Generate executable script
-    script, err := utils.GenerateExecutableScript(workflowConfig, concatenatedCode)
+// checkDataLanguages rejects a workflow whose data format some of its
+// components' languages cannot read; field names the format's source
+func checkDataLanguages(workflow utils.WorkflowConfig, field string) error {
+    for _, language := range utils.WorkflowLanguages(workflow) {
+        if !utils.SupportsDataFormat(language, workflow.DataFormat) {
+            return apperror.Validation("Workflow does not match the dataset",
+                apperror.Field(field, "format", fmt.Sprintf("%s data cannot be read by %s components, use csv, tsv or jsonl", workflow.DataFormat, language)),
+            )
+        }
+    }
+    return nil
+}
+
+// generateOptions reads the reproducibility options from the query: ?seed=
+// fixes the random seeds and ?timestamp= (RFC 3339) the header timestamp
+func generateOptions(c *gin.Context) (utils.GenerateOptions, error) {
+    var opts utils.GenerateOptions
+
+    if raw := c.Query("seed"); raw != "" {
+        seed, err := strconv.ParseInt(raw, 10, 64)
+        if err != nil || seed < 0 || seed > math.MaxUint32 {
+            return opts, apperror.Validation("Invalid seed",
+                apperror.Field("seed", "range", "must be an integer between 0 and 4294967295"),
+            )
+        }
+        opts.Seed = &seed
+    }
+
+    if raw := c.Query("timestamp"); raw != "" {
+        timestamp, err := time.Parse(time.RFC3339, raw)
+        if err != nil {
+            return opts, apperror.Validation("Invalid timestamp",
+                apperror.Field("timestamp", "datetime", "must be an RFC 3339 timestamp"),
+            ).Wrap(err)
+        }
+        opts.Timestamp = timestamp
+    }
+
+    return opts, nil
+}
+
+// workflowDataset returns the dataset a workflow runs on: the referenced
+// dataset, a new temporary dataset holding the inline data, or nil
+func (h *WorkflowHandler) workflowDataset(c *gin.Context, request models.RunCodeRequest) (*models.Dataset, error) {
+    if err := checkDataSource(request); err != nil {
+        return nil, err
+    }
+    if request.DatasetID == "" && request.Data.Schema == "" {
+        return nil, nil
+    }
+
+    workspace, err := workspaceOf(c)
     if err != nil {
-        c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
-        return
+        return nil, err
     }
 
-    c.JSON(http.StatusOK, gin.H{
-        "script":            script,
-        "concatenated_code": concatenatedCode,
-        "message":           "Executable script generated successfully",
-        "total_components":  len(request.Items),
-    })
+    ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+    defer cancel()
+
+    if request.DatasetID != "" {
+        dataset, content, err := openDataset(ctx, h.datasets, h.store, workspace, request.DatasetID)
+        if err != nil {
+            return nil, err
+        }
+        content.Close()
+        return dataset, nil
+    }
+
+    format, _ := utils.GetDatasetFormat(request.Data.Format)
+    dataset := &models.Dataset{
+        Workspace:   workspace,
+        Name:        "workflow data",
+        Filename:    "workflow" + format.Extensions[0],
+        ContentType: format.ContentType,
+        Format:      format.Name,
+        Temporary:   true,
+        Retention:   h.settings.TempRetention,
+        CreatedBy:   c.GetHeader(middleware.UserHeader),
+    }
+    if err := saveDataset(ctx, h.datasets, h.blobs, h.store, dataset, strings.NewReader(request.Data.Schema), h.settings.MaxSize); err != nil {
+        return nil, err
+    }
+    return dataset, nil
+}
+
+// datasetProfile returns the profile and format of a workflow's data: the
+// referenced dataset or the inline data. The profile is nil when there is
+// no data, or the data could not be profiled, and the format empty when
+// there is no data.
+func (h *WorkflowHandler) datasetProfile(c *gin.Context, datasetID string, inline models.WorkflowData) (*utils.DatasetProfile, string, error) {
+    if datasetID == "" {
+        if inline.Schema == "" {
+            return nil, "", nil
+        }
+        format := datasetFormatName(inline.Format)
+        profile, err := utils.ProfileDataset(strings.NewReader(inline.Schema), format)
+        if err != nil {
+            slog.DebugContext(c.Request.Context(), "skipping column check of unreadable data", "format", format, "error", err)
+            return nil, format, nil
+        }
+        return profile, format, nil
+    }
+
+    workspace, err := workspaceOf(c)
+    if err != nil {
+        return nil, "", err
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    dataset, err := findDataset(ctx, h.datasets, workspace, datasetID)
+    if err != nil {
+        return nil, "", err
+    }
+    return dataset.Profile, datasetFormatName(dataset.Format), nil
+}
+
+// resolveComponents looks up the stored components defining the functions
+// the workflow's nodes call, by language, and copies their role onto the
+// nodes that do not state one and their dependencies onto every node. When
+// several components define a function the most recently updated one wins.
+func (h *WorkflowHandler) resolveComponents(workflow *utils.WorkflowConfig) error {
+    names := make([]string, 0, len(workflow.Nodes))
+    for _, node := range workflow.Nodes {
+        if node.Code != "" {
+            names = append(names, node.Code)
+        }
+    }
+    if len(names) == 0 {
+        return nil
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    cursor, err := h.collection.Find(ctx, bson.M{"function": bson.M{"$in": names}}, options.Find().
+        SetProjection(bson.M{"language": 1, "function": 1, "role": 1, "dependencies": 1}).
+        SetSort(bson.D{{Key: "updated_at", Value: 1}}))
+    if err != nil {
+        return apperror.Internal(err)
+    }
+    defer cursor.Close(ctx)
+    var components []models.Component
+    if err := cursor.All(ctx, &components); err != nil {
+        return apperror.Internal(err)
+    }
+
+    applyComponents(workflow, components)
+    return nil
+}
+
+// applyComponents copies the role of the component defining each node's
+// function onto the nodes that do not state one, and its dependencies onto
+// the node; later components override earlier ones defining the same
+// function
+func applyComponents(workflow *utils.WorkflowConfig, components []models.Component) {
+    byFunction := make(map[string]models.Component, len(components))
+    for _, component := range components {
+        byFunction[component.Language+" "+component.Function] = component
+    }
+    for i, node := range workflow.Nodes {
+        language := node.Language
+        if language == "" {
+            language = utils.DefaultLanguage
+        }
+        component, ok := byFunction[language+" "+node.Code]
+        if !ok {
+            continue
+        }
+        if node.Role == "" {
+            workflow.Nodes[i].Role = component.Role
+        }
+        workflow.Nodes[i].Dependencies = utils.MergeRequirements(component.Dependencies, node.Dependencies)
+    }
+}
+
+// checkColumns rejects a workflow whose variables or target name columns
+// missing from the profiled data. nodeField names the request field of a
+// node and targetField that of the target column.
+func checkColumns(workflow utils.WorkflowConfig, profile *utils.DatasetProfile, nodeField func(nodeID string) string, targetField string) error {
+    if profile == nil {
+        return nil
+    }
+    issues := utils.CheckColumns(workflow, profile)
+    if len(issues) == 0 {
+        return nil
+    }
+
+    fields := make([]apperror.FieldError, len(issues))
+    for i, issue := range issues {
+        field := targetField
+        if issue.NodeID != "" {
+            field = nodeField(issue.NodeID) + "." + issue.Field
+        }
+        fields[i] = apperror.Field(field, "column", issue.Message)
+        fields[i].Suggestion = issue.Suggestion
+    }
+    return apperror.Validation("Workflow does not match the dataset", fields...)
+}
+
+// datasetFormatName returns the name of a dataset format, the default for
+// data stored or sent without one
+func datasetFormatName(format string) string {
+    if format == "" {
+        return utils.DefaultDatasetFormat
+    }
+    return format
+}
+
+// checkDataSource rejects a request with both inline data and a dataset
+func checkDataSource(request models.RunCodeRequest) error {
+    if request.DatasetID != "" && request.Data.Schema != "" {
+        return apperror.Validation("Invalid workflow data",
+            apperror.Field("dataset_id", "excluded_with", "cannot be combined with data.schema"),
+        )
+    }
+    return nil
+}
+
+// scriptTemplate loads the template named by ?template= from the caller's
+// workspace, the built-in template when the parameter is missing
+func (h *WorkflowHandler) scriptTemplate(c *gin.Context) (*utils.ScriptTemplate, error) {
+    workspace, err := workspaceOf(c)
+    if err != nil {
+        return nil, err
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+    defer cancel()
+    return loadScriptTemplate(ctx, h.templates, workspace, c.Query("template"))
+}
+
+// substitutePlaceholders replaces {{name}} with the variable's value in a
+// single pass, so values are never substituted again
+func substitutePlaceholders(code string, variables []models.Variable) string {
+    pairs := make([]string, 0, 2*len(variables))
+    for _, variable := range variables {
+        pairs = append(pairs, fmt.Sprintf("{{%s}}", variable.Name), variable.Value)
+    }
+    return strings.NewReplacer(pairs...).Replace(code)
+}
+
+// generationError turns a rejected node value into a validation error on
+// the field returned by field, unreadable component code into one on
+// codeField, and anything else into an internal error
+func generationError(err error, field func(nodeID string) string, codeField string) error {
+    var nodeErr *utils.NodeError
+    if errors.As(err, &nodeErr) {
+        code := nodeErr.Language
+        if nodeErr.Field == "language" || nodeErr.Field == "stage" {
+            code = nodeErr.Field
+        } else if code == "" {
+            code = utils.LanguagePython
+        }
+        return apperror.Validation("Invalid workflow",
+            apperror.Field(field(nodeErr.NodeID)+"."+nodeErr.Field, code, nodeErr.Message),
+        ).Wrap(err)
+    }
+    var codeErr *utils.CodeError
+    if errors.As(err, &codeErr) {
+        return apperror.Validation("Invalid component code",
+            apperror.Field(codeField, codeErr.Language, codeErr.Message),
+        ).Wrap(err)
+    }
+    var templateErr *utils.TemplateError
+    if errors.As(err, &templateErr) && templateErr.Template != utils.DefaultTemplateName {
+        return invalidTemplate("template", err)
+    }
+    return apperror.Internal(err)
+}
+
+// itemField names the request item a node built from code items came from
+func itemField(nodeID string) string {
+    return fmt.Sprintf("items[%s]", strings.TrimPrefix(nodeID, "node_"))
+}
+
+// itemFunctionName returns the function a code item defines, in the
+// item's language
+func itemFunctionName(item models.CodeItem) string {
+    language, ok := utils.GetLanguage(item.Language)
+    if !ok || language.Name() == utils.LanguagePython {
+        return extractFunctionName(item.Code)
+    }
+    if name := language.FunctionName(item.Code); name != "" {
+        return name
+    }
+    return "unknown_function"
 }
 
 // extractFunctionName extracts function name from Python code
@@ -259,47 +782,132 @@ func extractFunctionName(code string) string {
     return "unknown_function"
 }
 
-// executeInDocker runs the Python code in a Docker container
-func executeInDocker(code string) (string, error) {
-    tempDir := "/tmp/code_execution"
-    err := os.MkdirAll(tempDir, 0755)
+// dockerImageEnv names the variables overriding the image code of each
+// language runs in; by default the language's runtime image is used, with
+// the vetted packages installed for Python
+var dockerImageEnv = map[string]string{
+    utils.LanguagePython:     "PYTHON_DOCKER_IMAGE",
+    utils.LanguageJavaScript: "NODE_DOCKER_IMAGE",
+    utils.LanguageGo:         "GO_DOCKER_IMAGE",
+}
+
+// maxImageBuildOutput caps the build output kept in the error of a failed
+// executor image build
+const maxImageBuildOutput = 4000
+
+// executorImage returns the image code of the language runs in: the image
+// of the language's variable when set, or the runtime image with the
+// vetted packages installed, built the first time it is needed
+func executorImage(ctx context.Context, l utils.Language) (string, error) {
+    if image := os.Getenv(dockerImageEnv[l.Name()]); image != "" {
+        return image, nil
+    }
+    image := utils.NewExecutorImage(l)
+    if len(image.Files) == 0 {
+        return image.Tag, nil
+    }
+    if exec.CommandContext(ctx, "docker", "image", "inspect", image.Tag).Run() == nil {
+        return image.Tag, nil
+    }
+
+    dir, err := os.MkdirTemp("", "executor_*")
     if err != nil {
-        return "", fmt.Errorf("failed to create temp directory: %v", err)
+        return "", fmt.Errorf("failed to create build directory: %v", err)
+    }
+    defer os.RemoveAll(dir)
+    for name, content := range image.Files {
+        if err := os.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+            return "", fmt.Errorf("failed to write %s: %v", name, err)
+        }
     }
 
-    timestamp := time.Now().Unix()
-    filename := fmt.Sprintf("script_%d.py", timestamp)
-    filepath := filepath.Join(tempDir, filename)
+    // The build downloads the vetted packages as wheels and runs none of
+    // their code
+    output, err := exec.CommandContext(ctx, "docker", "build", "--tag", image.Tag, dir).CombinedOutput()
+    if err != nil {
+        if len(output) > maxImageBuildOutput {
+            output = output[len(output)-maxImageBuildOutput:]
+        }
+        return "", fmt.Errorf("build %s: %v: %s", image.Tag, err, bytes.TrimSpace(output))
+    }
+    slog.InfoContext(ctx, "built executor image", "image", image.Tag, "packages", utils.ExecutorPackages())
+    removeExecutorImages(ctx, image.Tag)
+    return image.Tag, nil
+}
 
-    err = os.WriteFile(filepath, []byte(code), 0644)
+// removeExecutorImages removes the executor images other than keep, built
+// for packages vetted before; images still in use are left for next time
+func removeExecutorImages(ctx context.Context, keep string) {
+    output, err := exec.CommandContext(ctx, "docker", "image", "ls", "--format", "{{.Repository}}:{{.Tag}}", utils.ExecutorImagePrefix).Output()
     if err != nil {
-        return "", fmt.Errorf("failed to write code to file: %v", err)
+        slog.WarnContext(ctx, "failed to list executor images", "error", err)
+        return
+    }
+    for _, image := range strings.Fields(string(output)) {
+        if image == keep {
+            continue
+        }
+        if err := exec.CommandContext(ctx, "docker", "image", "rm", image).Run(); err != nil {
+            slog.WarnContext(ctx, "failed to remove executor image", "image", image, "error", err)
+        }
+    }
+}
+
+// executeInDocker runs the code in a Docker container of its language,
+// without network access. The code is mounted read-only from a directory
+// of its own; resultsDir, when set, is mounted at
+// utils.ComponentTestResultsDir for the code to write to. On failure the
+// output is what the code printed to stderr.
+func executeInDocker(ctx context.Context, language, code, resultsDir string) (string, error) {
+    l, ok := utils.GetLanguage(language)
+    if !ok {
+        return "", fmt.Errorf("unknown language %q", language)
+    }
+    runtime := l.Runtime()
+
+    dockerImage, err := executorImage(ctx, l)
+    if err != nil {
+        return "", err
     }
 
-    defer os.Remove(filepath)
+    tempDir := "/tmp/code_execution"
+    err = os.MkdirAll(tempDir, 0755)
+    if err != nil {
+        return "", fmt.Errorf("failed to create temp directory: %v", err)
+    }
+    codeDir, err := os.MkdirTemp(tempDir, "run_*")
+    if err != nil {
+        return "", fmt.Errorf("failed to create code directory: %v", err)
+    }
+    defer os.RemoveAll(codeDir)
 
-    dockerImage := os.Getenv("PYTHON_DOCKER_IMAGE")
-    if dockerImage == "" {
-        dockerImage = "python:3.11-slim"
+    filename := "script" + l.Extension()
+    err = os.WriteFile(filepath.Join(codeDir, filename), []byte(code), 0600)
+    if err != nil {
+        return "", fmt.Errorf("failed to write code to file: %v", err)
     }
 
-    cmd := exec.Command(
-        "docker", "run",
+    args := []string{
+        "run",
         "--rm",
-        "-v", fmt.Sprintf("%s:/code", tempDir),
+        "-v", fmt.Sprintf("%s:/code:ro", codeDir),
         "--network", "none",
         "--memory", "2g",
         "--cpus", "2",
-        dockerImage,
-        "python", fmt.Sprintf("/code/%s", filename),
-    )
+    }
+    if resultsDir != "" {
+        args = append(args, "-v", fmt.Sprintf("%s:%s", resultsDir, utils.ComponentTestResultsDir))
+    }
+    args = append(args, dockerImage)
+    args = append(args, strings.Fields(runtime.Run)...)
+    cmd := exec.CommandContext(ctx, "docker", append(args, fmt.Sprintf("/code/%s", filename))...)
 
     var stdout, stderr bytes.Buffer
     cmd.Stdout = &stdout
     cmd.Stderr = &stderr
 
     if err := cmd.Run(); err != nil {
-        return stderr.String(), fmt.Errorf("execution error: %v", err)
+        return stderr.String(), fmt.Errorf("execution error: %w", err)
     }
 
     output := stdout.String()
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo/integration/mtest"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+	"builder.ai/src/storage"
+	"builder.ai/src/utils"
+)
+
+func TestApplyComponents(t *testing.T) {
+	workflow := utils.WorkflowConfig{Nodes: []utils.Node{
+		{ID: "n1", Stage: 1, Code: "drop_sparse"},
+		{ID: "n2", Stage: 1, Code: "drop_sparse", Role: utils.RoleTransform, Dependencies: []string{"scipy"}},
+		{ID: "n3", Stage: 1, Code: "dropSparse", Language: utils.LanguageJavaScript},
+		{ID: "n4", Stage: 2, Code: "unknown"},
+	}}
+	components := []models.Component{
+		{Language: utils.LanguagePython, Function: "drop_sparse", Role: utils.RoleSink},
+		{Language: utils.LanguagePython, Function: "drop_sparse", Role: utils.RoleRowFilter, Dependencies: []string{"pandas==2.2.3"}}, // updated last
+		{Language: utils.LanguageJavaScript, Function: "dropSparse", Role: utils.RoleLoader},
+		{Language: utils.LanguageJavaScript, Function: "unknown", Role: utils.RoleSink},
+	}
+
+	applyComponents(&workflow, components)
+
+	want := map[string]string{
+		"n1": utils.RoleRowFilter, // the most recently updated component wins
+		"n2": utils.RoleTransform, // a stated role is kept
+		"n3": utils.RoleLoader,
+		"n4": "", // components of another language do not match
+	}
+	for _, node := range workflow.Nodes {
+		if node.Role != want[node.ID] {
+			t.Errorf("node %s: role = %q, want %q", node.ID, node.Role, want[node.ID])
+		}
+	}
+
+	// Dependencies are copied onto every matching node
+	if got := workflow.Nodes[1].Dependencies; !reflect.DeepEqual(got, []string{"pandas==2.2.3", "scipy"}) {
+		t.Errorf("node n2: dependencies = %v", got)
+	}
+	if got := workflow.Nodes[3].Dependencies; got != nil {
+		t.Errorf("node n4: dependencies = %v, want none", got)
+	}
+}
+
+func TestRunCodeValidatesItemsBeforeStoringData(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mt := mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+
+	mt.Run("mixed languages without a bridge", func(mt *mtest.T) {
+		dir := mt.TempDir()
+		body := `{"items": [{"code": "def load(path):\n    return path"}, {"code": "function scale(rows) { return rows }", "language": "javascript"}],
+			"data": {"schema": "a,b\n1,2\n"}}`
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/run", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+
+		h := &WorkflowHandler{datasets: mt.DB.Collection("datasets"), blobs: mt.DB.Collection("blobs"), store: storage.NewLocalStore(dir)}
+		h.RunCode(c)
+
+		if len(c.Errors) == 0 || apperror.As(c.Errors.Last().Err).Kind != apperror.KindValidation {
+			mt.Fatalf("errors = %v, want a validation error", c.Errors)
+		}
+		if got := commands(mt); len(got) != 0 {
+			mt.Errorf("commands = %v, want the data left unstored", got)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			mt.Errorf("blob store holds %d entries, want none", len(entries))
+		}
+	})
+}
@@ -0,0 +1,100 @@
+// src/logging/logger.go
+package logging
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+)
+
+// RequestInfo holds the per-request fields attached to every log line
+type RequestInfo struct {
+	ID    string
+	Route string
+	User  string
+	Start time.Time
+}
+
+type requestInfoKey struct{}
+
+// WithRequestInfo returns a copy of ctx carrying the request info
+func WithRequestInfo(ctx context.Context, info *RequestInfo) context.Context {
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFrom returns the request info stored in ctx, if any
+func RequestInfoFrom(ctx context.Context) (*RequestInfo, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	info, ok := ctx.Value(requestInfoKey{}).(*RequestInfo)
+	return info, ok && info != nil
+}
+
+// ContextHandler decorates records with the request info found in the context
+type ContextHandler struct {
+	slog.Handler
+}
+
+// Handle adds request_id, route, user and latency before delegating
+func (h ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if info, ok := RequestInfoFrom(ctx); ok {
+		r.AddAttrs(slog.String("request_id", info.ID))
+		if info.Route != "" {
+			r.AddAttrs(slog.String("route", info.Route))
+		}
+		if info.User != "" {
+			r.AddAttrs(slog.String("user", info.User))
+		}
+		if !info.Start.IsZero() {
+			r.AddAttrs(slog.Float64("latency_ms", float64(time.Since(info.Start).Microseconds())/1000))
+		}
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs keeps the context decoration on derived handlers
+func (h ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return ContextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup keeps the context decoration on derived handlers
+func (h ContextHandler) WithGroup(name string) slog.Handler {
+	return ContextHandler{h.Handler.WithGroup(name)}
+}
+
+// ParseLevel converts a LOG_LEVEL value to a slog level, defaulting to info
+func ParseLevel(value string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// New builds a logger writing JSON (default) or text output at the given level
+func New(w io.Writer, level, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: ParseLevel(level)}
+
+	var handler slog.Handler
+	if strings.EqualFold(strings.TrimSpace(format), "text") {
+		handler = slog.NewTextHandler(w, opts)
+	} else {
+		handler = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(ContextHandler{handler})
+}
+
+// Setup installs the default logger using LOG_LEVEL and LOG_FORMAT
+func Setup() {
+	slog.SetDefault(New(os.Stdout, os.Getenv("LOG_LEVEL"), os.Getenv("LOG_FORMAT")))
+}
@@ -0,0 +1,55 @@
+// src/middleware/error.middleware.go
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/gin-gonic/gin"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/envelope"
+)
+
+// ErrorResponse is the envelope returned for every failed request
+type ErrorResponse = envelope.ErrorResponse
+
+// ErrorBody carries the stable code, a client-safe message and field details
+type ErrorBody = envelope.ErrorBody
+
+// ErrorHandler renders the last error recorded with c.Error as the
+// uniform JSON envelope, unless the handler already wrote a response
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+
+		abortWithError(c, c.Errors.Last().Err)
+	}
+}
+
+// Recovery turns panics into an internal error envelope
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		abortWithError(c, fmt.Errorf("panic: %v", recovered))
+	})
+}
+
+func abortWithError(c *gin.Context, err error) {
+	appErr := apperror.As(err)
+	if appErr.Kind == apperror.KindInternal {
+		slog.ErrorContext(c.Request.Context(), "request failed", "code", appErr.Code, "error", appErr.Err)
+	}
+
+	c.AbortWithStatusJSON(appErr.Status(), ErrorResponse{
+		Error: ErrorBody{
+			Code:      appErr.Code,
+			Message:   appErr.Message,
+			Details:   appErr.Fields,
+			RequestID: GetRequestID(c),
+		},
+	})
+}
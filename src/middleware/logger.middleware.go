@@ -0,0 +1,57 @@
+// src/middleware/logger.middleware.go
+package middleware
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"builder.ai/src/envelope"
+	"builder.ai/src/logging"
+)
+
+// UserHeader identifies the calling user until authentication is in place
+const UserHeader = envelope.UserHeader
+
+// RequestLogger attaches request info to the request context and logs
+// one line per completed request
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		info := &logging.RequestInfo{
+			ID:    GetRequestID(c),
+			Route: route,
+			User:  c.GetHeader(UserHeader),
+			Start: time.Now(),
+		}
+		c.Request = c.Request.WithContext(logging.WithRequestInfo(c.Request.Context(), info))
+
+		c.Next()
+
+		status := c.Writer.Status()
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		}
+		if len(c.Errors) > 0 {
+			attrs = append(attrs, slog.String("errors", c.Errors.String()))
+		}
+
+		slog.LogAttrs(c.Request.Context(), level, "request completed", attrs...)
+	}
+}
@@ -0,0 +1,23 @@
+// src/middleware/workspace.middleware.go
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// WorkspaceHeader scopes workspace resources, such as script templates,
+// until authentication is in place
+const WorkspaceHeader = "X-Workspace-ID"
+
+// DefaultWorkspace is used when a request carries no workspace header
+const DefaultWorkspace = "default"
+
+// GetWorkspace returns the caller's workspace, DefaultWorkspace when the
+// header is missing. ok is false when the header is malformed.
+func GetWorkspace(c *gin.Context) (workspace string, ok bool) {
+	workspace = c.GetHeader(WorkspaceHeader)
+	if workspace == "" {
+		return DefaultWorkspace, true
+	}
+	return workspace, isValidRequestID(workspace)
+}
@@ -1,16 +1,17 @@
 package models
 
 import (
-	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // ComponentInput represents an input parameter for a component
 type ComponentInput struct {
     Name        string `json:"name" bson:"name" binding:"required"`
-    Type        string `json:"type" bson:"type" binding:"required"` // e.g., "string", "int", "float", "bool", "list", "dict", "any"
+    Type        string `json:"type" bson:"type" binding:"required"` // a name or alias of utils.Types, e.g. "int" or "DataFrame"
     Description string `json:"description" bson:"description"`
     Required    bool   `json:"required" bson:"required"`
     DefaultValue interface{} `json:"default_value,omitempty" bson:"default_value,omitempty"`
@@ -18,7 +19,7 @@ type ComponentInput struct {
 
 // ComponentOutput represents the output of a component
 type ComponentOutput struct {
-    Type        string `json:"type" bson:"type" binding:"required"` // e.g., "string", "int", "float", "bool", "list", "dict", "any", "none"
+    Type        string `json:"type" bson:"type" binding:"required"` // as ComponentInput.Type, or "none"
     Description string `json:"description" bson:"description"`
 }
 
@@ -27,78 +28,81 @@ type Component struct {
     Name        string             `json:"name" bson:"name" binding:"required"`
     Description string             `json:"description" bson:"description"`
     Code        string             `json:"code" bson:"code" binding:"required"`
-    Language    string             `json:"language" bson:"language" binding:"required"` // e.g., "python", "go", "javascript"
-    Stage       string             `json:"stage" bson:"stage" binding:"required,oneof=stage1 stage2 stage3 stage4"`
+    Language    string             `json:"language" bson:"language" binding:"required"` // one of utils.Languages
+    Stage       string             `json:"stage" bson:"stage" binding:"required"` // one of utils.StageNames
+    Role        string             `json:"role" bson:"role,omitempty" binding:"required"` // how the generated pipeline calls the component, see utils.Roles
     Tags        []string           `json:"tags" bson:"tags"`
     Inputs      []ComponentInput   `json:"inputs" bson:"inputs"`           // Array of inputs (1 to n)
     Output      *ComponentOutput   `json:"output,omitempty" bson:"output,omitempty"` // Optional output (0 or 1)
+    Dependencies []string          `json:"dependencies,omitempty" bson:"dependencies,omitempty"` // pip requirements, detected from the code's imports
+    Function    string             `json:"function,omitempty" bson:"function,omitempty"`         // the function the code defines, see utils.IndexComponentReferences
+    Calls       []string           `json:"calls,omitempty" bson:"calls,omitempty"`               // functions the code calls, matched with the function of other components
+    Tests       ComponentTests     `json:"tests,omitempty" bson:"tests,omitempty"`         // examples run by POST /components/:id/test
+    TestRuns    []ComponentTestRun `json:"test_runs,omitempty" bson:"test_runs,omitempty"` // latest runs of the tests, newest first
+    Verified    bool               `json:"verified" bson:"verified"`                        // the latest test run passed and the code has not changed since
     CreatedBy   primitive.ObjectID `json:"created_by,omitempty" bson:"created_by,omitempty"`
     CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
     UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
 }
 
-// Stage constants
-const (
-    Stage1 = "stage1"
-    Stage2 = "stage2"
-    Stage3 = "stage3"
-    Stage4 = "stage4"
-)
-
-// Data type constants
-const (
-    TypeString  = "string"
-    TypeInt     = "int"
-    TypeFloat   = "float"
-    TypeBool    = "bool"
-    TypeList    = "list"
-    TypeDict    = "dict"
-    TypeAny     = "any"
-    TypeDataFrame = "DataFrame"
-    TypeSeries   = "Series"
-    TypeTuple   = "tuple"
-    TypeArray   = "array"
-    TypeObject  = "object"
-    TypeIterable = "iterable"
-    TypeDateTime = "datetime"
-    TypeNdArray   = "ndarray"
-    TypeTensor    = "tensor"
-    TypeFunction  = "function"
-    TypeKerasModel = "keras.model"
-    TypeCallable = "callable"
-    TypeNone    = "none"
-)
+// ComponentTests are the tests of a component. Their parameters and
+// expected values decode from BSON as the plain values JSON decodes to.
+type ComponentTests []ComponentTest
 
-// IsValidStage checks if the stage is valid
-func (c *Component) IsValidStage() bool {
-    validStages := []string{Stage1, Stage2, Stage3, Stage4}
-    for _, stage := range validStages {
-        if c.Stage == stage {
-            return true
+// UnmarshalBSONValue decodes the tests and converts documents, arrays and
+// integers inside parameters and expected values to maps, slices and floats
+func (t *ComponentTests) UnmarshalBSONValue(typ bsontype.Type, data []byte) error {
+    var tests []ComponentTest
+    if err := (bson.RawValue{Type: typ, Value: data}).Unmarshal(&tests); err != nil {
+        return err
+    }
+    for i := range tests {
+        for name, value := range tests[i].Params {
+            tests[i].Params[name] = plainValue(value)
         }
+        tests[i].Expect.Value = plainValue(tests[i].Expect.Value)
     }
-    return false
+    *t = tests
+    return nil
 }
 
-// GetStageNumber returns the stage number
-func (c *Component) GetStageNumber() int {
-    switch c.Stage {
-    case Stage1:
-        return 1
-    case Stage2:
-        return 2
-    case Stage3:
-        return 3
-    case Stage4:
-        return 4
-    default:
-        return 0
+// plainValue converts a value decoded from BSON into an interface{} to
+// the value JSON decodes to
+func plainValue(value interface{}) interface{} {
+    switch v := value.(type) {
+    case primitive.D:
+        m := make(map[string]interface{}, len(v))
+        for _, e := range v {
+            m[e.Key] = plainValue(e.Value)
+        }
+        return m
+    case primitive.M:
+        m := make(map[string]interface{}, len(v))
+        for key, item := range v {
+            m[key] = plainValue(item)
+        }
+        return m
+    case primitive.A:
+        items := make([]interface{}, len(v))
+        for i, item := range v {
+            items[i] = plainValue(item)
+        }
+        return items
+    case int32:
+        return float64(v)
+    case int64:
+        return float64(v)
     }
+    return value
 }
 
-// HasOutput checks if component has an output
+// MaxTestRuns is the number of test runs kept in a component's history
+const MaxTestRuns = 20
+
+// HasOutput checks if component has an output; output types are stored
+// with their canonical names
 func (c *Component) HasOutput() bool {
-    return c.Output != nil && c.Output.Type != TypeNone
+    return c.Output != nil && c.Output.Type != "none"
 }
 
 // GetRequiredInputs returns all required inputs
@@ -123,33 +127,28 @@ func (c *Component) GetOptionalInputs() []ComponentInput {
     return optional
 }
 
-// ValidateInputTypes checks if input types are valid
-func (c *Component) ValidateInputTypes() bool {
-    validTypes := []string{TypeString, TypeInt, TypeFloat, TypeTensor, TypeBool, TypeList, TypeDict, TypeDataFrame, TypeSeries, TypeTuple, TypeArray, TypeObject, TypeIterable, TypeDateTime, TypeNdArray, TypeFunction, TypeKerasModel, TypeCallable, TypeAny}
+// Input returns the input with the given name
+func (c *Component) Input(name string) (ComponentInput, bool) {
     for _, input := range c.Inputs {
-        isValid := false
-        for _, validType := range validTypes {
-            if input.Type == validType {
-                isValid = true
-                break
-            }
-        }
-        if !isValid {
-            fmt.Println("❌ Invalid Input Type:", input.Type)
-            return false
+        if input.Name == name {
+            return input, true
         }
     }
-    return true
+    return ComponentInput{}, false
 }
 
-// ValidateOutputType checks if output type is valid
-func (c *Component) ValidateOutputType() bool {
-    if c.Output == nil {
-        return true
+// InputTypes returns the declared types of the inputs by name
+func (c *Component) InputTypes() map[string]string {
+    types := make(map[string]string, len(c.Inputs))
+    for _, input := range c.Inputs {
+        types[input.Name] = input.Type
     }
-    validTypes := []string{TypeString, TypeInt, TypeFloat, TypeTensor, TypeBool, TypeList, TypeDict, TypeAny, TypeDataFrame, TypeSeries, TypeTuple, TypeArray, TypeObject, TypeIterable, TypeDateTime, TypeNdArray, TypeFunction, TypeKerasModel, TypeCallable, TypeNone}
-    for _, validType := range validTypes {
-        if c.Output.Type == validType {
+    return types
+}
+
+func contains(values []string, value string) bool {
+    for _, v := range values {
+        if v == value {
             return true
         }
     }
@@ -0,0 +1,61 @@
+// src/models/componentTest.model.go
+package models
+
+import "time"
+
+// ComponentTest is an example call of a component with the result it must
+// return. Input is passed as the first argument and Params as keyword
+// arguments, parsed as workflow variables of the declared input types.
+type ComponentTest struct {
+	Name   string                 `json:"name" bson:"name"`
+	Input  *TestFrame             `json:"input,omitempty" bson:"input,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty" bson:"params,omitempty"`
+	Expect TestExpectation        `json:"expect" bson:"expect"`
+}
+
+// TestFrame is a small inline DataFrame
+type TestFrame struct {
+	Format string `json:"format" bson:"format"` // one of TestFrameFormats
+	Data   string `json:"data" bson:"data"`     // CSV with a header row, or a JSON array of records
+}
+
+// Inline DataFrame formats
+const (
+	TestFrameCSV  = "csv"
+	TestFrameJSON = "json"
+)
+
+// TestFrameFormats lists the inline DataFrame formats
+var TestFrameFormats = []string{TestFrameCSV, TestFrameJSON}
+
+// TestExpectation is what a component test checks; every field set must
+// hold. Numbers compare within Tolerance.
+type TestExpectation struct {
+	Shape     []int       `json:"shape,omitempty" bson:"shape,omitempty"`     // rows and columns of the returned DataFrame
+	Columns   []string    `json:"columns,omitempty" bson:"columns,omitempty"` // its column names, in order
+	Frame     *TestFrame  `json:"frame,omitempty" bson:"frame,omitempty"`     // the returned DataFrame, ignoring its index and dtypes
+	Value     interface{} `json:"value,omitempty" bson:"value,omitempty"`     // a scalar, list or dict result
+	Tolerance float64     `json:"tolerance,omitempty" bson:"tolerance,omitempty"`
+}
+
+// IsEmpty reports whether the expectation checks nothing
+func (e TestExpectation) IsEmpty() bool {
+	return len(e.Shape) == 0 && len(e.Columns) == 0 && e.Frame == nil && e.Value == nil
+}
+
+// ComponentTestResult is the outcome of one component test
+type ComponentTestResult struct {
+	Name       string  `json:"name" bson:"name"`
+	Passed     bool    `json:"passed" bson:"passed"`
+	Message    string  `json:"message,omitempty" bson:"message,omitempty"` // why the test failed
+	DurationMs float64 `json:"duration_ms" bson:"duration_ms"`
+}
+
+// ComponentTestRun is one run of a component's tests
+type ComponentTestRun struct {
+	RanAt   time.Time             `json:"ran_at" bson:"ran_at"`
+	Passed  bool                  `json:"passed" bson:"passed"`
+	Results []ComponentTestResult `json:"results" bson:"results"`
+	// Error is the output of a run that failed before reporting results
+	Error string `json:"error,omitempty" bson:"error,omitempty"`
+}
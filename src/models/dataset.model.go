@@ -0,0 +1,116 @@
+// src/models/dataset.model.go
+package models
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Dataset describes an uploaded file. The content lives in the blob store
+// under SHA256, so datasets with the same content share one blob.
+type Dataset struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Workspace   string             `json:"workspace" bson:"workspace"`
+	Name        string             `json:"name" bson:"name"`
+	Filename    string             `json:"filename" bson:"filename"`
+	ContentType string             `json:"content_type" bson:"content_type"`
+	Format      string             `json:"format" bson:"format,omitempty"` // one of utils.DatasetFormats, csv when empty
+	Size        int64              `json:"size" bson:"size"`
+	SHA256      string             `json:"sha256" bson:"sha256"`
+	Temporary   bool               `json:"temporary" bson:"temporary"` // sent inline with a workflow rather than uploaded
+	Retention   string             `json:"retention" bson:"retention"` // see ParseRetention
+	ExpiresAt   *time.Time         `json:"expires_at,omitempty" bson:"expires_at,omitempty"`
+	CreatedBy   string             `json:"created_by,omitempty" bson:"created_by,omitempty"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	LastUsedAt  time.Time          `json:"last_used_at" bson:"last_used_at"`
+	// Profile is computed when the dataset is stored; nil when the file
+	// could not be read in its format
+	Profile *DatasetProfile `json:"profile,omitempty" bson:"profile,omitempty"`
+}
+
+// RetentionForever keeps a dataset until it is deleted
+const RetentionForever = "forever"
+
+// ParseRetention parses how long a dataset is kept after its last use: a
+// duration such as "72h", a number of days such as "30d", or "forever".
+// Forever is returned as 0.
+func ParseRetention(retention string) (time.Duration, error) {
+	if retention == RetentionForever {
+		return 0, nil
+	}
+	if days, ok := strings.CutSuffix(retention, "d"); ok {
+		n, err := strconv.ParseInt(days, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid retention %q", retention)
+		}
+		if n > math.MaxInt64/int64(24*time.Hour) {
+			return 0, fmt.Errorf("invalid retention %q, must be at most %d days", retention, math.MaxInt64/int64(24*time.Hour))
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(retention)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid retention %q", retention)
+	}
+	return d, nil
+}
+
+// Touch records a use of the dataset and restarts its retention period
+func (d *Dataset) Touch(now time.Time) {
+	d.LastUsedAt = now
+	d.ExpiresAt = nil
+	if retention, err := ParseRetention(d.Retention); err == nil && retention > 0 {
+		expires := now.Add(retention)
+		d.ExpiresAt = &expires
+	}
+}
+
+// DatasetProfile summarises the columns of a dataset
+type DatasetProfile struct {
+	Rows             int               `json:"rows" bson:"rows"`
+	Columns          []ColumnProfile   `json:"columns" bson:"columns"`
+	TargetCandidates []TargetCandidate `json:"target_candidates" bson:"target_candidates"` // most likely first
+}
+
+// ColumnProfile describes one column. Min, Max, Mean and Quantiles are set
+// for numeric columns, Earliest and Latest for datetimes and TopValues for
+// booleans and categoricals.
+type ColumnProfile struct {
+	Name           string       `json:"name" bson:"name"`
+	Type           string       `json:"type" bson:"type"`
+	Nulls          int          `json:"nulls" bson:"nulls"`
+	Distinct       int          `json:"distinct" bson:"distinct"`
+	DistinctCapped bool         `json:"distinct_capped,omitempty" bson:"distinct_capped,omitempty"` // Distinct stopped counting
+	Min            *float64     `json:"min,omitempty" bson:"min,omitempty"`
+	Max            *float64     `json:"max,omitempty" bson:"max,omitempty"`
+	Mean           *float64     `json:"mean,omitempty" bson:"mean,omitempty"`
+	Quantiles      *Quantiles   `json:"quantiles,omitempty" bson:"quantiles,omitempty"`
+	Earliest       string       `json:"earliest,omitempty" bson:"earliest,omitempty"`
+	Latest         string       `json:"latest,omitempty" bson:"latest,omitempty"`
+	TopValues      []ValueCount `json:"top_values,omitempty" bson:"top_values,omitempty"`
+}
+
+// Quantiles of a numeric column, estimated from a sample for large files
+type Quantiles struct {
+	P25 float64 `json:"p25" bson:"p25"`
+	P50 float64 `json:"p50" bson:"p50"`
+	P75 float64 `json:"p75" bson:"p75"`
+}
+
+// ValueCount is a value and how many rows hold it
+type ValueCount struct {
+	Value string `json:"value" bson:"value"`
+	Count int    `json:"count" bson:"count"`
+}
+
+// TargetCandidate is a column that looks like what a workflow predicts
+type TargetCandidate struct {
+	Column   string `json:"column" bson:"column"`
+	TaskType string `json:"task_type" bson:"task_type"` // suggested task type
+	Reason   string `json:"reason" bson:"reason"`
+}
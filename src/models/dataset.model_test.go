@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseRetention(t *testing.T) {
+	tests := []struct {
+		retention string
+		want      time.Duration
+		err       string
+	}{
+		{"forever", 0, ""},
+		{"72h", 72 * time.Hour, ""},
+		{"90m", 90 * time.Minute, ""},
+		{"30d", 30 * 24 * time.Hour, ""},
+		{"106751d", 106751 * 24 * time.Hour, ""},
+		{"106752d", 0, "at most 106751 days"},
+		{"9223372036854775807d", 0, "at most"},
+		{"99999999999999999999d", 0, "invalid retention"},
+		{"0d", 0, "invalid retention"},
+		{"-1d", 0, "invalid retention"},
+		{"0s", 0, "invalid retention"},
+		{"-5h", 0, "invalid retention"},
+		{"3000000h", 0, "invalid retention"},
+		{"d", 0, "invalid retention"},
+		{"1.5d", 0, "invalid retention"},
+		{"", 0, "invalid retention"},
+		{"soon", 0, "invalid retention"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.retention, func(t *testing.T) {
+			got, err := ParseRetention(tt.retention)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("ParseRetention(%q) = %v, %v, want an error containing %q", tt.retention, got, err, tt.err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("ParseRetention(%q) = %v, %v, want %v", tt.retention, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestDatasetTouch(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	d := Dataset{Retention: "7d"}
+	d.Touch(now)
+	if !d.LastUsedAt.Equal(now) || d.ExpiresAt == nil || !d.ExpiresAt.Equal(now.Add(7*24*time.Hour)) {
+		t.Errorf("Touch() with 7d: last used %v, expires %v", d.LastUsedAt, d.ExpiresAt)
+	}
+
+	d.Retention = RetentionForever
+	d.Touch(now)
+	if d.ExpiresAt != nil {
+		t.Errorf("Touch() with forever: expires %v, want never", d.ExpiresAt)
+	}
+}
@@ -0,0 +1,53 @@
+// src/models/stage.model.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StageDefinition is a step of a workflow. Components belong to a stage by
+// name and workflow nodes by order; generated pipelines run the stages in
+// order.
+type StageDefinition struct {
+	Name        string   `json:"name" bson:"name"`
+	Order       int      `json:"order" bson:"order"` // the stage number of its workflow nodes
+	Description string   `json:"description" bson:"description"`
+	Roles       []string `json:"roles" bson:"roles"`     // roles its components may have, the first inferred by default; empty allows any
+	Codegen     string   `json:"codegen" bson:"codegen"` // one of StageCodegens
+}
+
+// How generated code treats the nodes of a stage
+const (
+	StageCodegenRun  = "run"  // calls the nodes in the pipeline
+	StageCodegenSkip = "skip" // leaves the nodes out, e.g. for stages run by other systems
+)
+
+// StageCodegens lists the codegen behaviours of stages
+var StageCodegens = []string{StageCodegenRun, StageCodegenSkip}
+
+// AllowsRole reports whether components of the stage may have role
+func (s StageDefinition) AllowsRole(role string) bool {
+	return len(s.Roles) == 0 || contains(s.Roles, role)
+}
+
+// Stage is a workflow stage stored in the stages collection. The stored
+// stages replace the built-in default stages when the server starts and
+// whenever one changes.
+type Stage struct {
+	ID              primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	StageDefinition `bson:",inline"`
+	CreatedAt       time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// StageUpdate is the body accepted when updating a stage; its name and
+// order cannot change because components refer to it by name and workflow
+// nodes by order
+type StageUpdate struct {
+	Order       int      `json:"order"` // the current order when set
+	Description string   `json:"description"`
+	Roles       []string `json:"roles"`
+	Codegen     string   `json:"codegen"`
+}
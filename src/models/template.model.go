@@ -0,0 +1,45 @@
+// src/models/template.model.go
+package models
+
+import (
+	"regexp"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScriptTemplate is a custom script template stored for a workspace. The
+// content redefines blocks of the built-in template; see utils.ScriptBlocks.
+type ScriptTemplate struct {
+	ID          primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	Workspace   string             `json:"workspace" bson:"workspace"`
+	Name        string             `json:"name" bson:"name"`
+	Description string             `json:"description" bson:"description"`
+	Content     string             `json:"content" bson:"content"`
+	Blocks      []string           `json:"blocks" bson:"blocks"` // blocks the content redefines
+	Builtin     bool               `json:"builtin,omitempty" bson:"-"`
+	CreatedAt   time.Time          `json:"created_at" bson:"created_at"`
+	UpdatedAt   time.Time          `json:"updated_at" bson:"updated_at"`
+}
+
+// TemplateUpload is the body accepted when uploading a template
+type TemplateUpload struct {
+	Description string `json:"description"`
+	Content     string `json:"content" binding:"required"`
+}
+
+// TemplatePreviewRequest is the body accepted by the template preview
+type TemplatePreviewRequest struct {
+	Template       string `json:"template"`        // stored template to render; ignored when content is set
+	Content        string `json:"content"`         // unsaved template source to try out
+	WorkflowConfig string `json:"workflow_config"` // JSON-encoded utils.WorkflowConfig; a sample workflow when empty
+	ComponentCode  string `json:"component_code"`
+}
+
+// templateNamePattern matches names such as "team-logging" or "parquet_v2"
+var templateNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// IsValidTemplateName checks a template name
+func IsValidTemplateName(name string) bool {
+	return templateNamePattern.MatchString(name)
+}
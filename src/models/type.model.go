@@ -0,0 +1,43 @@
+// src/models/type.model.go
+package models
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DataTypeDefinition is a type of component inputs and outputs. Names and
+// aliases match case-insensitively; components are stored with the
+// canonical name.
+type DataTypeDefinition struct {
+	Name        string   `json:"name" bson:"name"`
+	Aliases     []string `json:"aliases,omitempty" bson:"aliases,omitempty"`
+	Parent      string   `json:"parent,omitempty" bson:"parent,omitempty"`   // the type it is a kind of, e.g. iterable for Series
+	PythonHint  string   `json:"python_hint" bson:"python_hint"`             // the annotation in Python code, e.g. pd.DataFrame
+	Literal     string   `json:"literal,omitempty" bson:"literal,omitempty"` // how variables are written; empty when they cannot be
+	Description string   `json:"description" bson:"description"`
+	OutputOnly  bool     `json:"output_only,omitempty" bson:"output_only,omitempty"`
+	Custom      bool     `json:"custom" bson:"-"` // defined by users rather than built in
+}
+
+// DataType is a user-defined type stored in the types collection. Stored
+// types are registered next to the built-in types when the server starts
+// and whenever one changes.
+type DataType struct {
+	ID                 primitive.ObjectID `json:"id,omitempty" bson:"_id,omitempty"`
+	DataTypeDefinition `bson:",inline"`
+	CreatedAt          time.Time `json:"created_at" bson:"created_at"`
+	UpdatedAt          time.Time `json:"updated_at" bson:"updated_at"`
+}
+
+// DataTypeUpdate is the body accepted when updating a type; its name
+// cannot change because components refer to it
+type DataTypeUpdate struct {
+	Aliases     []string `json:"aliases"`
+	Parent      string   `json:"parent" binding:"required"`
+	PythonHint  string   `json:"python_hint"`
+	Literal     string   `json:"literal"`
+	Description string   `json:"description"`
+	OutputOnly  bool     `json:"output_only"`
+}
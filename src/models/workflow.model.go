@@ -0,0 +1,57 @@
+package models
+
+// Variable represents a single variable with name and value
+type Variable struct {
+    Name  string `json:"name"`
+    Value string `json:"value"`
+}
+
+// CodeItem represents a code block with its variables
+type CodeItem struct {
+    Code      string     `json:"code" binding:"required"`
+    Variables []Variable `json:"variables"`
+    Language  string     `json:"language" binding:"omitempty,oneof=python javascript go"` // utils.Languages, python when empty
+}
+
+// WorkflowData carries the dataset submitted with a workflow
+type WorkflowData struct {
+    Schema string `json:"schema"` // raw CSV string, or TSV or JSON Lines as set by Format
+    Format string `json:"format" binding:"omitempty,oneof=csv tsv jsonl"` // csv when empty; binary formats are uploaded as datasets
+}
+
+// RunCodeRequest is the body accepted by RunCode and GenerateAndDownloadScript
+type RunCodeRequest struct {
+    Items        []CodeItem   `json:"items" binding:"required,min=1"`
+    Data         WorkflowData `json:"data"`
+    DatasetID    string       `json:"dataset_id"` // uploaded dataset to use instead of data.schema
+    TaskType     string       `json:"task_type" binding:"omitempty,oneof=classification regression clustering forecasting"` // utils.TaskTypes, classification when empty
+    TargetColumn string       `json:"target_column"` // --target default of the generated code, utils.DefaultTargetColumn when empty
+    Bridge       string       `json:"bridge" binding:"omitempty,oneof=csv arrow"` // utils.Bridges, required to mix languages
+}
+
+// GenerateScriptRequest is the body accepted by GenerateExecutableScript
+type GenerateScriptRequest struct {
+    WorkflowConfig string `json:"workflow_config" binding:"required"` // JSON-encoded utils.WorkflowConfig
+    ComponentCode  string `json:"component_code" binding:"required"`
+}
+
+// RunCodeResult is the response of the run endpoint
+type RunCodeResult struct {
+    Message          string                   `json:"message"`
+    TotalItems       int                      `json:"total_items"`
+    ConcatenatedCode string                   `json:"concatenated_code"`
+    Components       []map[string]interface{} `json:"components"`
+    DatasetID        string                   `json:"dataset_id"`
+    CSVFile          string                   `json:"csv_file"` // local path of the dataset content, when stored on disk
+    DataFormat       string                   `json:"data_format,omitempty"` // format of the dataset content
+    Profile          *DatasetProfile          `json:"profile,omitempty"`
+}
+
+// ExportResult is the response of the export endpoint
+type ExportResult struct {
+    Script           string   `json:"script"`
+    ConcatenatedCode string   `json:"concatenated_code"`
+    Message          string   `json:"message"`
+    TotalComponents  int      `json:"total_components"`
+    Requirements     []string `json:"requirements"`
+}
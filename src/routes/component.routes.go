@@ -17,8 +17,12 @@ func SetupComponentRoutes(r *gin.Engine) {
             components.POST("", componentHandler.Create)             // Create new
             components.PUT("/:id", componentHandler.Update)          // Update
             components.DELETE("/:id", componentHandler.Delete)       // Delete
+            components.POST("/:id/test", componentHandler.RunTests)  // Run the component's tests
+            components.GET("/:id/usages", componentHandler.GetUsages) // Components calling this one
+            components.GET("/:id/dependencies", componentHandler.GetDependencies) // Components this one calls
             components.GET("/search", componentHandler.SearchByName) // Search
             components.GET("/stats", componentHandler.GetStageStats) // Get stats
+            components.GET("/languages", componentHandler.GetLanguages) // Supported languages
         }    
     }
 }
\ No newline at end of file
@@ -0,0 +1,23 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "builder.ai/src/handlers"
+)
+
+func SetupDatasetRoutes(r *gin.Engine) {
+    datasetHandler := handlers.NewDatasetHandler()
+    
+    api := r.Group("/api/v1")
+    {
+        datasets := api.Group("/datasets")
+        {
+            datasets.POST("", datasetHandler.Upload)                 // Multipart upload
+            datasets.POST("/profile", datasetHandler.Profile)        // Profile a CSV without storing it
+            datasets.GET("", datasetHandler.List)                    // Workspace datasets
+            datasets.GET("/:id", datasetHandler.Get)                 // Metadata
+            datasets.GET("/:id/download", datasetHandler.Download)   // Content
+            datasets.DELETE("/:id", datasetHandler.Delete)           // Delete
+        }
+    }
+}
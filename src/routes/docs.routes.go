@@ -0,0 +1,15 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "builder.ai/src/docs"
+)
+
+func SetupDocsRoutes(r *gin.Engine) {
+    api := r.Group("/api/v1")
+    {
+        api.GET("/openapi.json", docs.ServeSpec) // OpenAPI 3 document
+        api.GET("/docs", docs.ServeUI)           // Interactive docs page
+        api.GET("/docs/assets/*file", docs.ServeAsset) // Swagger UI, vendored into the binary
+    }
+}
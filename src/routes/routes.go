@@ -0,0 +1,17 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+)
+
+// Setup registers every API route on r
+func Setup(r *gin.Engine) {
+    SetupUserRoutes(r)
+    SetupComponentRoutes(r)
+    SetupStageRoutes(r)
+    SetupTypeRoutes(r)
+    SetupWorkflowRoutes(r)
+    SetupTemplateRoutes(r)
+    SetupDatasetRoutes(r)
+    SetupDocsRoutes(r)
+}
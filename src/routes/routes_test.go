@@ -0,0 +1,125 @@
+package routes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"builder.ai/config"
+	"builder.ai/src/docs"
+)
+
+// newTestEngine registers every route without a reachable database; the
+// driver connects lazily so handlers can be constructed offline
+func newTestEngine(t *testing.T) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI("mongodb://127.0.0.1:1"))
+	if err != nil {
+		t.Fatalf("mongo client: %v", err)
+	}
+	t.Cleanup(func() { client.Disconnect(context.Background()) })
+	config.DB = client.Database("builder_test")
+
+	r := gin.New()
+	Setup(r)
+	return r
+}
+
+func TestEveryRouteIsDocumented(t *testing.T) {
+	r := newTestEngine(t)
+	spec := docs.Spec()
+
+	for _, route := range r.Routes() {
+		if !spec.HasOperation(route.Method, route.Path) {
+			t.Errorf("%s %s is registered but missing from the OpenAPI spec", route.Method, route.Path)
+		}
+	}
+}
+
+func TestEveryDocumentedOperationIsRouted(t *testing.T) {
+	r := newTestEngine(t)
+
+	routed := map[string]bool{}
+	for _, route := range r.Routes() {
+		routed[route.Method+" "+docs.OpenAPIPath(route.Path)] = true
+	}
+
+	for path, item := range docs.Spec().Paths {
+		for method := range item {
+			key := http.MethodGet
+			switch method {
+			case "post":
+				key = http.MethodPost
+			case "put":
+				key = http.MethodPut
+			case "patch":
+				key = http.MethodPatch
+			case "delete":
+				key = http.MethodDelete
+			}
+			if !routed[key+" "+docs.BasePath+path] {
+				t.Errorf("%s %s is documented but not registered", key, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPIEndpointServesSpec(t *testing.T) {
+	r := newTestEngine(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/openapi.json", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+
+	var doc docs.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if doc.OpenAPI == "" || len(doc.Paths) == 0 {
+		t.Fatalf("spec is empty: %+v", doc)
+	}
+	for _, name := range []string{"Component", "ComponentInput", "ComponentOutput", "User", "WorkflowConfig", "Node", "ErrorResponse"} {
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			t.Errorf("schema %s missing from components", name)
+		}
+	}
+}
+
+func TestDocsPageLoadsOnlyServedFiles(t *testing.T) {
+	r := newTestEngine(t)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/docs", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	if policy := w.Header().Get("Content-Security-Policy"); !strings.Contains(policy, "default-src 'self'") {
+		t.Errorf("Content-Security-Policy = %q", policy)
+	}
+	if page := w.Body.String(); strings.Contains(page, "//") {
+		t.Errorf("page refers to another host:\n%s", page)
+	}
+
+	for path, want := range map[string]int{
+		"/api/v1/docs/assets/docs.js":       http.StatusOK,
+		"/api/v1/docs/assets/missing.js":    http.StatusNotFound,
+		"/api/v1/docs/assets/../openapi.go": http.StatusNotFound,
+	} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Code != want {
+			t.Errorf("GET %s: status = %d, want %d", path, w.Code, want)
+		}
+	}
+}
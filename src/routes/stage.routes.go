@@ -7,11 +7,17 @@ import (
 
 func SetupStageRoutes(r *gin.Engine) {
     componentHandler := handlers.NewComponentHandler()
+    stageHandler := handlers.NewStageHandler()
     
     api := r.Group("/api/v1")
     {
         stages := api.Group("/stages")
         {
+            stages.GET("", stageHandler.List)                         // Stages in order
+            stages.POST("", stageHandler.Create)                      // Add a stage
+            stages.GET("/:stage", stageHandler.Get)                   // Stage by name
+            stages.PUT("/:stage", stageHandler.Update)                // Change order, roles or codegen
+            stages.DELETE("/:stage", stageHandler.Delete)             // Delete an unused stage
             stages.GET("/:stage/components", componentHandler.GetByStage)
         }    
     }
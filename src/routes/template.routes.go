@@ -0,0 +1,22 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "builder.ai/src/handlers"
+)
+
+func SetupTemplateRoutes(r *gin.Engine) {
+    templateHandler := handlers.NewTemplateHandler()
+    
+    api := r.Group("/api/v1")
+    {
+        templates := api.Group("/templates")
+        {
+            templates.GET("", templateHandler.List)               // Built-in and workspace templates
+            templates.GET("/:name", templateHandler.Get)          // Template with its source
+            templates.PUT("/:name", templateHandler.Upload)       // Create or replace
+            templates.DELETE("/:name", templateHandler.Delete)    // Delete
+            templates.POST("/preview", templateHandler.Preview)   // Render without saving
+        }
+    }
+}
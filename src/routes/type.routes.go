@@ -0,0 +1,22 @@
+package routes
+
+import (
+    "github.com/gin-gonic/gin"
+    "builder.ai/src/handlers"
+)
+
+func SetupTypeRoutes(r *gin.Engine) {
+    typeHandler := handlers.NewTypeHandler()
+    
+    api := r.Group("/api/v1")
+    {
+        types := api.Group("/types")
+        {
+            types.GET("", typeHandler.List)               // Built-in and custom types
+            types.POST("", typeHandler.Create)            // Add a custom type
+            types.GET("/:name", typeHandler.Get)          // Type by name or alias
+            types.PUT("/:name", typeHandler.Update)       // Replace a custom type
+            types.DELETE("/:name", typeHandler.Delete)    // Delete an unused custom type
+        }
+    }
+}
@@ -4,40 +4,71 @@ import (
     "github.com/gin-gonic/gin"
     "github.com/gin-contrib/cors"
     "time"
-    "log"
+    "log/slog"
     "context"
+    "os"
     "builder.ai/src/handlers"
+    "builder.ai/src/logging"
+    "builder.ai/src/middleware"
     "builder.ai/src/routes"
+    "builder.ai/src/utils"
     "builder.ai/config"
 )
 
 func main() {
+    config.LoadEnv()
+    logging.Setup()
+
+    if err := utils.PinPackages(os.Getenv("PYTHON_PACKAGE_PINS")); err != nil {
+        slog.Warn("ignoring PYTHON_PACKAGE_PINS", "error", err)
+    }
+    if spec := os.Getenv("EXECUTOR_PYTHON_PACKAGES"); spec != "" {
+        if err := utils.SetExecutorPackages(spec); err != nil {
+            slog.Warn("ignoring EXECUTOR_PYTHON_PACKAGES, using the default packages", "error", err)
+        }
+    }
+
     config.ConnectDB()
     config.CreateIndexes()
+    config.RunMigrations()
+    if err := config.LoadStages(context.Background()); err != nil {
+        slog.Warn("using the built-in stages", "error", err)
+    }
+    if err := config.LoadTypes(context.Background()); err != nil {
+        slog.Warn("using the built-in types", "error", err)
+    }
 
     // Store the handler first
     componentHandler := handlers.NewComponentHandler()
     err := componentHandler.CreateSearchIndexes(context.Background())
     if err != nil {
-        log.Printf("Warning: Failed to create indexes: %v", err)
+        slog.Warn("failed to create search indexes", "error", err)
     }
+
+    // Remove datasets whose retention has run out
+    go handlers.NewDatasetHandler().RunSweeper(context.Background())
+
+    // Pick up the stages and types changed through other instances
+    go config.RefreshRegistries(context.Background(), config.RegistryRefreshInterval())
     
-    r := gin.Default()
+    r := gin.New()
+    r.Use(middleware.RequestID())
     
     // Configure CORS
     r.Use(cors.New(cors.Config{
         AllowOrigins:     []string{"http://localhost:3000", "http://localhost:3001", "http://127.0.0.1:3000"},
         AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-        ExposeHeaders:    []string{"Content-Length"},
+        AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", middleware.RequestIDHeader, middleware.UserHeader, middleware.WorkspaceHeader},
+        ExposeHeaders:    []string{"Content-Length", middleware.RequestIDHeader},
         AllowCredentials: true,
         MaxAge:           12 * time.Hour,
     }))
+
+    r.Use(middleware.RequestLogger())
+    r.Use(middleware.ErrorHandler())
+    r.Use(middleware.Recovery())
     
-    routes.SetupUserRoutes(r)
-    routes.SetupComponentRoutes(r)
-    routes.SetupStageRoutes(r)
-    routes.SetupWorkflowRoutes(r)
+    routes.Setup(r)
     
     r.Run("localhost:8080")
-}
\ No newline at end of file
+}
@@ -0,0 +1,37 @@
+// src/storage/blob.go
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"regexp"
+)
+
+// ErrNotFound is returned for a key the store does not hold
+var ErrNotFound = errors.New("blob not found")
+
+// ErrTooLarge is returned by Put when the content exceeds the size limit
+var ErrTooLarge = errors.New("blob too large")
+
+// BlobStore keeps immutable blobs addressed by the hex SHA-256 of their
+// content, so storing the same content twice stores it once
+type BlobStore interface {
+	// Put stores the content of r, reading at most maxSize bytes when
+	// maxSize is positive, and returns its key and size
+	Put(ctx context.Context, r io.Reader, maxSize int64) (key string, size int64, err error)
+	// Open returns the content of a blob
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes a blob; deleting a missing blob is not an error
+	Delete(ctx context.Context, key string) error
+	// Exists reports whether the store holds a blob
+	Exists(ctx context.Context, key string) (bool, error)
+}
+
+// keyPattern matches a hex SHA-256 key
+var keyPattern = regexp.MustCompile(`^[0-9a-f]{64}$`)
+
+// IsValidKey checks a blob key
+func IsValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
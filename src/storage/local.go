@@ -0,0 +1,124 @@
+// src/storage/local.go
+package storage
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// LocalStore keeps blobs as files under a root directory, sharded by the
+// first two characters of the key
+type LocalStore struct {
+	root string
+}
+
+// NewLocalStore returns a store rooted at dir. The directory is created on
+// the first Put.
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{root: dir}
+}
+
+// Path returns the file holding a blob, for tools that need a file name
+// such as the code runner
+func (s *LocalStore) Path(key string) (string, error) {
+	if !IsValidKey(key) {
+		return "", fmt.Errorf("invalid blob key %q", key)
+	}
+	return filepath.Join(s.root, key[:2], key), nil
+}
+
+func (s *LocalStore) Put(ctx context.Context, r io.Reader, maxSize int64) (string, int64, error) {
+	// Write to a temporary file while hashing, then move it into place so
+	// readers never see a partial blob
+	if err := os.MkdirAll(filepath.Join(s.root, "tmp"), 0755); err != nil {
+		return "", 0, err
+	}
+	tmp, err := os.CreateTemp(filepath.Join(s.root, "tmp"), "upload-*")
+	if err != nil {
+		return "", 0, err
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	if maxSize > 0 {
+		r = io.LimitReader(r, maxSize+1)
+	}
+	h := sha256.New()
+	size, err := io.Copy(io.MultiWriter(tmp, h), contextReader{ctx, r})
+	if err != nil {
+		return "", 0, err
+	}
+	if maxSize > 0 && size > maxSize {
+		return "", 0, ErrTooLarge
+	}
+	if err := tmp.Close(); err != nil {
+		return "", 0, err
+	}
+
+	key := hex.EncodeToString(h.Sum(nil))
+	path, _ := s.Path(key)
+	if _, err := os.Stat(path); err == nil {
+		return key, size, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", 0, err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", 0, err
+	}
+	return key, size, nil
+}
+
+func (s *LocalStore) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.Path(key)
+	if err != nil {
+		return nil, ErrNotFound
+	}
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return f, err
+}
+
+func (s *LocalStore) Delete(ctx context.Context, key string) error {
+	path, err := s.Path(key)
+	if err != nil {
+		return nil
+	}
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (s *LocalStore) Exists(ctx context.Context, key string) (bool, error) {
+	path, err := s.Path(key)
+	if err != nil {
+		return false, nil
+	}
+	_, err = os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// contextReader stops a copy when the context is done
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestLocalStoreIsContentAddressed(t *testing.T) {
+	ctx := context.Background()
+	store := NewLocalStore(t.TempDir())
+
+	key, size, err := store.Put(ctx, strings.NewReader("a,b\n1,2\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !IsValidKey(key) || size != 8 {
+		t.Fatalf("Put = %q, %d, want a sha256 key and 8 bytes", key, size)
+	}
+
+	again, _, err := store.Put(ctx, strings.NewReader("a,b\n1,2\n"), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != key {
+		t.Errorf("same content stored under %q and %q", key, again)
+	}
+
+	r, err := store.Open(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(r)
+	r.Close()
+	if string(data) != "a,b\n1,2\n" {
+		t.Errorf("Open = %q", data)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := store.Exists(ctx, key); ok {
+		t.Error("blob exists after Delete")
+	}
+	if _, err := store.Open(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Open after Delete = %v, want ErrNotFound", err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("deleting a missing blob = %v", err)
+	}
+}
+
+func TestLocalStoreLimitsSize(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	if _, _, err := store.Put(context.Background(), strings.NewReader("0123456789"), 9); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Put over the limit = %v, want ErrTooLarge", err)
+	}
+	if _, _, err := store.Put(context.Background(), strings.NewReader("0123456789"), 10); err != nil {
+		t.Errorf("Put at the limit = %v", err)
+	}
+}
+
+func TestLocalStoreRejectsInvalidKeys(t *testing.T) {
+	store := NewLocalStore(t.TempDir())
+
+	for _, key := range []string{"", "../etc/passwd", strings.Repeat("A", 64)} {
+		if _, err := store.Open(context.Background(), key); !errors.Is(err, ErrNotFound) {
+			t.Errorf("Open(%q) = %v, want ErrNotFound", key, err)
+		}
+	}
+}
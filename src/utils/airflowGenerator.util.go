@@ -0,0 +1,84 @@
+// src/utils/airflowGenerator.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const airflowImports = `import pendulum
+from airflow import DAG
+from airflow.operators.python import PythonOperator
+`
+
+// GenerateAirflowDAG renders the workflow as an Airflow DAG file with one
+// PythonOperator per node. Tasks pass the pipeline state through pickle
+// files in a directory per DAG run.
+func GenerateAirflowDAG(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	tasks, err := planTasks(seedWorkflow(workflow, componentCode, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+
+	if err := writeTaskModule(&sb, workflow, componentCode, "Airflow DAG",
+		`Every component runs as its own PythonOperator. The tasks exchange the
+pipeline state through pickle files under BUILDER_RUN_DIR/<run_id>.`,
+		airflowImports, tasks, opts); err != nil {
+		return nil, err
+	}
+
+	sb.WriteString(`
+
+# ============================================================
+# DAG DEFINITION
+# ============================================================
+
+RUN_DIR = os.environ.get('BUILDER_RUN_DIR', '/tmp/builder_runs')
+
+with DAG(
+    dag_id='builder_workflow',
+    start_date=pendulum.datetime(2024, 1, 1, tz='UTC'),
+    schedule=None,
+    catchup=False,
+    params={
+        'data_file': ` + pyQuote(dataFileName(workflow)) + `,
+        'target_column': ` + targetDefault(workflow) + `,
+        'output_file': 'output.csv',
+    },
+    tags=['builder'],
+) as dag:
+    run_dir = os.path.join(RUN_DIR, '{{ run_id }}')
+
+`)
+
+	for _, task := range tasks {
+		var kwargs string
+		switch task.ID {
+		case loadDataTaskID:
+			kwargs = "'data_file': '{{ params.data_file }}', 'target_column': '{{ params.target_column }}'"
+		case saveOutputTaskID:
+			kwargs = fmt.Sprintf("'upstream': %s, 'output_file': '{{ params.output_file }}'", pythonStringList(task.Upstream))
+		default:
+			kwargs = fmt.Sprintf("'upstream': %s, 'target_column': '{{ params.target_column }}'", pythonStringList(task.Upstream))
+		}
+
+		sb.WriteString(fmt.Sprintf(`    # %s
+    %s_task = PythonOperator(
+        task_id='%s',
+        python_callable=%s,
+        op_kwargs={'run_dir': run_dir, %s},
+    )
+`, task.Name, task.ID, task.ID, task.Function, kwargs))
+	}
+
+	sb.WriteString("\n")
+	for _, task := range tasks {
+		for _, up := range task.Upstream {
+			sb.WriteString(fmt.Sprintf("    %s_task >> %s_task\n", up, task.ID))
+		}
+	}
+
+	return []byte(sb.String()), nil
+}
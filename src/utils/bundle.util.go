@@ -0,0 +1,391 @@
+// src/utils/bundle.util.go
+package utils
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+)
+
+// BundleFormat is the only archive format supported for project bundles
+const BundleFormat = "zip"
+
+// BundleDir is the directory the bundle files are placed in
+const BundleDir = "workflow"
+
+// BundleFile is a single file of a project bundle
+type BundleFile struct {
+	Name    string
+	Content []byte
+	Mode    fs.FileMode
+}
+
+// Bundle is a self-contained project that reproduces a workflow run
+type Bundle struct {
+	Files []BundleFile
+	// Modified is the file time in the archive, the current time when zero
+	Modified time.Time
+}
+
+// NewBundle generates the artifact for the exporter and the files needed to
+// run it: the package manifest of the exporter's language, e.g.
+// requirements.txt, a Dockerfile, a README, the workflow JSON and, when
+// given, the sample data in the workflow's data format, e.g. as data.csv
+func NewBundle(exporter Exporter, workflow WorkflowConfig, componentCode, sampleData string, opts GenerateOptions) (*Bundle, error) {
+	language, ok := GetLanguage(exporter.Language)
+	if !ok {
+		return nil, fmt.Errorf("unknown language %q", exporter.Language)
+	}
+
+	artifact, err := exporter.Generate(workflow, componentCode, opts)
+	if err != nil {
+		return nil, fmt.Errorf("export %s: %w", exporter.Format, err)
+	}
+
+	workflowJSON, err := json.MarshalIndent(workflow, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode workflow: %w", err)
+	}
+
+	fileName := "workflow" + exporter.Extension
+	dataFile := dataFileName(workflow)
+	command := strings.NewReplacer("{file}", fileName, "{data}", dataFile, "{target}", shellQuote(workflow.Target())).Replace(exporter.Command)
+
+	requirements := language.Requirements(workflow, componentCode)
+	if language.Name() == LanguagePython {
+		requirements = MergeRequirements(requirements, exporter.Requirements)
+	}
+	manifest, manifestContent := language.Manifest(requirements)
+
+	// Go programs are run with go run, the scripts directly
+	mode := fs.FileMode(0644)
+	if exporter.Format == DefaultExportFormat && language.Name() != LanguageGo {
+		mode = 0755
+	}
+
+	var installs []dockerInstall
+	if manifest != "" {
+		installs = append(installs, dockerInstall{Manifest: manifest, Run: language.Runtime().Install})
+	}
+
+	b := &Bundle{Modified: opts.Timestamp}
+	b.add(fileName, artifact, mode)
+	if manifest != "" {
+		b.add(manifest, manifestContent, 0644)
+	}
+	b.add("Dockerfile", []byte(bundleDockerfile(language.Runtime().Image, nil, installs, command)), 0644)
+	b.add("README.md", []byte(bundleReadme(exporter, workflow, fileName, dataFile, command, manifest, sampleData != "")), 0644)
+	b.add("workflow.json", append(workflowJSON, '\n'), 0644)
+	if sampleData != "" {
+		b.add(dataFile, []byte(sampleData), 0644)
+	}
+	return b, nil
+}
+
+// NewBridgeBundle generates the bundle of a workflow whose components are
+// in several languages: one program for every run of consecutive stages in
+// the same language, named after the stages, e.g. stage1-2.py, and run.sh,
+// which runs the programs in order and passes the data from one to the next
+// as a file of the workflow's bridge format. code holds the component code
+// of each language.
+func NewBridgeBundle(workflow WorkflowConfig, code map[string]string, sampleData string, opts GenerateOptions) (*Bundle, error) {
+	segments, err := languageSegments(workflow)
+	if err != nil {
+		return nil, err
+	}
+	if workflow.Bridge == "" {
+		return nil, fmt.Errorf("workflow has no bridge")
+	}
+
+	workflowJSON, err := json.MarshalIndent(workflow, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode workflow: %w", err)
+	}
+
+	b := &Bundle{Modified: opts.Timestamp}
+	var programs []bridgeProgram
+	input := `"$data"`
+	for i, s := range segments {
+		language, _ := GetLanguage(s.Language)
+		part := workflow
+		part.Nodes = s.Nodes
+		program, err := language.Generate(part, code[s.Language], opts)
+		if err != nil {
+			return nil, fmt.Errorf("export %s: %w", s.name(), err)
+		}
+
+		output := "run/" + s.name() + bridgeExtensions[workflow.Bridge]
+		if i == len(segments)-1 {
+			output = `"$output"`
+		}
+		fileName := s.name() + language.Extension()
+		programs = append(programs, bridgeProgram{
+			File:     fileName,
+			Language: s.Language,
+			Stages:   s.Stages,
+			Command:  strings.NewReplacer("{file}", fileName, "{data}", input, "{target}", `"$target"`, "{output}", output).Replace(language.Runtime().Command()),
+		})
+		input = output
+		b.add(fileName, program, 0644)
+	}
+
+	// The image is that of the first language in Languages order, with the
+	// others installed from Debian packages
+	var image string
+	var packages []string
+	var installs []dockerInstall
+	var manifests []string
+	for _, name := range WorkflowLanguages(workflow) {
+		language, _ := GetLanguage(name)
+		if image == "" {
+			image = language.Runtime().Image
+		} else {
+			packages = append(packages, language.Runtime().DebianPackages...)
+		}
+
+		part := workflow
+		part.Nodes = nil
+		for _, node := range workflow.Nodes {
+			if NodeLanguage(node) == name {
+				part.Nodes = append(part.Nodes, node)
+			}
+		}
+		if manifest, content := language.Manifest(language.Requirements(part, code[name])); manifest != "" {
+			b.add(manifest, content, 0644)
+			installs = append(installs, dockerInstall{Manifest: manifest, Run: language.Runtime().Install})
+			manifests = append(manifests, manifest)
+		}
+	}
+
+	dataFile := dataFileName(workflow)
+	b.add("run.sh", []byte(bridgeScript(workflow, dataFile, programs)), 0755)
+	b.add("Dockerfile", []byte(bundleDockerfile(image, packages, installs, "sh run.sh")), 0644)
+	b.add("README.md", []byte(bridgeReadme(workflow, programs, manifests, dataFile, sampleData != "")), 0644)
+	b.add("workflow.json", append(workflowJSON, '\n'), 0644)
+	if sampleData != "" {
+		b.add(dataFile, []byte(sampleData), 0644)
+	}
+	return b, nil
+}
+
+// bridgeProgram is a program of a bridge bundle and the run.sh line
+// running it
+type bridgeProgram struct {
+	File     string
+	Language string
+	Stages   []int
+	Command  string
+}
+
+func (b *Bundle) add(name string, content []byte, mode fs.FileMode) {
+	b.Files = append(b.Files, BundleFile{Name: name, Content: content, Mode: mode})
+}
+
+// WriteZip streams the bundle as a zip archive with every file under BundleDir
+func (b *Bundle) WriteZip(w io.Writer) error {
+	zw := zip.NewWriter(w)
+	modified := b.Modified
+	if modified.IsZero() {
+		modified = time.Now()
+	}
+
+	for _, file := range b.Files {
+		header := &zip.FileHeader{
+			Name:     BundleDir + "/" + file.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		}
+		header.SetMode(file.Mode)
+
+		fw, err := zw.CreateHeader(header)
+		if err != nil {
+			return fmt.Errorf("add %s: %w", file.Name, err)
+		}
+		if _, err := fw.Write(file.Content); err != nil {
+			return fmt.Errorf("write %s: %w", file.Name, err)
+		}
+	}
+
+	return zw.Close()
+}
+
+// shellQuote quotes s for sh unless it only holds safe characters
+func shellQuote(s string) string {
+	safe := s != "" && strings.IndexFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("_-.,/:", r))
+	}) < 0
+	if safe {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// dockerInstall is a Dockerfile step installing a package manifest
+type dockerInstall struct {
+	Manifest string
+	Run      string
+}
+
+// bundleDockerfile renders a Dockerfile on image that installs the Debian
+// packages and the manifests and runs command
+func bundleDockerfile(image string, packages []string, installs []dockerInstall, command string) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("FROM %s\n\nWORKDIR /app\n\n", image))
+	if len(packages) > 0 {
+		sb.WriteString(fmt.Sprintf("RUN apt-get update && apt-get install -y --no-install-recommends %s && rm -rf /var/lib/apt/lists/*\n\n", strings.Join(packages, " ")))
+	}
+	for _, install := range installs {
+		sb.WriteString(fmt.Sprintf("COPY %s .\nRUN %s\n", install.Manifest, install.Run))
+	}
+	if len(installs) > 0 {
+		sb.WriteString("\n")
+	}
+
+	cmd, _ := json.Marshal([]string{"sh", "-c", command})
+	sb.WriteString(fmt.Sprintf("COPY . .\n\nCMD %s\n", cmd))
+	return sb.String()
+}
+
+// manifestDocs describe the package manifests in the README
+var manifestDocs = map[string]string{
+	"requirements.txt": "Python packages imported by the components and the pipeline",
+	"package.json":     "npm packages required by the components",
+}
+
+// manifestInstalls install the package manifests locally
+var manifestInstalls = map[string]string{
+	"requirements.txt": "pip install -r requirements.txt",
+	"package.json":     "npm install",
+}
+
+func bundleReadme(exporter Exporter, workflow WorkflowConfig, fileName, dataFile, command, manifest string, hasData bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(`# Workflow bundle
+
+Exported at: %s
+Version: %s
+Format: %s
+Total Components: %d
+
+## Contents
+
+- %s: the generated workflow
+`, workflow.ExportedAt, workflow.Version, exporter.Format, len(workflow.Nodes), fileName))
+	if manifest != "" {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", manifest, manifestDocs[manifest]))
+	}
+	sb.WriteString(`- Dockerfile: image that installs the requirements and runs the workflow
+- workflow.json: the workflow configuration
+`)
+	if hasData {
+		sb.WriteString(fmt.Sprintf("- %s: the sample data submitted with the workflow\n", dataFile))
+	}
+
+	sb.WriteString("\n## Run locally\n\n")
+	if manifest != "" {
+		sb.WriteString("    " + manifestInstalls[manifest] + "\n")
+	}
+	sb.WriteString(fmt.Sprintf(`    %s
+
+Replace %s with your own data and %s with the name of its target column.
+`, command, dataFile, shellQuote(workflow.Target())))
+
+	sb.WriteString(dockerReadme(dataFile, hasData))
+	return sb.String()
+}
+
+// dockerReadme renders the README section running the bundle with Docker,
+// mounting the data file when the bundle has none
+func dockerReadme(dataFile string, hasData bool) string {
+	mount := ""
+	if !hasData {
+		mount = fmt.Sprintf(` -v "$PWD/%s:/app/%s"`, dataFile, dataFile)
+	}
+	return fmt.Sprintf(`
+## Run with Docker
+
+    docker build -t builder-workflow .
+    docker run --rm%s builder-workflow
+`, mount)
+}
+
+// bridgeScript renders run.sh, which runs the programs of a bridge bundle
+// in order. Its arguments override the data file, the target column and
+// the output file.
+func bridgeScript(workflow WorkflowConfig, dataFile string, programs []bridgeProgram) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf(`#!/bin/sh
+# Runs the workflow program by program, passing the data between them as
+# %s files in run/
+#
+# Usage: sh run.sh [data file] [target column] [output file]
+set -e
+
+data=${1:-%s}
+target=${2:-%s}
+output=${3:-output.csv}
+
+mkdir -p run
+`, strings.ToUpper(workflow.Bridge), shellQuote(dataFile), shellQuote(workflow.Target())))
+	for _, program := range programs {
+		sb.WriteString(fmt.Sprintf("\necho \"==> %s (%s)\"\n%s\n", program.File, program.Language, program.Command))
+	}
+	return sb.String()
+}
+
+func bridgeReadme(workflow WorkflowConfig, programs []bridgeProgram, manifests []string, dataFile string, hasData bool) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(`# Workflow bundle
+
+Exported at: %s
+Version: %s
+Bridge: %s
+Total Components: %d
+
+The components are written in several languages. Each program below runs
+the stages of one language; run.sh runs them in order and passes the data
+from one to the next as %s files in run/.
+
+## Contents
+
+`, workflow.ExportedAt, workflow.Version, workflow.Bridge, len(workflow.Nodes), strings.ToUpper(workflow.Bridge)))
+	for _, program := range programs {
+		stages := make([]string, len(program.Stages))
+		for i, stage := range program.Stages {
+			stages[i] = fmt.Sprint(stage)
+		}
+		label := "stage "
+		if len(stages) > 1 {
+			label = "stages "
+		}
+		sb.WriteString(fmt.Sprintf("- %s: the %s components of %s%s\n", program.File, program.Language, label, strings.Join(stages, ", ")))
+	}
+	sb.WriteString("- run.sh: runs the programs in order\n")
+	for _, manifest := range manifests {
+		sb.WriteString(fmt.Sprintf("- %s: %s\n", manifest, manifestDocs[manifest]))
+	}
+	sb.WriteString(`- Dockerfile: image with every runtime that installs the requirements and runs the workflow
+- workflow.json: the workflow configuration
+`)
+	if hasData {
+		sb.WriteString(fmt.Sprintf("- %s: the sample data submitted with the workflow\n", dataFile))
+	}
+
+	sb.WriteString("\n## Run locally\n\n")
+	for _, manifest := range manifests {
+		sb.WriteString("    " + manifestInstalls[manifest] + "\n")
+	}
+	sb.WriteString(fmt.Sprintf(`    sh run.sh %s %s output.csv
+
+Replace %s with your own data and %s with the name of its target column.
+`, dataFile, shellQuote(workflow.Target()), dataFile, shellQuote(workflow.Target())))
+
+	sb.WriteString(dockerReadme(dataFile, hasData))
+	return sb.String()
+}
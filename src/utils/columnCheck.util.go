@@ -0,0 +1,258 @@
+// src/utils/columnCheck.util.go
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxListedColumns is how many dataset columns an issue without a
+// suggestion lists
+const maxListedColumns = 10
+
+// ColumnIssue is a column the workflow refers to that the dataset cannot
+// provide
+type ColumnIssue struct {
+	NodeID     string // empty for the workflow's target column
+	Field      string // "variables.<name>" for nodes, "target_column" for the workflow
+	Column     string
+	Message    string
+	Suggestion string // closest dataset column, when one is close enough
+}
+
+// CheckColumns checks the column names in node variables and the target
+// column against the header and inferred types of the dataset, so a
+// workflow that would fail with a KeyError is rejected before generation.
+// Variables named column, columns or ending in _column or _columns, and
+// variables of the column and columns input types, hold column names.
+// Variables such as new_column and output_columns name the columns a node
+// adds instead; nodes in later stages, or later in the same stage, may
+// refer to them. The target is checked when the workflow states it or has
+// nodes that use it.
+func CheckColumns(workflow WorkflowConfig, profile *DatasetProfile) []ColumnIssue {
+	columns := make(map[string]ColumnProfile, len(profile.Columns))
+	for _, column := range profile.Columns {
+		columns[column.Name] = column
+	}
+
+	nodes := append([]Node(nil), workflow.Nodes...)
+	sort.SliceStable(nodes, func(i, j int) bool { return nodes[i].Stage < nodes[j].Stage })
+
+	var issues []ColumnIssue
+	produced := map[string]bool{}
+	for _, node := range nodes {
+		names := make([]string, 0, len(node.Variables))
+		for name := range node.Variables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if isOutputColumnVariable(name) || !isColumnVariable(name) {
+				continue
+			}
+			for _, column := range columnNames(node.Variables[name]) {
+				if _, ok := columns[column]; !ok && !produced[column] {
+					issues = append(issues, missingColumn(profile, node.ID, "variables."+name, column))
+				}
+			}
+		}
+		for _, name := range names {
+			if isOutputColumnVariable(name) {
+				for _, column := range columnNames(node.Variables[name]) {
+					produced[column] = true
+				}
+			}
+		}
+	}
+
+	// Clustering drops the target when the data has it, but needs none;
+	// the generated code runs without the default target when no node
+	// uses it
+	if workflow.TaskType == TaskClustering || (strings.TrimSpace(workflow.TargetColumn) == "" && !usesTarget(workflow)) {
+		return issues
+	}
+	for _, target := range splitColumns(workflow.Target()) {
+		column, ok := columns[target]
+		if !ok && produced[target] {
+			continue
+		}
+		if !ok {
+			issue := missingColumn(profile, "", "target_column", target)
+			if issue.Suggestion == "" {
+				issue.Suggestion = suggestedTarget(profile, workflow.TaskType)
+				if issue.Suggestion != "" {
+					issue.Message = fmt.Sprintf("column %q is not in the dataset; %q looks like its target", target, issue.Suggestion)
+				}
+			}
+			issues = append(issues, issue)
+			continue
+		}
+		if needsNumericTarget(workflow.TaskType) && column.Type != ColumnInt && column.Type != ColumnFloat {
+			issues = append(issues, ColumnIssue{
+				Field:   "target_column",
+				Column:  target,
+				Message: fmt.Sprintf("column %q is %s, a %s target must be numeric", target, column.Type, workflow.TaskType),
+			})
+		}
+	}
+	return issues
+}
+
+// usesTarget reports whether a node of the workflow splits off, trains on
+// or evaluates against the target
+func usesTarget(workflow WorkflowConfig) bool {
+	for _, node := range workflow.Nodes {
+		_, funcName := componentNames(node)
+		switch role, _ := nodeRole(node, funcName); role {
+		case RoleSplitter, RoleTrainer, RoleEvaluator, RoleCrossValidator:
+			return true
+		}
+	}
+	return false
+}
+
+// outputColumnPrefixes start the names of variables naming the columns a
+// node adds, such as new_column or output_columns
+var outputColumnPrefixes = []string{"new_", "output_", "out_", "result_", "added_"}
+
+// isOutputColumnVariable reports whether a node variable names columns the
+// node adds
+func isOutputColumnVariable(name string) bool {
+	name = strings.ToLower(name)
+	if !strings.HasSuffix(name, "_column") && !strings.HasSuffix(name, "_columns") {
+		return false
+	}
+	for _, prefix := range outputColumnPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isColumnVariable reports whether a node variable holds column names,
+// going by its name: the type registry has no column type, column inputs
+// are declared as str or list
+func isColumnVariable(name string) bool {
+	name = strings.ToLower(name)
+	return name == "column" || name == "columns" ||
+		strings.HasSuffix(name, "_column") || strings.HasSuffix(name, "_columns")
+}
+
+// columnNames returns the column names in a variable value: a Python
+// string, list or tuple literal, a JSON list, or comma-separated text.
+// Values that are not names, such as None, indexes and placeholders, give
+// none.
+func columnNames(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		if strings.Contains(v, "{{") {
+			return nil
+		}
+		parsed, err := parsePythonLiteral(v)
+		if err != nil {
+			return splitColumns(v)
+		}
+		return columnNames(parsed)
+	case []interface{}:
+		return stringValues(v)
+	case pyTuple:
+		return stringValues(v)
+	}
+	return nil
+}
+
+func stringValues(values []interface{}) []string {
+	var names []string
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			names = append(names, s)
+		}
+	}
+	return names
+}
+
+// splitColumns splits comma-separated column names
+func splitColumns(s string) []string {
+	var names []string
+	for _, name := range strings.Split(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func missingColumn(profile *DatasetProfile, nodeID, field, column string) ColumnIssue {
+	issue := ColumnIssue{NodeID: nodeID, Field: field, Column: column}
+	if suggestion := closestColumn(profile, column); suggestion != "" {
+		issue.Suggestion = suggestion
+		issue.Message = fmt.Sprintf("column %q is not in the dataset; did you mean %q?", column, suggestion)
+		return issue
+	}
+
+	names := make([]string, 0, maxListedColumns)
+	for _, c := range profile.Columns {
+		if len(names) == maxListedColumns {
+			names = append(names, "...")
+			break
+		}
+		names = append(names, c.Name)
+	}
+	issue.Message = fmt.Sprintf("column %q is not in the dataset, which has: %s", column, strings.Join(names, ", "))
+	return issue
+}
+
+// closestColumn returns the dataset column with the smallest edit distance
+// to name, ignoring case, or "" when none is within a third of its length
+func closestColumn(profile *DatasetProfile, name string) string {
+	best, bestDistance := "", max(1, len(name)/3)+1
+	for _, column := range profile.Columns {
+		if d := levenshtein(strings.ToLower(name), strings.ToLower(column.Name)); d < bestDistance {
+			best, bestDistance = column.Name, d
+		}
+	}
+	return best
+}
+
+// suggestedTarget returns the profile's most likely target that suits the
+// task type
+func suggestedTarget(profile *DatasetProfile, taskType string) string {
+	if taskType == "" {
+		taskType = DefaultTaskType
+	}
+	for _, candidate := range profile.TargetCandidates {
+		suggested := candidate.TaskType
+		if suggested == taskType || (suggested == TaskRegression && taskType == TaskForecasting) {
+			return candidate.Column
+		}
+	}
+	return ""
+}
+
+func needsNumericTarget(taskType string) bool {
+	return taskType == TaskRegression || taskType == TaskForecasting
+}
+
+// levenshtein returns the number of single-character edits between a and b
+func levenshtein(a, b string) int {
+	s, t := []rune(a), []rune(b)
+	prev := make([]int, len(t)+1)
+	curr := make([]int, len(t)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		curr[0] = i
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(t)]
+}
@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+func columnProfile(t *testing.T) *DatasetProfile {
+	t.Helper()
+	profile, err := ProfileCSV(strings.NewReader(profileCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profile
+}
+
+func TestCheckColumnsVariables(t *testing.T) {
+	workflow := WorkflowConfig{
+		TargetColumn: "churn",
+		Nodes: []Node{{
+			ID: "scale",
+			Variables: map[string]interface{}{
+				"column":       "Age",
+				"columns":      "['income', 'citty']",
+				"drop_columns": "id, notes",
+				"n_columns":    "3",
+				"key_column":   "None",
+				"other_column": "{{column}}",
+				"threshold":    "agee",
+			},
+		}, {
+			ID:     "encode",
+			Inputs: []Input{{Name: "feature_columns", Type: TypeList}, {Name: "features", Type: TypeList}},
+			Variables: map[string]interface{}{
+				"feature_columns": []interface{}{"member", "postcode"},
+				"features":        []interface{}{"not", "columns"},
+			},
+		}},
+	}
+
+	issues := CheckColumns(workflow, columnProfile(t))
+	want := []ColumnIssue{
+		{NodeID: "scale", Field: "variables.column", Column: "Age", Suggestion: "age"},
+		{NodeID: "scale", Field: "variables.columns", Column: "citty", Suggestion: "city"},
+		{NodeID: "encode", Field: "variables.feature_columns", Column: "postcode"},
+	}
+	if len(issues) != len(want) {
+		t.Fatalf("CheckColumns = %+v, want %d issues", issues, len(want))
+	}
+	for i, issue := range issues {
+		w := want[i]
+		if issue.NodeID != w.NodeID || issue.Field != w.Field || issue.Column != w.Column || issue.Suggestion != w.Suggestion {
+			t.Errorf("issue %d = %+v, want %+v", i, issue, w)
+		}
+	}
+	if !strings.Contains(issues[0].Message, `did you mean "age"?`) {
+		t.Errorf("message = %q", issues[0].Message)
+	}
+	if !strings.Contains(issues[2].Message, "which has: id, age, income") {
+		t.Errorf("message = %q", issues[2].Message)
+	}
+}
+
+func TestCheckColumnsTarget(t *testing.T) {
+	tests := []struct {
+		name       string
+		workflow   WorkflowConfig
+		column     string
+		suggestion string
+		message    string
+	}{
+		{
+			name:       "default target falls back to the likely target",
+			workflow:   WorkflowConfig{Nodes: []Node{{ID: "fit", Stage: 3, Role: RoleTrainer, Code: "train_model"}}},
+			column:     "target",
+			suggestion: "churn",
+			message:    `"churn" looks like its target`,
+		},
+		{
+			name:       "misspelled multi-output target",
+			workflow:   WorkflowConfig{TargetColumn: "churn, incme"},
+			column:     "incme",
+			suggestion: "income",
+		},
+		{
+			name:     "regression needs a numeric target",
+			workflow: WorkflowConfig{TaskType: TaskRegression, TargetColumn: "city"},
+			column:   "city",
+			message:  "a regression target must be numeric",
+		},
+		{
+			name:     "valid target",
+			workflow: WorkflowConfig{TaskType: TaskRegression, TargetColumn: "income"},
+		},
+		{
+			name:     "clustering needs no target",
+			workflow: WorkflowConfig{TaskType: TaskClustering},
+		},
+		{
+			name:     "default target of a workflow that does not use it",
+			workflow: WorkflowConfig{Nodes: []Node{{ID: "scale", Stage: 2, Role: RoleTransform, Code: "scale_features"}}},
+		},
+		{
+			name: "target added upstream",
+			workflow: WorkflowConfig{TargetColumn: "label", Nodes: []Node{
+				{ID: "label", Stage: 1, Code: "label_rows", Variables: map[string]interface{}{"new_column": "label"}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			issues := CheckColumns(tt.workflow, columnProfile(t))
+			if tt.column == "" {
+				if len(issues) != 0 {
+					t.Fatalf("CheckColumns = %+v, want no issues", issues)
+				}
+				return
+			}
+			if len(issues) != 1 {
+				t.Fatalf("CheckColumns = %+v, want one issue", issues)
+			}
+			issue := issues[0]
+			if issue.Field != "target_column" || issue.Column != tt.column || issue.Suggestion != tt.suggestion {
+				t.Errorf("issue = %+v", issue)
+			}
+			if !strings.Contains(issue.Message, tt.message) {
+				t.Errorf("message = %q, want it to contain %q", issue.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestCheckColumnsOutputColumns(t *testing.T) {
+	workflow := WorkflowConfig{
+		TargetColumn: "churn",
+		Nodes: []Node{{
+			ID:    "ratio",
+			Stage: 2,
+			Variables: map[string]interface{}{
+				"output_column": "debt_ratio",
+				"uses_column":   "age_group", // added in stage 1
+			},
+		}, {
+			ID:    "bucket",
+			Stage: 1,
+			Variables: map[string]interface{}{
+				"column":           "age",
+				"new_columns":      "['age_group', 'age_decile']",
+				"reference_column": "debt_ratio", // only added in stage 2
+			},
+		}},
+	}
+
+	issues := CheckColumns(workflow, columnProfile(t))
+	if len(issues) != 1 || issues[0].NodeID != "bucket" || issues[0].Field != "variables.reference_column" || issues[0].Column != "debt_ratio" {
+		t.Errorf("CheckColumns = %+v, want only the reference to a column added later", issues)
+	}
+}
+
+func TestLevenshtein(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"target", "target", 0},
+		{"größe", "grösse", 2},
+	}
+	for _, tt := range tests {
+		if got := levenshtein(tt.a, tt.b); got != tt.want {
+			t.Errorf("levenshtein(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
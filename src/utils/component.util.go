@@ -0,0 +1,227 @@
+// src/utils/component.util.go
+package utils
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+)
+
+// requirementPattern matches a pip requirement such as "pandas" or "scikit-learn==1.5.2"
+var requirementPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*(\[[A-Za-z0-9,._-]+\])?\s*((==|!=|<=|>=|~=|<|>)\s*[A-Za-z0-9.*+!_-]+\s*)?$`)
+
+// IndexComponentReferences records the function the component's code
+// defines and the functions it calls, so that the components it relies on
+// and the components relying on it can be looked up
+func IndexComponentReferences(c *models.Component) {
+	c.Function = ""
+	if language, ok := GetLanguage(c.Language); ok {
+		c.Function = language.FunctionName(c.Code)
+	}
+	c.Calls = nil
+	for _, name := range FunctionCalls(c.Language, c.Code) {
+		if name != c.Function {
+			c.Calls = append(c.Calls, name)
+		}
+	}
+}
+
+// NormalizeComponentTypes replaces type aliases of the inputs and output
+// with the canonical type names
+func NormalizeComponentTypes(c *models.Component) {
+	for i := range c.Inputs {
+		c.Inputs[i].Type = CanonicalType(c.Inputs[i].Type)
+	}
+	if c.Output != nil {
+		c.Output.Type = CanonicalType(c.Output.Type)
+	}
+}
+
+// ValidateComponent checks the component and returns one FieldError per problem
+func ValidateComponent(c *models.Component) []apperror.FieldError {
+	var fields []apperror.FieldError
+
+	if strings.TrimSpace(c.Name) == "" {
+		fields = append(fields, apperror.Field("name", "required", "is required"))
+	}
+	if strings.TrimSpace(c.Code) == "" {
+		fields = append(fields, apperror.Field("code", "required", "is required"))
+	}
+	if strings.TrimSpace(c.Language) == "" {
+		fields = append(fields, apperror.Field("language", "required", "is required"))
+	} else if !IsValidLanguage(c.Language) {
+		fields = append(fields, apperror.Field("language", "oneof",
+			fmt.Sprintf("invalid language %q, must be one of: %s", c.Language, strings.Join(Languages, ", "))))
+	}
+	stage, stageOK := GetStage(c.Stage)
+	if !stageOK {
+		fields = append(fields, apperror.Field("stage", "oneof",
+			fmt.Sprintf("must be one of: %s", strings.Join(StageNames(), ", "))))
+	}
+	if strings.TrimSpace(c.Role) == "" {
+		fields = append(fields, apperror.Field("role", "required",
+			fmt.Sprintf("is required, must be one of: %s", strings.Join(Roles, ", "))))
+	} else if !IsValidRole(c.Role) {
+		fields = append(fields, apperror.Field("role", "oneof",
+			fmt.Sprintf("invalid role %q, must be one of: %s", c.Role, strings.Join(Roles, ", "))))
+	} else if stageOK && !stage.AllowsRole(c.Role) {
+		fields = append(fields, apperror.Field("role", "stage_role",
+			fmt.Sprintf("role %q is not allowed in %s, must be one of: %s", c.Role, stage.Name, strings.Join(stage.Roles, ", "))))
+	}
+
+	if len(c.Inputs) == 0 {
+		fields = append(fields, apperror.Field("inputs", "min", "must have at least one input"))
+	}
+	for i, input := range c.Inputs {
+		if strings.TrimSpace(input.Name) == "" {
+			fields = append(fields, apperror.Field(fmt.Sprintf("inputs[%d].name", i), "required", "is required"))
+		}
+		if !IsValidInputType(input.Type) {
+			fields = append(fields, apperror.Field(fmt.Sprintf("inputs[%d].type", i), "oneof",
+				fmt.Sprintf("invalid input type %q, must be one of: %s", input.Type, strings.Join(InputTypeNames(), ", "))))
+		}
+	}
+
+	if c.Output != nil && !IsValidOutputType(c.Output.Type) {
+		fields = append(fields, apperror.Field("output.type", "oneof",
+			fmt.Sprintf("invalid output type %q, must be one of: %s", c.Output.Type, strings.Join(OutputTypeNames(), ", "))))
+	}
+
+	// Declared types must fit the annotations of the Python function
+	if c.Language == LanguagePython {
+		for _, conflict := range TypeHintConflicts(c.Code, c.InputTypes()) {
+			for i, input := range c.Inputs {
+				if input.Name == conflict.Input {
+					fields = append(fields, apperror.Field(fmt.Sprintf("inputs[%d].type", i), "type_hint",
+						fmt.Sprintf("is %s but the code annotates %s as %s", conflict.Declared, conflict.Input, conflict.Hint)))
+				}
+			}
+		}
+	}
+
+	fields = append(fields, validateComponentTests(c)...)
+
+	for i, dependency := range c.Dependencies {
+		if !requirementPattern.MatchString(dependency) {
+			fields = append(fields, apperror.Field(fmt.Sprintf("dependencies[%d]", i), "requirement",
+				fmt.Sprintf("invalid requirement %q, expected a package name with an optional version such as pandas==2.2.3", dependency)))
+		}
+	}
+
+	return fields
+}
+
+// validateComponentTests checks the component's tests; they run Python
+// components only and pass parameters of the declared inputs
+func validateComponentTests(c *models.Component) []apperror.FieldError {
+	if len(c.Tests) == 0 {
+		return nil
+	}
+	if c.Language != LanguagePython {
+		return []apperror.FieldError{apperror.Field("tests", "language", "tests run Python components only")}
+	}
+
+	var fields []apperror.FieldError
+	names := map[string]bool{}
+	for i, test := range c.Tests {
+		prefix := fmt.Sprintf("tests[%d].", i)
+		if strings.TrimSpace(test.Name) == "" {
+			fields = append(fields, apperror.Field(prefix+"name", "required", "is required"))
+		} else if names[test.Name] {
+			fields = append(fields, apperror.Field(prefix+"name", "unique", fmt.Sprintf("duplicate test name %q", test.Name)))
+		}
+		names[test.Name] = true
+
+		if test.Expect.Frame != nil && c.Output != nil && !IsSubtype(c.Output.Type, TypeDataFrame) && !IsSubtype(TypeDataFrame, c.Output.Type) {
+			fields = append(fields, apperror.Field(prefix+"expect.frame", "type",
+				fmt.Sprintf("the component returns %s, not a DataFrame", CanonicalType(c.Output.Type))))
+		}
+		frames := map[string]*TestFrame{"input": test.Input, "expect.frame": test.Expect.Frame}
+		for _, field := range []string{"input", "expect.frame"} {
+			if frame := frames[field]; frame != nil && !slices.Contains(TestFrameFormats, frame.Format) {
+				fields = append(fields, apperror.Field(prefix+field+".format", "oneof",
+					fmt.Sprintf("invalid format %q, must be one of: %s", frame.Format, strings.Join(TestFrameFormats, ", "))))
+			}
+		}
+		params := make([]string, 0, len(test.Params))
+		for name := range test.Params {
+			params = append(params, name)
+		}
+		sort.Strings(params)
+		for _, name := range params {
+			value := test.Params[name]
+			input, ok := c.Input(name)
+			if !ok {
+				fields = append(fields, apperror.Field(prefix+"params."+name, "input", fmt.Sprintf("%q is not an input of the component", name)))
+			} else if _, err := PythonLiteral(value, input.Type); err != nil {
+				fields = append(fields, apperror.Field(prefix+"params."+name, "type", err.Error()))
+			}
+		}
+		if test.Expect.IsEmpty() {
+			fields = append(fields, apperror.Field(prefix+"expect", "required", "must set shape, columns, frame or value"))
+		}
+		if test.Expect.Tolerance < 0 {
+			fields = append(fields, apperror.Field(prefix+"expect.tolerance", "min", "must not be negative"))
+		}
+	}
+	return fields
+}
+
+// CheckComponentSyntax compiles the component's code without running it
+// and returns one FieldError per syntax error, located at its line and
+// column. Components without code or with an unknown language are left to
+// ValidateComponent.
+func CheckComponentSyntax(ctx context.Context, c *models.Component) []apperror.FieldError {
+	if strings.TrimSpace(c.Code) == "" || !IsValidLanguage(c.Language) {
+		return nil
+	}
+	check, err := CheckSyntax(ctx, c.Language, c.Code)
+	if err != nil {
+		return nil
+	}
+	slog.DebugContext(ctx, "checked component syntax", "component", c.Name, "checker", check.Checker, "diagnostics", len(check.Diagnostics))
+
+	var fields []apperror.FieldError
+	for _, d := range check.Diagnostics {
+		fields = append(fields, apperror.Field("code", "syntax", d.String()).At(d.Line, d.Column))
+	}
+	return fields
+}
+
+// Bounds of checking the components of one request: at most
+// syntaxCheckWorkers checker commands run at once, and the components left
+// once syntaxCheckBudget is spent get the in-process check
+const syntaxCheckWorkers = 4
+
+var syntaxCheckBudget = 15 * time.Second
+
+// CheckComponentsSyntax checks each component as CheckComponentSyntax does
+// and returns their errors in order
+func CheckComponentsSyntax(ctx context.Context, components []models.Component) [][]apperror.FieldError {
+	ctx, cancel := context.WithTimeout(ctx, syntaxCheckBudget)
+	defer cancel()
+
+	results := make([][]apperror.FieldError, len(components))
+	workers := make(chan struct{}, syntaxCheckWorkers)
+	var wg sync.WaitGroup
+	for i := range components {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-workers }()
+			results[i] = CheckComponentSyntax(ctx, &components[i])
+		}(i)
+	}
+	wg.Wait()
+	return results
+}
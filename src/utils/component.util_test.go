@@ -0,0 +1,69 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"builder.ai/src/models"
+)
+
+func TestValidateComponentTestsLanguage(t *testing.T) {
+	tests := []struct {
+		language, code string
+		want           []string
+	}{
+		{LanguagePython, "def drop_sparse(df):\n    return df\n", nil},
+		{LanguageJavaScript, "function dropSparse(df) { return df }", []string{"tests: tests run Python components only"}},
+		{LanguageGo, "func DropSparse(df DataFrame) DataFrame { return df }", []string{"tests: tests run Python components only"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			component := models.Component{
+				Name:     "Drop sparse",
+				Code:     tt.code,
+				Language: tt.language,
+				Stage:    "stage1",
+				Role:     RoleTransform,
+				Inputs:   []models.ComponentInput{{Name: "df", Type: TypeDataFrame}},
+				Tests:    models.ComponentTests{{Name: "keeps rows", Expect: models.TestExpectation{Value: 1.0}}},
+			}
+			var got []string
+			for _, field := range ValidateComponent(&component) {
+				got = append(got, field.Field+": "+field.Message)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ValidateComponent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckComponentsSyntax(t *testing.T) {
+	components := make([]models.Component, 9)
+	for i := range components {
+		components[i] = models.Component{Language: LanguagePython, Code: "def f(df):\n    return df\n"}
+	}
+	components[5].Code = "def f(x:\n    return x\n"
+
+	check := func() {
+		t.Helper()
+		results := CheckComponentsSyntax(context.Background(), components)
+		for i, fields := range results {
+			if wantErr := i == 5; (len(fields) > 0) != wantErr || wantErr && fields[0].Line != 1 {
+				t.Errorf("results[%d] = %v", i, fields)
+			}
+		}
+	}
+	check()
+
+	// Once the budget is spent the in-process check takes over
+	defer func(budget time.Duration) { syntaxCheckBudget = budget }(syntaxCheckBudget)
+	syntaxCheckBudget = 0
+	start := time.Now()
+	check()
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("checks without budget took %s", elapsed)
+	}
+}
@@ -0,0 +1,324 @@
+// src/utils/componentTest.util.go
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"builder.ai/src/models"
+)
+
+// The test types are defined in models, where components store them
+type (
+	ComponentTest       = models.ComponentTest
+	TestFrame           = models.TestFrame
+	TestExpectation     = models.TestExpectation
+	ComponentTestResult = models.ComponentTestResult
+	ComponentTestRun    = models.ComponentTestRun
+)
+
+// Inline DataFrame formats
+const (
+	TestFrameCSV  = models.TestFrameCSV
+	TestFrameJSON = models.TestFrameJSON
+)
+
+// TestFrameFormats lists the inline DataFrame formats
+var TestFrameFormats = models.TestFrameFormats
+
+// DefaultTestTolerance is the absolute tolerance of expectations without one
+const DefaultTestTolerance = 1e-9
+
+// ComponentTestResultsDir is where the executor mounts the directory the
+// test script writes ComponentTestResultsFile to
+const (
+	ComponentTestResultsDir  = "/results"
+	ComponentTestResultsFile = "results.json"
+)
+
+// GenerateComponentTests renders a Python script that runs the tests of a
+// component and writes their results to the file named by its argument,
+// ComponentTestResultsDir/ComponentTestResultsFile by default, to be read
+// with ParseComponentTestResults. The script must start as root: each test
+// calls the component in a child process running as nobody, which can
+// neither write the results nor reach the process checking them.
+// inputTypes are the declared types of the component's inputs by name.
+func GenerateComponentTests(componentName, code string, inputTypes map[string]string, tests []ComponentTest) (string, error) {
+	funcName := FunctionName(code)
+	if !isPythonName(funcName) {
+		return "", &CodeError{Language: LanguagePython, Message: "defines no function to test"}
+	}
+
+	var calls []string
+	for i, test := range tests {
+		call, err := componentTestCall(funcName, inputTypes, test)
+		if err != nil {
+			return "", fmt.Errorf("tests[%d]: %w", i, err)
+		}
+		if test.Expect.Tolerance == 0 {
+			test.Expect.Tolerance = DefaultTestTolerance
+		}
+		expect, err := json.Marshal(test.Expect)
+		if err != nil {
+			return "", fmt.Errorf("tests[%d].expect: %w", i, err)
+		}
+		calls = append(calls, fmt.Sprintf("_run(%s, %s, json.loads(%s))", pyQuote(test.Name), pyQuote(call), pyQuote(string(expect))))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env python3\n")
+	fmt.Fprintf(&sb, "# Tests of the component: %s\n", commentText(componentName))
+	sb.WriteString(componentTestPrelude)
+	sb.WriteString("\n# " + strings.Repeat("=", 60) + "\n# COMPONENT\n# " + strings.Repeat("=", 60) + "\n\n")
+	fmt.Fprintf(&sb, "_RESULTS = sys.argv[1] if len(sys.argv) > 1 else %s\n", pyQuote(ComponentTestResultsDir+"/"+ComponentTestResultsFile))
+	fmt.Fprintf(&sb, "_COMPONENT = %s\n\n", pyQuote(strings.Trim(code, "\n")+"\n"))
+	sb.WriteString(componentTestHelpers)
+	sb.WriteString("\n")
+	for _, call := range calls {
+		sb.WriteString(call + "\n")
+	}
+	sb.WriteString("\nwith open(_RESULTS, 'w') as f:\n    json.dump(_results, f)\n")
+	return sb.String(), nil
+}
+
+// componentTestCall renders the call of the component for a test
+func componentTestCall(funcName string, inputTypes map[string]string, test ComponentTest) (string, error) {
+	var args []string
+	if test.Input != nil {
+		frame, err := testFrameLiteral(*test.Input)
+		if err != nil {
+			return "", fmt.Errorf("input: %w", err)
+		}
+		args = append(args, frame)
+	}
+
+	names := make([]string, 0, len(test.Params))
+	for name := range test.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !isPythonName(name) {
+			return "", fmt.Errorf("params.%s: not a valid Python argument name", name)
+		}
+		literal, err := PythonLiteral(test.Params[name], inputTypes[name])
+		if err != nil {
+			return "", fmt.Errorf("params.%s: %w", name, err)
+		}
+		args = append(args, name+"="+literal)
+	}
+	return fmt.Sprintf("%s(%s)", funcName, strings.Join(args, ", ")), nil
+}
+
+// testFrameLiteral renders the Python expression reading an inline DataFrame
+func testFrameLiteral(frame TestFrame) (string, error) {
+	switch frame.Format {
+	case TestFrameCSV:
+		return fmt.Sprintf("pd.read_csv(io.StringIO(%s))", pyQuote(frame.Data)), nil
+	case TestFrameJSON:
+		var records []map[string]interface{}
+		if err := json.Unmarshal([]byte(frame.Data), &records); err != nil {
+			return "", fmt.Errorf("data must be a JSON array of records: %w", err)
+		}
+		return fmt.Sprintf("pd.DataFrame(json.loads(%s))", pyQuote(frame.Data)), nil
+	}
+	return "", fmt.Errorf("invalid format %q, must be one of: %s", frame.Format, strings.Join(TestFrameFormats, ", "))
+}
+
+// ParseComponentTestResults reads the results file written by a script of
+// GenerateComponentTests; ok is false when the file holds no results, e.g.
+// because the script failed before writing it. The output of the script is
+// never read for results, as the component's code shares it.
+func ParseComponentTestResults(data []byte) ([]ComponentTestResult, bool) {
+	var results []ComponentTestResult
+	if err := json.Unmarshal(data, &results); err != nil || results == nil {
+		return nil, false
+	}
+	return results, true
+}
+
+// NewComponentTestRun summarizes the results of running tests; a run
+// passes when every test ran and passed
+func NewComponentTestRun(tests []ComponentTest, results []ComponentTestResult, ranAt time.Time) ComponentTestRun {
+	run := ComponentTestRun{RanAt: ranAt, Passed: len(results) == len(tests) && len(tests) > 0, Results: results}
+	for _, result := range results {
+		run.Passed = run.Passed && result.Passed
+	}
+	return run
+}
+
+const componentTestPrelude = `
+import io
+import json
+import math
+import os
+import signal
+import sys
+import time
+
+import pandas as pd
+
+if os.geteuid() != 0:
+    sys.exit('component tests must start as root to run the component as nobody')
+`
+
+// componentTestHelpers runs each test in a child process without
+// privileges and compares its result with the expectation, recording a
+// message for the first difference. Only the child runs the component's
+// code; it sends the parent the result as JSON through a pipe.
+const componentTestHelpers = `# ============================================================
+# TESTS
+# ============================================================
+
+_results = []
+_NOBODY = 65534
+_SCOPE = {'__name__': 'component', 'io': io, 'json': json, 'math': math, 'time': time, 'pd': pd}
+
+
+def _plain(value):
+    """Converts pandas and numpy results to plain Python values"""
+    if isinstance(value, pd.DataFrame):
+        return value.to_dict(orient='records')
+    if hasattr(value, 'tolist'):
+        return value.tolist()
+    if hasattr(value, 'item'):
+        return value.item()
+    if isinstance(value, tuple):
+        return [_plain(v) for v in value]
+    if isinstance(value, list):
+        return [_plain(v) for v in value]
+    if isinstance(value, dict):
+        return {str(k): _plain(v) for k, v in value.items()}
+    return value
+
+
+def _encode(result):
+    """Describes a result of the component for the parent process"""
+    encoded = {
+        'type': type(result).__name__,
+        'shape': [int(n) for n in getattr(result, 'shape', ())],
+        'columns': [str(c) for c in getattr(result, 'columns', [])],
+        'value': _plain(result),
+    }
+    if isinstance(result, pd.DataFrame):
+        encoded['frame'] = result.reset_index(drop=True).to_json(orient='split', date_format='iso')
+    return encoded
+
+
+def _close(actual, expected, tolerance):
+    if isinstance(expected, bool) or isinstance(actual, bool):
+        return actual == expected
+    if isinstance(expected, (int, float)) and isinstance(actual, (int, float)):
+        if isinstance(actual, float) and math.isnan(actual):
+            return isinstance(expected, float) and math.isnan(expected)
+        return abs(actual - expected) <= tolerance
+    if isinstance(expected, list) and isinstance(actual, list):
+        return len(actual) == len(expected) and all(_close(a, e, tolerance) for a, e in zip(actual, expected))
+    if isinstance(expected, dict) and isinstance(actual, dict):
+        return actual.keys() == expected.keys() and all(_close(actual[k], expected[k], tolerance) for k in expected)
+    return actual == expected
+
+
+def _check(result, expect):
+    tolerance = expect['tolerance']
+    if 'shape' in expect and result['shape'] != expect['shape']:
+        return f'shape {result["shape"]} differs from the expected {expect["shape"]}'
+    if 'columns' in expect and result['columns'] != expect['columns']:
+        return f'columns {result["columns"]} differ from the expected {expect["columns"]}'
+    if 'frame' in expect:
+        frame = expect['frame']
+        if frame['format'] == 'csv':
+            expected = pd.read_csv(io.StringIO(frame['data']))
+        else:
+            expected = pd.DataFrame(json.loads(frame['data']))
+        if 'frame' not in result:
+            return f'returned {result["type"]}, expected a DataFrame'
+        actual = pd.read_json(io.StringIO(result['frame']), orient='split', convert_dates=False)
+        try:
+            pd.testing.assert_frame_equal(actual, expected, check_dtype=False,
+                                          check_exact=False, atol=tolerance, rtol=0)
+        except AssertionError as e:
+            return 'DataFrame differs: ' + ' '.join(str(e).split())
+    if 'value' in expect and not _close(result['value'], expect['value'], tolerance):
+        return f'returned {result["value"]!r}, expected {expect["value"]!r}'
+    return None
+
+
+def _call(call):
+    """Runs the component in a child process as nobody and returns the
+    encoded result of the call, or {'error': message}"""
+    read_fd, write_fd = os.pipe()
+    pid = os.fork()
+    if pid == 0:
+        os.close(read_fd)
+        try:
+            os.setpgid(0, 0)
+            os.setgroups([])
+            os.setgid(_NOBODY)
+            os.setuid(_NOBODY)
+            exec(compile(_COMPONENT, 'component.py', 'exec'), _SCOPE)
+            payload = json.dumps(_encode(eval(call, _SCOPE)), default=repr)
+        except BaseException as e:
+            payload = json.dumps({'error': f'raised {type(e).__name__}: {e}'})
+        data = payload.encode()
+        data = len(data).to_bytes(8, 'big') + data
+        while data:
+            data = data[os.write(write_fd, data):]
+        try:
+            sys.stdout.flush()
+            sys.stderr.flush()
+        finally:
+            os._exit(0)
+
+    os.close(write_fd)
+    with os.fdopen(read_fd, 'rb') as f:
+        header = f.read(8)
+        size = int.from_bytes(header, 'big') if len(header) == 8 else 0
+        data = f.read(size)
+    _, status = os.waitpid(pid, 0)
+    _reap(pid)
+    if os.waitstatus_to_exitcode(status) != 0:
+        return {'error': f'the component exited with status {os.waitstatus_to_exitcode(status)}'}
+    if len(data) != size or size == 0:
+        return {'error': 'the component exited without returning'}
+    try:
+        return json.loads(data)
+    except ValueError:
+        return {'error': 'the component sent an unreadable result'}
+
+
+def _reap(pid):
+    """Kills the processes the component left running. In a container the
+    harness is init and kills every other process."""
+    try:
+        if os.getpid() == 1:
+            os.kill(-1, signal.SIGKILL)
+        else:
+            os.killpg(pid, signal.SIGKILL)
+    except ProcessLookupError:
+        pass
+    while True:
+        try:
+            os.waitpid(-1, 0)
+        except ChildProcessError:
+            break
+
+
+def _run(name, call, expect):
+    start = time.perf_counter()
+    result = _call(call)
+    try:
+        message = result['error'] if 'error' in result else _check(result, expect)
+    except Exception as e:
+        message = f'could not check the result: {type(e).__name__}: {e}'
+    _results.append({
+        'name': name,
+        'passed': message is None,
+        'message': message or '',
+        'duration_ms': round((time.perf_counter() - start) * 1000, 3),
+    })
+
+`
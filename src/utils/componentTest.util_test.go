@@ -0,0 +1,176 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateComponentTests(t *testing.T) {
+	code := "import pandas as pd\n\ndef scale(data, factor=2, columns=None):\n    return data * factor\n"
+	tests := []ComponentTest{
+		{
+			Name:   "doubles",
+			Input:  &TestFrame{Format: TestFrameCSV, Data: "a,b\n1,2\n"},
+			Params: map[string]interface{}{"factor": 2.0, "columns": []interface{}{"a"}},
+			Expect: TestExpectation{Shape: []int{1, 2}, Frame: &TestFrame{Format: TestFrameJSON, Data: `[{"a": 2, "b": 4}]`}},
+		},
+		{
+			Name:   "it's a string",
+			Input:  &TestFrame{Format: TestFrameJSON, Data: `[{"a": 1}]`},
+			Params: map[string]interface{}{"factor": "3"},
+			Expect: TestExpectation{Columns: []string{"a"}, Tolerance: 0.5},
+		},
+	}
+	types := map[string]string{"data": "DataFrame", "factor": "int", "columns": "list"}
+
+	script, err := GenerateComponentTests("Scale", code, types, tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"# Tests of the component: Scale",
+		`_COMPONENT = 'import pandas as pd\n\ndef scale(data, factor=2, columns=None):\n    return data * factor\n'`,
+		`_run('doubles', 'scale(pd.read_csv(io.StringIO(\'a,b\\n1,2\\n\')), columns=[\'a\'], factor=2)', json.loads('{"shape":[1,2],"frame":{"format":"json","data":"[{\\"a\\": 2, \\"b\\": 4}]"},"tolerance":1e-9}'))`,
+		`_run('it\'s a string', 'scale(pd.DataFrame(json.loads(\'[{"a": 1}]\')), factor=3)', json.loads('{"columns":["a"],"tolerance":0.5}'))`,
+		"with open(_RESULTS, 'w') as f:\n    json.dump(_results, f)",
+	} {
+		if !strings.Contains(script, want) {
+			t.Errorf("script does not contain %q", want)
+		}
+	}
+
+	tests[1].Params = map[string]interface{}{"factor": "three"}
+	if _, err := GenerateComponentTests("Scale", code, types, tests); err == nil || !strings.Contains(err.Error(), "tests[1]: params.factor") {
+		t.Errorf("invalid parameter: error = %v", err)
+	}
+	tests[1].Params = nil
+	tests[1].Input.Data = `{"a": 1}`
+	if _, err := GenerateComponentTests("Scale", code, types, tests); err == nil || !strings.Contains(err.Error(), "tests[1]: input: data must be a JSON array") {
+		t.Errorf("invalid JSON frame: error = %v", err)
+	}
+	var codeErr *CodeError
+	if _, err := GenerateComponentTests("Scale", "x = 1\n", types, tests); !errors.As(err, &codeErr) {
+		t.Errorf("code without a function: error = %v, want a *CodeError", err)
+	}
+}
+
+func TestParseComponentTestResults(t *testing.T) {
+	data := `[{"name": "doubles", "passed": true, "message": "", "duration_ms": 1.5},` +
+		` {"name": "halves", "passed": false, "message": "shape [2, 2] differs from the expected [1, 2]", "duration_ms": 0.2}]`
+	results, ok := ParseComponentTestResults([]byte(data))
+	if !ok {
+		t.Fatal("no results parsed")
+	}
+	want := []ComponentTestResult{
+		{Name: "doubles", Passed: true, DurationMs: 1.5},
+		{Name: "halves", Message: "shape [2, 2] differs from the expected [1, 2]", DurationMs: 0.2},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("results = %+v, want %+v", results, want)
+	}
+	for _, data := range []string{"", "null", `[{"name": "doubles", "passed": tru`} {
+		if _, ok := ParseComponentTestResults([]byte(data)); ok {
+			t.Errorf("results parsed from %q", data)
+		}
+	}
+
+	tests := []ComponentTest{{Name: "doubles"}, {Name: "halves"}}
+	ranAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	if run := NewComponentTestRun(tests, results, ranAt); run.Passed || !run.RanAt.Equal(ranAt) {
+		t.Errorf("run with a failed test: %+v", run)
+	}
+	if run := NewComponentTestRun(tests, results[:1], ranAt); run.Passed {
+		t.Error("run missing a result passed")
+	}
+	results[1].Passed = true
+	if run := NewComponentTestRun(tests, results, ranAt); !run.Passed {
+		t.Error("run with every test passing failed")
+	}
+}
+
+// The component runs in a process of its own as nobody: what it prints,
+// writes or signals cannot change the results
+func TestComponentTestsIsolateTheComponent(t *testing.T) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		t.Skip("python3 is not installed")
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("the harness drops privileges, which needs root")
+	}
+
+	// The checks used here need no more of pandas than its DataFrame type
+	stubs := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stubs, "pandas"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stubs, "pandas", "__init__.py"), []byte("class DataFrame:\n    pass\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	code := `import os, sys, signal
+
+def cheat(x, how=''):
+    print('[{"name": "forged", "passed": true, "message": "", "duration_ms": 0}]')
+    if how == 'write':
+        with open(sys.argv[1], 'w') as f:
+            f.write('[{"name": "forged", "passed": true, "message": "", "duration_ms": 0}]')
+    if how == 'kill':
+        os.kill(os.getppid(), signal.SIGKILL)
+    if how == 'exit':
+        os._exit(0)
+    return x * 2
+`
+	tests := []ComponentTest{
+		{Name: "doubles", Params: map[string]interface{}{"x": 2.0}, Expect: TestExpectation{Value: 4.0}},
+		{Name: "is wrong", Params: map[string]interface{}{"x": 2.0}, Expect: TestExpectation{Value: 5.0}},
+		{Name: "writes the results", Params: map[string]interface{}{"x": 2.0, "how": "write"}, Expect: TestExpectation{Value: 4.0}},
+		{Name: "kills the harness", Params: map[string]interface{}{"x": 2.0, "how": "kill"}, Expect: TestExpectation{Value: 4.0}},
+		{Name: "exits", Params: map[string]interface{}{"x": 2.0, "how": "exit"}, Expect: TestExpectation{Value: 4.0}},
+	}
+	script, err := GenerateComponentTests("Cheat", code, map[string]string{"x": "int", "how": "str"}, tests)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	scriptPath := filepath.Join(dir, "script.py")
+	if err := os.WriteFile(scriptPath, []byte(script), 0600); err != nil {
+		t.Fatal(err)
+	}
+	resultsPath := filepath.Join(dir, ComponentTestResultsFile)
+	cmd := exec.Command(python, scriptPath, resultsPath)
+	cmd.Env = append(os.Environ(), "PYTHONPATH="+stubs)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("script failed: %v: %s", err, output)
+	}
+
+	data, err := os.ReadFile(resultsPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	results, ok := ParseComponentTestResults(data)
+	if !ok || len(results) != len(tests) {
+		t.Fatalf("results = %s", data)
+	}
+	for i, want := range []struct {
+		passed  bool
+		message string
+	}{
+		{true, ""},
+		{false, "returned 4, expected 5"},
+		{false, "raised PermissionError"},
+		{false, "raised PermissionError"},
+		{false, "the component exited without returning"},
+	} {
+		if results[i].Name != tests[i].Name || results[i].Passed != want.passed || !strings.HasPrefix(results[i].Message, want.message) {
+			t.Errorf("results[%d] = %+v, want passed %v with message %q", i, results[i], want.passed, want.message)
+		}
+	}
+}
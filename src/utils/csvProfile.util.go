@@ -0,0 +1,405 @@
+// src/utils/csvProfile.util.go
+package utils
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"builder.ai/src/models"
+)
+
+// Column types inferred by ProfileCSV
+const (
+	ColumnInt         = "int"
+	ColumnFloat       = "float"
+	ColumnBool        = "bool"
+	ColumnDatetime    = "datetime"
+	ColumnCategorical = "categorical"
+	ColumnText        = "text"
+)
+
+const (
+	// profileDistinctLimit caps the distinct values counted per column;
+	// beyond it Distinct is a lower bound
+	profileDistinctLimit = 10000
+	// profileSampleSize is how many numeric values per column are kept to
+	// estimate quantiles
+	profileSampleSize = 10000
+	// categoricalMaxDistinct is the most distinct values a column can have
+	// and still be categorical, unless they are under 5% of its values
+	categoricalMaxDistinct = 50
+	// profileTopValues is how many frequent values are reported
+	profileTopValues = 10
+	// classificationMaxDistinct is the most distinct integers a target can
+	// have and still suggest classification
+	classificationMaxDistinct = 20
+)
+
+// The profile types are defined in models, where datasets store them
+type (
+	DatasetProfile  = models.DatasetProfile
+	ColumnProfile   = models.ColumnProfile
+	Quantiles       = models.Quantiles
+	ValueCount      = models.ValueCount
+	TargetCandidate = models.TargetCandidate
+)
+
+// nullValues are the cells treated as missing, compared in lower case
+var nullValues = map[string]bool{"": true, "na": true, "n/a": true, "nan": true, "null": true, "none": true}
+
+// boolValues maps the accepted boolean spellings, compared in lower case
+var boolValues = map[string]bool{"true": true, "false": false, "yes": true, "no": false, "t": true, "f": false}
+
+// datetimeLayouts are the formats a datetime column may use
+var datetimeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+	"2006/01/02",
+	"01/02/2006",
+}
+
+// targetNames are column names that usually hold the target
+var targetNames = []string{"target", "label", "class", "y", "outcome", "response"}
+
+// ProfileCSV reads a CSV file with a header row in one pass and infers the
+// type and statistics of each column. Memory does not grow with the number
+// of rows.
+func ProfileCSV(r io.Reader) (*DatasetProfile, error) {
+	return profileDelimited(r, ',')
+}
+
+// profileDelimited profiles a CSV file separated by comma
+func profileDelimited(r io.Reader, comma rune) (*DatasetProfile, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("empty file")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	p := newProfiler(header)
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		p.addRow(record)
+	}
+	return p.result(), nil
+}
+
+// profiler accumulates the columns of a file read row by row
+type profiler struct {
+	columns []*columnStats
+	rows    int
+}
+
+func newProfiler(header []string) *profiler {
+	p := &profiler{}
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		if i == 0 {
+			name = strings.TrimPrefix(name, "\ufeff")
+		}
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		p.addColumn(name)
+	}
+	return p
+}
+
+// addColumn adds a column, missing from the rows read so far
+func (p *profiler) addColumn(name string) int {
+	column := newColumnStats(name, uint64(len(p.columns)))
+	column.nulls = p.rows
+	p.columns = append(p.columns, column)
+	return len(p.columns) - 1
+}
+
+// addRow adds the cells of a row in column order; short rows are missing
+// their last cells
+func (p *profiler) addRow(values []string) {
+	p.rows++
+	for i, column := range p.columns {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		column.add(value)
+	}
+}
+
+func (p *profiler) result() *DatasetProfile {
+	profile := &DatasetProfile{Rows: p.rows, Columns: make([]ColumnProfile, len(p.columns))}
+	for i, column := range p.columns {
+		profile.Columns[i] = column.profile()
+	}
+	profile.TargetCandidates = targetCandidates(profile)
+	return profile
+}
+
+// columnStats accumulates one column while the file is read
+type columnStats struct {
+	name     string
+	nulls    int
+	values   int
+	counts   map[string]int
+	capped   bool
+	isInt    bool
+	isFloat  bool
+	isBool   bool
+	isTime   bool
+	min, max float64
+	sum      float64
+	earliest time.Time
+	latest   time.Time
+	sample   []float64
+	rng      *rand.Rand
+}
+
+func newColumnStats(name string, seed uint64) *columnStats {
+	return &columnStats{
+		name:    name,
+		counts:  map[string]int{},
+		isInt:   true,
+		isFloat: true,
+		isBool:  true,
+		isTime:  true,
+		min:     math.Inf(1),
+		max:     math.Inf(-1),
+		// A fixed seed keeps the profile of a file stable
+		rng: rand.New(rand.NewPCG(seed, 0x5eed)),
+	}
+}
+
+func (s *columnStats) add(raw string) {
+	value := strings.TrimSpace(raw)
+	if nullValues[strings.ToLower(value)] {
+		s.nulls++
+		return
+	}
+	s.values++
+
+	if _, ok := s.counts[value]; ok {
+		s.counts[value]++
+	} else if len(s.counts) < profileDistinctLimit {
+		// Clone so the key does not keep the whole CSV line alive
+		s.counts[strings.Clone(value)] = 1
+	} else {
+		s.capped = true
+	}
+
+	if s.isBool {
+		_, s.isBool = boolValues[strings.ToLower(value)]
+	}
+	if s.isInt {
+		_, err := strconv.ParseInt(value, 10, 64)
+		s.isInt = err == nil
+	}
+	if s.isFloat {
+		f, err := strconv.ParseFloat(value, 64)
+		if s.isFloat = err == nil && !math.IsNaN(f) && !math.IsInf(f, 0); s.isFloat {
+			s.addNumber(f)
+		}
+	}
+	if s.isTime {
+		t, ok := parseDatetime(value)
+		if s.isTime = ok; ok {
+			if s.earliest.IsZero() || t.Before(s.earliest) {
+				s.earliest = t
+			}
+			if s.latest.IsZero() || t.After(s.latest) {
+				s.latest = t
+			}
+		}
+	}
+}
+
+// addNumber updates the numeric statistics, keeping a uniform sample of
+// the values for the quantiles
+func (s *columnStats) addNumber(f float64) {
+	s.min = math.Min(s.min, f)
+	s.max = math.Max(s.max, f)
+	s.sum += f
+	if len(s.sample) < profileSampleSize {
+		s.sample = append(s.sample, f)
+	} else if j := s.rng.IntN(s.values); j < profileSampleSize {
+		s.sample[j] = f
+	}
+}
+
+func (s *columnStats) profile() ColumnProfile {
+	column := ColumnProfile{
+		Name:           s.name,
+		Type:           s.columnType(),
+		Nulls:          s.nulls,
+		Distinct:       len(s.counts),
+		DistinctCapped: s.capped,
+	}
+
+	switch column.Type {
+	case ColumnInt, ColumnFloat:
+		mean := s.sum / float64(s.values)
+		column.Min, column.Max, column.Mean = &s.min, &s.max, &mean
+		slices.Sort(s.sample)
+		column.Quantiles = &Quantiles{
+			P25: quantile(s.sample, 0.25),
+			P50: quantile(s.sample, 0.50),
+			P75: quantile(s.sample, 0.75),
+		}
+	case ColumnDatetime:
+		column.Earliest = s.earliest.Format(time.RFC3339)
+		column.Latest = s.latest.Format(time.RFC3339)
+	case ColumnBool, ColumnCategorical:
+		column.TopValues = topValues(s.counts, profileTopValues)
+	}
+	return column
+}
+
+// columnType picks the narrowest type every value fits
+func (s *columnStats) columnType() string {
+	switch {
+	case s.values == 0:
+		return ColumnText
+	case s.isBool:
+		return ColumnBool
+	case s.isInt:
+		return ColumnInt
+	case s.isFloat:
+		return ColumnFloat
+	case s.isTime:
+		return ColumnDatetime
+	case !s.capped && (len(s.counts) <= categoricalMaxDistinct || len(s.counts)*20 <= s.values):
+		return ColumnCategorical
+	default:
+		return ColumnText
+	}
+}
+
+func parseDatetime(value string) (time.Time, bool) {
+	for _, layout := range datetimeLayouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// quantile interpolates linearly between the closest sorted values, as
+// pandas does by default
+func quantile(sorted []float64, q float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := q * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+// topValues returns the n most frequent values, ties in value order
+func topValues(counts map[string]int, n int) []ValueCount {
+	values := make([]ValueCount, 0, len(counts))
+	for value, count := range counts {
+		values = append(values, ValueCount{Value: value, Count: count})
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > n {
+		values = values[:n]
+	}
+	return values
+}
+
+// targetCandidates ranks the columns that could be the target: columns
+// named like one first, then the last column, which is where most datasets
+// keep it. Identifiers, free text, datetimes and mostly empty columns are
+// never suggested.
+func targetCandidates(profile *DatasetProfile) []TargetCandidate {
+	candidates := []TargetCandidate{}
+	last := len(profile.Columns) - 1
+	for _, pass := range []string{"named like a target", "last column"} {
+		for i, column := range profile.Columns {
+			if !isTargetLike(column, profile.Rows) {
+				continue
+			}
+			if pass == "last column" && i != last {
+				continue
+			}
+			if pass == "named like a target" && !hasTargetName(column.Name) {
+				continue
+			}
+			if slices.ContainsFunc(candidates, func(c TargetCandidate) bool { return c.Column == column.Name }) {
+				continue
+			}
+			candidates = append(candidates, TargetCandidate{
+				Column:   column.Name,
+				TaskType: suggestedTask(column),
+				Reason:   pass,
+			})
+		}
+	}
+	return candidates
+}
+
+func isTargetLike(column ColumnProfile, rows int) bool {
+	if column.Type == ColumnText || column.Type == ColumnDatetime {
+		return false
+	}
+	if column.Nulls*2 > rows {
+		return false
+	}
+	name := strings.ToLower(column.Name)
+	if name == "id" || strings.HasSuffix(name, "_id") {
+		return false
+	}
+	// A unique integer per row is an identifier, unless there are so few
+	// rows that class labels are unique too
+	return !(column.Type == ColumnInt && rows > classificationMaxDistinct && column.Distinct == rows)
+}
+
+func hasTargetName(name string) bool {
+	name = strings.ToLower(name)
+	for _, target := range targetNames {
+		if name == target || strings.HasSuffix(name, "_"+target) || strings.HasPrefix(name, target+"_") {
+			return true
+		}
+	}
+	return false
+}
+
+func suggestedTask(column ColumnProfile) string {
+	switch {
+	case column.Type == ColumnBool || column.Type == ColumnCategorical:
+		return TaskClassification
+	case column.Type == ColumnInt && column.Distinct <= classificationMaxDistinct:
+		return TaskClassification
+	default:
+		return TaskRegression
+	}
+}
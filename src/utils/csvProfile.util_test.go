@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const profileCSV = `id,age,income,member,joined,city,notes,churn
+1,34,52000.5,yes,2023-01-05,Paris,first visit,0
+2,41,61000,no,2023-02-11,Lyon,,1
+3,NA,48000.25,yes,2023-03-20,Paris,asked about pricing,0
+4,29,,no,2023-04-02,Nice,called twice,1
+5,52,75000,yes,2023-05-17,Paris,n/a,0
+`
+
+func TestProfileCSVInfersColumnTypes(t *testing.T) {
+	profile, err := ProfileCSV(strings.NewReader(profileCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Rows != 5 {
+		t.Errorf("Rows = %d, want 5", profile.Rows)
+	}
+
+	want := map[string]struct {
+		typ      string
+		nulls    int
+		distinct int
+	}{
+		"id":     {ColumnInt, 0, 5},
+		"age":    {ColumnInt, 1, 4},
+		"income": {ColumnFloat, 1, 4},
+		"member": {ColumnBool, 0, 2},
+		"joined": {ColumnDatetime, 0, 5},
+		"city":   {ColumnCategorical, 0, 3},
+		"notes":  {ColumnCategorical, 2, 3},
+		"churn":  {ColumnInt, 0, 2},
+	}
+	for _, column := range profile.Columns {
+		w := want[column.Name]
+		if column.Type != w.typ || column.Nulls != w.nulls || column.Distinct != w.distinct {
+			t.Errorf("%s = %s with %d nulls and %d distinct, want %s with %d and %d",
+				column.Name, column.Type, column.Nulls, column.Distinct, w.typ, w.nulls, w.distinct)
+		}
+	}
+
+	income := profile.Columns[2]
+	if *income.Min != 48000.25 || *income.Max != 75000 || *income.Mean != 59000.1875 {
+		t.Errorf("income min/max/mean = %v/%v/%v", *income.Min, *income.Max, *income.Mean)
+	}
+	if q := *income.Quantiles; q.P25 != 51000.4375 || q.P50 != 56500.25 || q.P75 != 64500 {
+		t.Errorf("income quantiles = %+v", q)
+	}
+
+	joined := profile.Columns[4]
+	if joined.Earliest != "2023-01-05T00:00:00Z" || joined.Latest != "2023-05-17T00:00:00Z" {
+		t.Errorf("joined range = %s to %s", joined.Earliest, joined.Latest)
+	}
+
+	city := profile.Columns[5]
+	if len(city.TopValues) != 3 || city.TopValues[0] != (ValueCount{Value: "Paris", Count: 3}) {
+		t.Errorf("city top values = %v", city.TopValues)
+	}
+}
+
+func TestProfileCSVTargetCandidates(t *testing.T) {
+	tests := []struct {
+		name string
+		csv  string
+		want []TargetCandidate
+	}{
+		{
+			name: "last column",
+			csv:  profileCSV,
+			want: []TargetCandidate{{Column: "churn", TaskType: TaskClassification, Reason: "last column"}},
+		},
+		{
+			name: "named target before the last column",
+			csv:  "label,x,price\nspam,1.5,10.25\nham,2.5,12.5\nspam,3.5,9.75\n",
+			want: []TargetCandidate{
+				{Column: "label", TaskType: TaskClassification, Reason: "named like a target"},
+				{Column: "price", TaskType: TaskRegression, Reason: "last column"},
+			},
+		},
+		{
+			name: "identifiers and text are skipped",
+			csv:  "x,customer_id\n1.5,10\n2.5,11\n",
+			want: []TargetCandidate{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := ProfileCSV(strings.NewReader(tt.csv))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if fmt.Sprint(profile.TargetCandidates) != fmt.Sprint(tt.want) {
+				t.Errorf("TargetCandidates = %v, want %v", profile.TargetCandidates, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileCSVLargeColumns(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("n,word\n")
+	for i := 1; i <= 3*profileSampleSize; i++ {
+		fmt.Fprintf(&b, "%d,w%d\n", i, i)
+	}
+
+	profile, err := ProfileCSV(strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, word := profile.Columns[0], profile.Columns[1]
+	if *n.Min != 1 || *n.Max != 3*profileSampleSize || *n.Mean != 15000.5 {
+		t.Errorf("n min/max/mean = %v/%v/%v", *n.Min, *n.Max, *n.Mean)
+	}
+	// The median is estimated from a sample
+	if median := n.Quantiles.P50; median < 14000 || median > 16000 {
+		t.Errorf("n median = %v, want about 15000", median)
+	}
+	if !word.DistinctCapped || word.Distinct != profileDistinctLimit || word.Type != ColumnText {
+		t.Errorf("word = %s with %d distinct (capped %v), want capped text", word.Type, word.Distinct, word.DistinctCapped)
+	}
+}
+
+func TestProfileCSVRejectsMalformedFiles(t *testing.T) {
+	for _, input := range []string{"", "a,b\n\"unterminated,1\n"} {
+		if _, err := ProfileCSV(strings.NewReader(input)); err == nil {
+			t.Errorf("ProfileCSV(%q) succeeded", input)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// src/utils/datasetFormat.util.go
+package utils
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// Dataset formats the builder profiles and the generated code reads
+const (
+	FormatCSV     = "csv"
+	FormatTSV     = "tsv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+	FormatXLSX    = "xlsx"
+)
+
+// DefaultDatasetFormat is assumed for data without a known format
+const DefaultDatasetFormat = FormatCSV
+
+// DatasetFormat describes how a dataset format is stored and read
+type DatasetFormat struct {
+	Name        string
+	Extensions  []string // the first is used for new files
+	ContentType string
+	// Binary formats cannot be sent inline as text
+	Binary bool
+	// Module is the extra Python package pandas needs for the format
+	Module string
+}
+
+var datasetFormats = []DatasetFormat{
+	{Name: FormatCSV, Extensions: []string{".csv"}, ContentType: "text/csv"},
+	{Name: FormatTSV, Extensions: []string{".tsv", ".tab"}, ContentType: "text/tab-separated-values"},
+	{Name: FormatJSONL, Extensions: []string{".jsonl", ".ndjson"}, ContentType: "application/x-ndjson"},
+	{Name: FormatParquet, Extensions: []string{".parquet"}, ContentType: "application/vnd.apache.parquet", Binary: true, Module: "pyarrow"},
+	{Name: FormatXLSX, Extensions: []string{".xlsx"}, ContentType: "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", Binary: true, Module: "openpyxl"},
+}
+
+// DatasetFormats lists the supported dataset formats
+func DatasetFormats() []string {
+	names := make([]string, len(datasetFormats))
+	for i, format := range datasetFormats {
+		names[i] = format.Name
+	}
+	return names
+}
+
+// GetDatasetFormat returns a dataset format by name; empty means the
+// default format
+func GetDatasetFormat(name string) (DatasetFormat, bool) {
+	if name == "" {
+		name = DefaultDatasetFormat
+	}
+	for _, format := range datasetFormats {
+		if format.Name == name {
+			return format, true
+		}
+	}
+	return DatasetFormat{}, false
+}
+
+// DetectDatasetFormat picks the format of a file from the first bytes of
+// its content, falling back to its extension and then to CSV
+func DetectDatasetFormat(filename string, head []byte) string {
+	switch {
+	case bytes.HasPrefix(head, []byte("PAR1")):
+		return FormatParquet
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")):
+		// XLSX files are zip archives
+		return FormatXLSX
+	}
+
+	ext := strings.ToLower(filepath.Ext(filename))
+	for _, format := range datasetFormats {
+		if !format.Binary && containsString(format.Extensions, ext) {
+			return format.Name
+		}
+	}
+
+	head = bytes.TrimLeft(bytes.TrimPrefix(head, []byte("\ufeff")), " \t\r\n")
+	if bytes.HasPrefix(head, []byte("{")) {
+		return FormatJSONL
+	}
+	line, _, _ := bytes.Cut(head, []byte("\n"))
+	if bytes.Contains(line, []byte("\t")) && !bytes.Contains(line, []byte(",")) {
+		return FormatTSV
+	}
+	return DefaultDatasetFormat
+}
+
+// SniffDatasetFormat detects the format of a file from its name and first
+// 512 bytes, then rewinds it
+func SniffDatasetFormat(filename string, r io.ReadSeeker) (string, error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", err
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return DetectDatasetFormat(filename, head[:n]), nil
+}
+
+// dataFileName is the file name the generated code and bundles use for
+// the workflow's data
+func dataFileName(workflow WorkflowConfig) string {
+	format, ok := GetDatasetFormat(workflow.DataFormat)
+	if !ok {
+		format, _ = GetDatasetFormat(DefaultDatasetFormat)
+	}
+	return "data" + format.Extensions[0]
+}
+
+// dataIOCode reads and writes datasets in the format of the file
+// extension, so --data and --output accept any supported format and the
+// Arrow files of the arrow bridge
+const dataIOCode = `
+
+def read_data(path):
+    """Read a CSV, TSV, JSON Lines, Parquet, Excel or Arrow file, by extension"""
+    ext = str(path).lower().rsplit('.', 1)[-1]
+    if ext in ('tsv', 'tab'):
+        return pd.read_csv(path, sep='\t')
+    if ext in ('jsonl', 'ndjson'):
+        return pd.read_json(path, lines=True)
+    if ext == 'parquet':
+        return pd.read_parquet(path)
+    if ext in ('xlsx', 'xls'):
+        return pd.read_excel(path)
+    if ext in ('arrow', 'feather'):
+        return pd.read_feather(path)
+    return pd.read_csv(path)
+
+
+def write_data(df, path):
+    """Write a DataFrame as CSV, TSV, JSON Lines, Parquet, Excel or Arrow, by extension"""
+    ext = str(path).lower().rsplit('.', 1)[-1]
+    if ext in ('tsv', 'tab'):
+        df.to_csv(path, sep='\t', index=False)
+    elif ext in ('jsonl', 'ndjson'):
+        df.to_json(path, orient='records', lines=True)
+    elif ext == 'parquet':
+        df.to_parquet(path, index=False)
+    elif ext == 'xlsx':
+        df.to_excel(path, index=False)
+    elif ext in ('arrow', 'feather'):
+        df.reset_index(drop=True).to_feather(path)
+    else:
+        df.to_csv(path, index=False)
+
+
+def holdout_path(path):
+    """Return the path the test split is saved to, next to path"""
+    base, dot, ext = str(path).rpartition('.')
+    if not dot or '/' in ext:
+        return f"{path}_test"
+    return f"{base}_test.{ext}"
+`
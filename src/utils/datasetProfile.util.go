@@ -0,0 +1,133 @@
+// src/utils/datasetProfile.util.go
+package utils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ProfileDataset profiles a dataset of one of DatasetFormats. Text formats
+// are streamed; Parquet and XLSX need random access, so r should be an
+// io.ReaderAt and io.Seeker such as *os.File or it is read into memory.
+func ProfileDataset(r io.Reader, format string) (*DatasetProfile, error) {
+	switch format {
+	case FormatCSV, "":
+		return profileDelimited(r, ',')
+	case FormatTSV:
+		return profileDelimited(r, '\t')
+	case FormatJSONL:
+		return profileJSONL(r)
+	case FormatParquet, FormatXLSX:
+		ra, size, err := readerAt(r)
+		if err != nil {
+			return nil, err
+		}
+		if format == FormatParquet {
+			return profileParquet(ra, size)
+		}
+		return profileXLSX(ra, size)
+	}
+	return nil, fmt.Errorf("unsupported dataset format %q", format)
+}
+
+// readerAt returns r with random access and its size
+func readerAt(r io.Reader) (io.ReaderAt, int64, error) {
+	if ra, ok := r.(interface {
+		io.ReaderAt
+		io.Seeker
+	}); ok {
+		size, err := ra.Seek(0, io.SeekEnd)
+		return ra, size, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// profileJSONL profiles a file with one JSON object per line. Columns are
+// the keys in the order they first appear; nested values are profiled as
+// their JSON text.
+func profileJSONL(r io.Reader) (*DatasetProfile, error) {
+	p := newProfiler(nil)
+	index := map[string]int{}
+	var values []string
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64<<10), 16<<20)
+	for line := 1; scanner.Scan(); line++ {
+		text := bytes.TrimSpace(scanner.Bytes())
+		if len(text) == 0 {
+			continue
+		}
+
+		values = values[:0]
+		err := decodeJSONObject(text, func(key string, value json.RawMessage) {
+			i, ok := index[key]
+			if !ok {
+				i = p.addColumn(key)
+				index[key] = i
+			}
+			for len(values) <= i {
+				values = append(values, "")
+			}
+			values[i] = jsonCell(value)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		p.addRow(values)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if p.rows == 0 {
+		return nil, errors.New("empty file")
+	}
+	return p.result(), nil
+}
+
+// decodeJSONObject calls fn for every key of a JSON object in source order
+func decodeJSONObject(data []byte, fn func(key string, value json.RawMessage)) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return errors.New("expected a JSON object")
+	}
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var value json.RawMessage
+		if err := dec.Decode(&value); err != nil {
+			return err
+		}
+		fn(tok.(string), value)
+	}
+	if _, err := dec.Token(); err != nil {
+		return err
+	}
+	if dec.More() {
+		return errors.New("unexpected data after the JSON object")
+	}
+	return nil
+}
+
+// jsonCell turns a JSON value into the text a CSV cell would hold
+func jsonCell(value json.RawMessage) string {
+	switch {
+	case bytes.Equal(value, []byte("null")):
+		return ""
+	case len(value) > 0 && value[0] == '"':
+		var s string
+		json.Unmarshal(value, &s)
+		return s
+	}
+	return string(value)
+}
@@ -0,0 +1,395 @@
+package utils
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestDetectDatasetFormat(t *testing.T) {
+	tests := []struct {
+		filename string
+		head     string
+		want     string
+	}{
+		{"data.parquet", "PAR1\x15\x04", FormatParquet},
+		{"upload", "PAR1\x15\x04", FormatParquet},
+		{"book.xlsx", "PK\x03\x04\x14\x00", FormatXLSX},
+		{"data.tsv", "a,b\n", FormatTSV},
+		{"data.NDJSON", "", FormatJSONL},
+		{"data.csv", "a\tb\n", FormatCSV},
+		{"upload", "\ufeff {\"a\": 1}\n", FormatJSONL},
+		{"upload", "a\tb\tc\n1\t2\t3\n", FormatTSV},
+		{"upload", "a,b\tc\n", FormatCSV},
+		{"", "", FormatCSV},
+	}
+	for _, tt := range tests {
+		if got := DetectDatasetFormat(tt.filename, []byte(tt.head)); got != tt.want {
+			t.Errorf("DetectDatasetFormat(%q, %q) = %q, want %q", tt.filename, tt.head, got, tt.want)
+		}
+	}
+}
+
+// profileColumns indexes the columns of a profile by name
+func profileColumns(t *testing.T, profile *DatasetProfile, err error) map[string]ColumnProfile {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+	columns := map[string]ColumnProfile{}
+	for _, column := range profile.Columns {
+		columns[column.Name] = column
+	}
+	return columns
+}
+
+func TestProfileDatasetTSV(t *testing.T) {
+	data := strings.ReplaceAll(profileCSV, ",", "\t")
+	profile, err := ProfileDataset(strings.NewReader(data), FormatTSV)
+	columns := profileColumns(t, profile, err)
+	if profile.Rows != 5 || len(columns) != 8 {
+		t.Fatalf("profile = %d rows, %d columns", profile.Rows, len(columns))
+	}
+	if columns["income"].Type != ColumnFloat || columns["joined"].Type != ColumnDatetime {
+		t.Errorf("columns = %+v", columns)
+	}
+}
+
+func TestProfileDatasetJSONL(t *testing.T) {
+	data := `{"id": 1, "city": "Paris", "score": 0.5, "churn": false}
+
+{"id": 2, "city": "Lyon", "score": null, "churn": true, "tags": ["a", "b"]}
+{"id": 3, "city": "Paris", "churn": false}
+`
+	profile, err := ProfileDataset(strings.NewReader(data), FormatJSONL)
+	columns := profileColumns(t, profile, err)
+	if profile.Rows != 3 {
+		t.Errorf("Rows = %d, want 3", profile.Rows)
+	}
+
+	var names []string
+	for _, column := range profile.Columns {
+		names = append(names, column.Name)
+	}
+	if got := strings.Join(names, ","); got != "id,city,score,churn,tags" {
+		t.Errorf("columns = %s, want keys in order of appearance", got)
+	}
+	if c := columns["score"]; c.Type != ColumnFloat || c.Nulls != 2 {
+		t.Errorf("score = %+v, want float with 2 nulls", c)
+	}
+	if c := columns["tags"]; c.Nulls != 2 {
+		t.Errorf("tags = %+v, want 2 nulls for the rows before it appeared", c)
+	}
+	if c := columns["churn"]; c.Type != ColumnBool {
+		t.Errorf("churn = %+v, want bool", c)
+	}
+
+	if _, err := ProfileDataset(strings.NewReader("{\"a\": 1}\n[1, 2]\n"), FormatJSONL); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("err = %v, want an error on line 2", err)
+	}
+}
+
+// xlsxFile zips workbook parts
+func xlsxFile(t *testing.T, parts map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+	for name, content := range parts {
+		w, err := archive.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := archive.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestProfileDatasetXLSX(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml": `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<sheets><sheet name="Data" sheetId="1" r:id="rId2"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Target="worksheets/sheet1.xml"/><Relationship Id="rId2" Target="worksheets/data.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":     `<sst><si><t>city</t></si><si><r><t>Par</t></r><r><t>is</t></r><rPh><t>x</t></rPh></si><si><t>Lyon</t></si></sst>`,
+		"xl/worksheets/sheet1.xml": `<worksheet><sheetData><row r="1"><c r="A1" t="inlineStr"><is><t>wrong</t></is></c></row></sheetData></worksheet>`,
+		"xl/worksheets/data.xml": `<worksheet><sheetData>
+<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="inlineStr"><is><t>amount</t></is></c><c r="C1" t="inlineStr"><is><t>label</t></is></c></row>
+<row r="2"><c r="A2" t="s"><v>1</v></c><c r="B2"><v>1.5</v></c><c r="C2" t="b"><v>1</v></c></row>
+<row r="3"><c r="A3" t="s"><v>2</v></c><c r="C3" t="b"><v>0</v></c></row>
+<row r="4"><c r="A4" t="s"><v>1</v></c><c r="B4"><v>4</v></c><c r="C4" t="e"><v>#N/A</v></c></row>
+</sheetData></worksheet>`,
+	}
+	profile, err := ProfileDataset(bytes.NewReader(xlsxFile(t, parts)), FormatXLSX)
+	columns := profileColumns(t, profile, err)
+	if profile.Rows != 3 || len(columns) != 3 {
+		t.Fatalf("profile = %+v, want 3 rows of the first sheet", profile)
+	}
+	if c := columns["city"]; c.Distinct != 2 || len(c.TopValues) == 0 || c.TopValues[0].Value != "Paris" {
+		t.Errorf("city = %+v, want shared strings with rich text joined", c)
+	}
+	if c := columns["amount"]; c.Type != ColumnFloat || c.Nulls != 1 {
+		t.Errorf("amount = %+v, want float with the empty cell as null", c)
+	}
+	if c := columns["label"]; c.Type != ColumnBool || c.Nulls != 1 {
+		t.Errorf("label = %+v, want bool with the error cell as null", c)
+	}
+}
+
+func TestProfileDatasetXLSXSharedStringLimits(t *testing.T) {
+	defer func(size int64) { maxSharedStringsSize = size }(maxSharedStringsSize)
+
+	sheet := `<worksheet><sheetData><row r="1"><c r="A1" t="s"><v>0</v></c></row></sheetData></worksheet>`
+	tests := []struct {
+		name    string
+		size    int64
+		strings string
+		want    string
+	}{
+		{"too many entries", 64 << 20, "<sst>" + strings.Repeat("<si/>", maxSharedStrings+1) + "</sst>", "more than 1048576 shared strings"},
+		{"too large", 1 << 20, "<sst><si><t>" + strings.Repeat("x", 2<<20) + "</t></si></sst>", "shared strings exceed 1048576 bytes"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			maxSharedStringsSize = tt.size
+			file := xlsxFile(t, map[string]string{
+				"xl/sharedStrings.xml":     tt.strings,
+				"xl/worksheets/sheet1.xml": sheet,
+			})
+			_, err := ProfileDataset(bytes.NewReader(file), FormatXLSX)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// compactWriter encodes the Thrift compact protocol for test footers
+type compactWriter struct {
+	bytes.Buffer
+	last []int16
+}
+
+func (w *compactWriter) field(id int16, typ byte) {
+	last := w.last[len(w.last)-1]
+	if delta := id - last; delta > 0 && delta <= 15 {
+		w.WriteByte(byte(delta)<<4 | typ)
+	} else {
+		w.WriteByte(typ)
+		w.zigzag(int64(id))
+	}
+	w.last[len(w.last)-1] = id
+}
+
+func (w *compactWriter) zigzag(v int64) {
+	w.Write(binary.AppendUvarint(nil, uint64(v<<1^v>>63)))
+}
+
+func (w *compactWriter) int(id int16, v int64) {
+	w.field(id, thriftI64)
+	w.zigzag(v)
+}
+
+func (w *compactWriter) binary(id int16, b []byte) {
+	w.field(id, thriftBinary)
+	w.Write(binary.AppendUvarint(nil, uint64(len(b))))
+	w.Write(b)
+}
+
+func (w *compactWriter) begin(id int16) {
+	w.field(id, thriftStructType)
+	w.last = append(w.last, 0)
+}
+
+func (w *compactWriter) list(id int16, n int) {
+	w.field(id, thriftList)
+	if n < 15 {
+		w.WriteByte(byte(n)<<4 | thriftStructType)
+		return
+	}
+	w.WriteByte(0xf0 | thriftStructType)
+	w.Write(binary.AppendUvarint(nil, uint64(n)))
+}
+
+// item starts a struct inside a list
+func (w *compactWriter) item() {
+	w.last = append(w.last, 0)
+}
+
+func (w *compactWriter) end() {
+	w.WriteByte(thriftStop)
+	w.last = w.last[:len(w.last)-1]
+}
+
+func le32(v uint32) []byte { return binary.LittleEndian.AppendUint32(nil, v) }
+func le64(v uint64) []byte { return binary.LittleEndian.AppendUint64(nil, v) }
+
+func TestProfileDatasetParquet(t *testing.T) {
+	w := &compactWriter{last: []int16{0}}
+	w.list(2, 5) // schema
+	w.item()
+	w.binary(4, []byte("schema"))
+	w.int(5, 4)
+	w.end()
+	leaves := []struct {
+		name      string
+		physical  int64
+		converted int64
+	}{
+		{"age", parquetInt32, -1},
+		{"income", parquetDouble, -1},
+		{"joined", parquetInt32, convertedDate},
+		{"city", parquetByteArray, -1},
+	}
+	for _, leaf := range leaves {
+		w.item()
+		w.int(1, leaf.physical)
+		w.binary(4, []byte(leaf.name))
+		if leaf.converted >= 0 {
+			w.int(6, leaf.converted)
+		}
+		w.end()
+	}
+	w.int(3, 1000) // num_rows
+
+	stats := [][2][]byte{
+		{le32(18), le32(90)},
+		{le64(math.Float64bits(1200.5)), le64(math.Float64bits(98000))},
+		{le32(19358), le32(19722)}, // 2023-01-01 and 2023-12-31
+		{[]byte("Lyon"), []byte("Paris")},
+	}
+	w.list(4, 1) // row_groups
+	w.item()
+	w.list(1, len(stats)) // columns
+	for i, s := range stats {
+		w.item()
+		w.begin(3) // meta_data
+		w.begin(12)
+		w.int(3, int64(i))
+		if i == 3 {
+			w.int(4, 3)
+		}
+		w.binary(5, s[1])
+		w.binary(6, s[0])
+		w.end()
+		w.end()
+		w.end()
+	}
+	w.end()
+	w.WriteByte(thriftStop)
+
+	footer := w.Bytes()
+	file := append([]byte("PAR1"), footer...)
+	file = append(append(file, le32(uint32(len(footer)))...), "PAR1"...)
+
+	profile, err := ProfileDataset(bytes.NewReader(file), FormatParquet)
+	columns := profileColumns(t, profile, err)
+	if profile.Rows != 1000 || len(columns) != 4 {
+		t.Fatalf("profile = %+v", profile)
+	}
+	if c := columns["age"]; c.Type != ColumnInt || c.Min == nil || *c.Min != 18 || *c.Max != 90 || !c.DistinctCapped {
+		t.Errorf("age = %+v", c)
+	}
+	if c := columns["income"]; c.Type != ColumnFloat || c.Nulls != 1 || *c.Max != 98000 {
+		t.Errorf("income = %+v", c)
+	}
+	if c := columns["joined"]; c.Type != ColumnDatetime || c.Earliest != "2023-01-01T00:00:00Z" || c.Latest != "2023-12-31T00:00:00Z" {
+		t.Errorf("joined = %+v", c)
+	}
+	if c := columns["city"]; c.Type != ColumnCategorical || c.Distinct != 3 || c.DistinctCapped {
+		t.Errorf("city = %+v", c)
+	}
+
+	if _, err := ProfileDataset(bytes.NewReader(file[:len(file)-4]), FormatParquet); err == nil {
+		t.Error("expected an error for a truncated file")
+	}
+}
+
+// parquetFile wraps a footer in the Parquet magic and length
+func parquetFile(footer []byte) []byte {
+	file := append([]byte("PAR1"), footer...)
+	return append(append(file, le32(uint32(len(footer)))...), "PAR1"...)
+}
+
+// parquetFooter returns a footer with a valid schema of the given number
+// of int64 columns, followed by what rest writes
+func parquetFooter(columns int, rest func(w *compactWriter)) []byte {
+	w := &compactWriter{last: []int16{0}}
+	w.list(2, 1+columns)
+	w.item()
+	w.binary(4, []byte("schema"))
+	w.int(5, int64(columns))
+	w.end()
+	for i := 0; i < columns; i++ {
+		w.item()
+		w.int(1, parquetInt64)
+		w.binary(4, []byte(fmt.Sprintf("c%d", i)))
+		w.end()
+	}
+	rest(w)
+	w.WriteByte(thriftStop)
+	return w.Bytes()
+}
+
+func TestProfileDatasetParquetInvalidFooters(t *testing.T) {
+	deep := &compactWriter{last: []int16{0}}
+	deep.list(2, maxParquetNesting+2)
+	for i := 0; i < maxParquetNesting+2; i++ {
+		deep.item()
+		deep.int(5, 1)
+		deep.end()
+	}
+	deep.WriteByte(thriftStop)
+
+	tests := []struct {
+		name string
+		file []byte
+	}{
+		{"schema of integers", parquetFile([]byte{0x29, 0x15, 0x00, 0x00})},
+		{"truncated footer", parquetFile(parquetFooter(0, func(w *compactWriter) { w.int(3, 10) })[:9])},
+		{"length past the file", append(append([]byte("PAR1"), le32(1<<20)...), "PAR1"...)},
+		{"row group of integers", parquetFile(parquetFooter(0, func(w *compactWriter) {
+			w.field(4, thriftList)
+			w.WriteByte(1<<4 | thriftI32)
+			w.zigzag(7)
+		}))},
+		{"column chunk of strings", parquetFile(parquetFooter(1, func(w *compactWriter) {
+			w.list(4, 1)
+			w.item()
+			w.field(1, thriftList)
+			w.WriteByte(1<<4 | thriftBinary)
+			w.WriteByte(1)
+			w.WriteByte('x')
+			w.end()
+		}))},
+		{"distinct count of bytes", parquetFile(parquetFooter(1, func(w *compactWriter) {
+			w.list(4, 1)
+			w.item()
+			w.list(1, 1)
+			w.item()
+			w.begin(3)
+			w.begin(12)
+			w.binary(4, []byte("many"))
+			w.end()
+			w.end()
+			w.end()
+			w.end()
+		}))},
+		{"negative row count", parquetFile(parquetFooter(0, func(w *compactWriter) { w.int(3, -1) }))},
+		{"schema nested too deeply", parquetFile(deep.Bytes())},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			profile, err := ProfileDataset(bytes.NewReader(tt.file), FormatParquet)
+			if err == nil || !strings.Contains(err.Error(), "invalid Parquet footer") {
+				t.Fatalf("ProfileDataset() = %+v, %v, want an invalid Parquet footer error", profile, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+// src/utils/executor.util.go
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ExecutorImagePrefix names the images built to run component tests
+const ExecutorImagePrefix = "builder-ai-executor"
+
+// DefaultExecutorPackages are the Python packages component tests may
+// import when EXECUTOR_PYTHON_PACKAGES is not set
+const DefaultExecutorPackages = "numpy==1.26.4,pandas==2.2.3,scikit-learn==1.5.2,scipy==1.14.1"
+
+// executorInstall installs the packages from wheels only, so building the
+// image runs no code of the packages
+const executorInstall = "pip install --no-cache-dir --only-binary=:all: -r requirements.txt"
+
+var (
+	executorMu       sync.RWMutex
+	executorPackages = mustParsePins(DefaultExecutorPackages)
+)
+
+// SetExecutorPackages replaces the vetted packages component tests run
+// with. spec is a comma separated list of pins such as
+// "pandas==2.2.3,numpy==1.26.4"; every package needs an exact version.
+func SetExecutorPackages(spec string) error {
+	packages, err := parsePins(spec)
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("at least one package is required")
+	}
+	executorMu.Lock()
+	defer executorMu.Unlock()
+	executorPackages = packages
+	return nil
+}
+
+// ExecutorPackages returns the vetted packages as sorted pins
+func ExecutorPackages() []string {
+	executorMu.RLock()
+	defer executorMu.RUnlock()
+	requirements := make([]string, 0, len(executorPackages))
+	for name, version := range executorPackages {
+		requirements = append(requirements, name+"=="+version)
+	}
+	sort.Strings(requirements)
+	return requirements
+}
+
+// CheckExecutorRequirements reports the requirements outside the vetted
+// packages. Version specifiers are not compared: tests run with the
+// vetted version of a package.
+func CheckExecutorRequirements(requirements []string) error {
+	executorMu.RLock()
+	defer executorMu.RUnlock()
+	var missing []string
+	for _, requirement := range requirements {
+		if _, ok := executorPackages[normalizePackage(requirementName(requirement))]; !ok {
+			missing = append(missing, requirementName(requirement))
+		}
+	}
+	if len(missing) > 0 {
+		names := make([]string, 0, len(executorPackages))
+		for name := range executorPackages {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("%s not available to tests, which can use: %s", strings.Join(missing, ", "), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+// ExecutorImage is the image code of a language runs in. Files is the
+// build context of an image adding the vetted packages to the Python
+// runtime image; it is empty for other languages, whose Tag is their
+// runtime image.
+type ExecutorImage struct {
+	Tag   string
+	Files map[string][]byte
+}
+
+// NewExecutorImage returns the image code of the language runs in. The
+// tag is derived from the build context, so the image is built once per
+// set of vetted packages.
+func NewExecutorImage(l Language) ExecutorImage {
+	runtime := l.Runtime()
+	if l.Name() != LanguagePython {
+		return ExecutorImage{Tag: runtime.Image}
+	}
+
+	manifest, content := l.Manifest(ExecutorPackages())
+	dockerfile := fmt.Sprintf("FROM %s\n\nWORKDIR /app\n\nCOPY %s .\nRUN %s\n", runtime.Image, manifest, executorInstall)
+	files := map[string][]byte{
+		"Dockerfile": []byte(dockerfile),
+		manifest:     content,
+	}
+
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	hash := sha256.New()
+	for _, name := range names {
+		fmt.Fprintf(hash, "%s\x00%d\x00", name, len(files[name]))
+		hash.Write(files[name])
+	}
+	tag := fmt.Sprintf("%s:%s-%s", ExecutorImagePrefix, l.Name(), hex.EncodeToString(hash.Sum(nil))[:16])
+	return ExecutorImage{Tag: tag, Files: files}
+}
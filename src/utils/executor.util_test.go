@@ -0,0 +1,70 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// withExecutorPackages sets the vetted packages for the duration of a test
+func withExecutorPackages(t *testing.T, spec string) {
+	t.Helper()
+	if err := SetExecutorPackages(spec); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetExecutorPackages(DefaultExecutorPackages) })
+}
+
+func TestNewExecutorImage(t *testing.T) {
+	withExecutorPackages(t, "pandas==2.2.3,NumPy==1.26.4")
+	python, _ := GetLanguage(LanguagePython)
+	golang, _ := GetLanguage(LanguageGo)
+
+	if image := NewExecutorImage(golang); image.Tag != "golang:1.23" || image.Files != nil {
+		t.Errorf("go: image = %+v, want the runtime image", image)
+	}
+
+	image := NewExecutorImage(python)
+	if !strings.HasPrefix(image.Tag, ExecutorImagePrefix+":python-") {
+		t.Errorf("Tag = %q", image.Tag)
+	}
+	if got := string(image.Files["requirements.txt"]); got != "numpy==1.26.4\npandas==2.2.3\n" {
+		t.Errorf("requirements.txt = %q", got)
+	}
+	want := "FROM python:3.11-slim\n\nWORKDIR /app\n\nCOPY requirements.txt .\nRUN pip install --no-cache-dir --only-binary=:all: -r requirements.txt\n"
+	if got := string(image.Files["Dockerfile"]); got != want {
+		t.Errorf("Dockerfile = %q, want %q", got, want)
+	}
+
+	if again := NewExecutorImage(python); again.Tag != image.Tag {
+		t.Errorf("same packages: Tag = %q, want %q", again.Tag, image.Tag)
+	}
+	withExecutorPackages(t, "pandas==2.2.3")
+	if other := NewExecutorImage(python); other.Tag == image.Tag {
+		t.Errorf("other packages: Tag = %q, want a different tag", other.Tag)
+	}
+}
+
+func TestCheckExecutorRequirements(t *testing.T) {
+	withExecutorPackages(t, "pandas==2.2.3,scikit-learn==1.5.2")
+
+	if err := CheckExecutorRequirements([]string{"pandas>=2.0", "scikit_learn", "Pandas==1.5.3"}); err != nil {
+		t.Errorf("vetted packages: err = %v", err)
+	}
+	err := CheckExecutorRequirements([]string{"pandas", "requests==2.32.3", "evil-setup"})
+	if err == nil || err.Error() != "requests, evil-setup not available to tests, which can use: pandas, scikit-learn" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestSetExecutorPackagesErrors(t *testing.T) {
+	defer SetExecutorPackages(DefaultExecutorPackages)
+	for _, spec := range []string{"", "pandas", "pandas>=2.0"} {
+		if err := SetExecutorPackages(spec); err == nil {
+			t.Errorf("SetExecutorPackages(%q) error = nil", spec)
+		}
+	}
+	if got := ExecutorPackages(); !reflect.DeepEqual(got, []string{"numpy==1.26.4", "pandas==2.2.3", "scikit-learn==1.5.2", "scipy==1.14.1"}) {
+		t.Errorf("ExecutorPackages() = %v, want the defaults kept", got)
+	}
+}
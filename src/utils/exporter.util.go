@@ -0,0 +1,118 @@
+// src/utils/exporter.util.go
+package utils
+
+import (
+	"sort"
+	"strings"
+)
+
+// Exporter renders a workflow into a downloadable artifact. Command and
+// Requirements describe how to run the artifact in a project bundle; {file}
+// in Command is replaced by the artifact's file name, {data} by the data
+// file's and {target} by the workflow's target column.
+type Exporter struct {
+	Format      string
+	ContentType string
+	Extension   string
+	Generate    func(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error)
+	// Command runs the artifact in a bundle; {file} is replaced with its
+	// file name, {data} with the data file's and {target} with the
+	// workflow's target column
+	Command      string
+	Requirements []string
+	// Language is the component language the artifact runs, python when
+	// empty
+	Language string
+}
+
+// DefaultExportFormat is used when no format is requested
+const DefaultExportFormat = "script"
+
+var exporters = map[string]Exporter{}
+
+// RegisterExporter makes an export format available by name
+func RegisterExporter(e Exporter) {
+	exporters[e.Format] = e
+}
+
+// GetExporter returns the exporter registered for format
+func GetExporter(format string) (Exporter, bool) {
+	e, ok := exporters[format]
+	return e, ok
+}
+
+// GetExporterFor returns the exporter of format for workflows whose
+// components are in language. Only the script format runs components in
+// languages other than Python.
+func GetExporterFor(format, language string) (Exporter, bool) {
+	if language == "" || language == LanguagePython {
+		return GetExporter(format)
+	}
+	l, ok := GetLanguage(language)
+	if !ok || format != DefaultExportFormat {
+		return Exporter{}, false
+	}
+	return Exporter{
+		Format:      DefaultExportFormat,
+		ContentType: l.ContentType(),
+		Extension:   l.Extension(),
+		Generate:    l.Generate,
+		Command:     strings.ReplaceAll(l.Runtime().Command(), "{output}", "output.csv"),
+		Language:    l.Name(),
+	}, true
+}
+
+// ExportFormats lists the registered formats in alphabetical order
+func ExportFormats() []string {
+	formats := make([]string, 0, len(exporters))
+	for format := range exporters {
+		formats = append(formats, format)
+	}
+	sort.Strings(formats)
+	return formats
+}
+
+func init() {
+	RegisterExporter(Exporter{
+		Format:      DefaultExportFormat,
+		ContentType: "text/x-python; charset=utf-8",
+		Extension:   ".py",
+		Command:     "python {file} --data {data} --target {target} --output output.csv",
+		Generate: func(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+			script, err := GenerateExecutableScript(workflow, componentCode, opts)
+			return []byte(script), err
+		},
+	})
+	RegisterExporter(Exporter{
+		Format:       "ipynb",
+		ContentType:  "application/x-ipynb+json",
+		Extension:    ".ipynb",
+		Generate:     GenerateNotebook,
+		Command:      "papermill {file} output.ipynb -p data_file {data} -p target_column {target}",
+		Requirements: []string{"papermill", "ipykernel"},
+	})
+	RegisterExporter(Exporter{
+		Format:       "sklearn",
+		ContentType:  "text/x-python; charset=utf-8",
+		Extension:    "_pipeline.py",
+		Generate:     GenerateSklearnPipeline,
+		Command:      "python {file} --data {data} --target {target} --model-output model.joblib",
+		Requirements: []string{"scikit-learn", "joblib"},
+	})
+	RegisterExporter(Exporter{
+		Format:       "airflow",
+		ContentType:  "text/x-python; charset=utf-8",
+		Extension:    "_dag.py",
+		Generate:     GenerateAirflowDAG,
+		Command:      "export AIRFLOW__CORE__DAGS_FOLDER=$PWD AIRFLOW__CORE__LOAD_EXAMPLES=False && airflow db migrate && airflow dags test builder_workflow",
+		Requirements: []string{"apache-airflow"},
+	})
+	RegisterExporter(Exporter{
+		Format:       "prefect",
+		ContentType:  "text/x-python; charset=utf-8",
+		Extension:    "_flow.py",
+		Generate:     GeneratePrefectFlow,
+		Command:      "python {file} --data {data} --target {target} --output output.csv",
+		Requirements: []string{"prefect"},
+	})
+}
@@ -0,0 +1,572 @@
+// src/utils/goGenerator.util.go
+package utils
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// goLanguage runs Go components in a generated main package
+type goLanguage struct{}
+
+func (goLanguage) Name() string        { return LanguageGo }
+func (goLanguage) Extension() string   { return ".go" }
+func (goLanguage) ContentType() string { return "text/x-go; charset=utf-8" }
+
+func (goLanguage) FunctionName(code string) string {
+	return GoFunctionName(code)
+}
+
+func (goLanguage) Generate(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	return GenerateGo(workflow, componentCode, opts)
+}
+
+// Requirements is empty: the harness only builds with the standard library
+func (goLanguage) Requirements(WorkflowConfig, string) []string {
+	return nil
+}
+
+func (goLanguage) Manifest([]string) (string, []byte) {
+	return "", nil
+}
+
+func (goLanguage) CheckSyntax(code string) []Diagnostic {
+	return checkGoSyntax(code)
+}
+
+func (goLanguage) Runtime() Runtime {
+	return Runtime{
+		Image:          "golang:1.23",
+		DebianPackages: []string{"golang-go"},
+		Run:            "go run",
+	}
+}
+
+// goFunctionPattern matches the first top-level function, not a method
+var goFunctionPattern = regexp.MustCompile(`(?m)^func\s+([A-Za-z_][A-Za-z0-9_]*)\s*[\[(]`)
+
+// GoFunctionName returns the name of the first top-level function in Go
+// code, or "" when there is none
+func GoFunctionName(code string) string {
+	if m := goFunctionPattern.FindStringSubmatch(code); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+// isGoName reports whether s can be called as a Go function
+func isGoName(s string) bool {
+	return isIdentifier(s) && !token.IsKeyword(s) && s != "main" && s != "init"
+}
+
+// goPackageClause matches the package clause starting a pasted Go file
+var goPackageClause = regexp.MustCompile(`(?m)^package\s+[A-Za-z_][A-Za-z0-9_]*[ \t]*(?://.*)?$`)
+
+// goHarnessImports are the packages imported by the harness code
+var goHarnessImports = []string{"encoding/csv", "encoding/json", "errors", "flag", "fmt", "io", "os", "path/filepath", "strconv", "strings"}
+
+// goImport is an import spec of component code
+type goImport struct {
+	Name string // the explicit package name, "" when there is none
+	Path string
+}
+
+// splitGoSource separates the imports of Go component code from the rest.
+// Components pasted together may each start with a package clause and
+// imports; the clauses are dropped and the imports merged.
+func splitGoSource(code string) ([]goImport, string, error) {
+	starts := goPackageClause.FindAllStringIndex(code, -1)
+	if len(starts) == 0 || strings.TrimSpace(code[:starts[0][0]]) != "" && !onlyComments(code[:starts[0][0]]) {
+		code = "package main\n" + code
+		starts = goPackageClause.FindAllStringIndex(code, -1)
+	}
+
+	var imports []goImport
+	var body strings.Builder
+	for i, start := range starts {
+		end := len(code)
+		if i+1 < len(starts) {
+			end = starts[i+1][0]
+		}
+		begin := start[0]
+		if i == 0 {
+			begin = 0 // keep the comments above the first package clause
+		}
+		chunk := code[begin:end]
+		line := strings.Count(code[:begin], "\n")
+
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", chunk, parser.ImportsOnly)
+		if err != nil {
+			return nil, "", &CodeError{Language: LanguageGo, Message: offsetGoError(err, line)}
+		}
+
+		rest := fset.Position(file.Name.End()).Offset
+		for _, decl := range file.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.IMPORT {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				imp := spec.(*ast.ImportSpec)
+				path, _ := strconv.Unquote(imp.Path.Value)
+				if !isStandardPackage(path) {
+					return nil, "", &CodeError{Language: LanguageGo, Message: fmt.Sprintf(
+						"imports %q; only standard library packages are available to Go components", path)}
+				}
+				var name string
+				if imp.Name != nil {
+					name = imp.Name.Name
+				}
+				if entry := (goImport{Name: name, Path: path}); !containsImport(imports, entry) {
+					imports = append(imports, entry)
+				}
+			}
+			rest = fset.Position(gen.End()).Offset
+		}
+		body.WriteString(strings.TrimSpace(chunk[rest:]))
+		body.WriteString("\n\n")
+	}
+	return imports, strings.TrimSpace(body.String()), nil
+}
+
+// onlyComments reports whether the Go source holds nothing but comments
+func onlyComments(src string) bool {
+	for _, line := range strings.Split(src, "\n") {
+		if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "//") {
+			return false
+		}
+	}
+	return true
+}
+
+// offsetGoError reports a parse error at its line in the component code
+func offsetGoError(err error, lines int) string {
+	if list, ok := err.(scanner.ErrorList); ok && len(list) > 0 {
+		return fmt.Sprintf("line %d: %s", list[0].Pos.Line+lines, list[0].Msg)
+	}
+	return err.Error()
+}
+
+// checkGoSyntax parses Go component code, which may leave out its package
+// clause, and reports its syntax errors and imports outside the standard
+// library
+func checkGoSyntax(code string) []Diagnostic {
+	src, lines := code, 0
+	if starts := goPackageClause.FindStringIndex(code); starts == nil || !onlyComments(code[:starts[0]]) {
+		src, lines = "package main\n"+code, 1
+	}
+
+	var diagnostics []Diagnostic
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, 0)
+	if list, ok := err.(scanner.ErrorList); ok {
+		for _, e := range list {
+			diagnostics = append(diagnostics, Diagnostic{Line: e.Pos.Line - lines, Column: e.Pos.Column, Message: e.Msg})
+		}
+	}
+	if file != nil {
+		for _, imp := range file.Imports {
+			path, _ := strconv.Unquote(imp.Path.Value)
+			if !isStandardPackage(path) {
+				pos := fset.Position(imp.Path.Pos())
+				diagnostics = append(diagnostics, Diagnostic{Line: pos.Line - lines, Column: pos.Column,
+					Message: fmt.Sprintf("imports %q; only standard library packages are available to Go components", path)})
+			}
+		}
+	}
+	if len(diagnostics) > maxDiagnostics {
+		diagnostics = diagnostics[:maxDiagnostics]
+	}
+	return diagnostics
+}
+
+// isStandardPackage reports whether an import path is in the standard
+// library, whose paths have no dot in their first element
+func isStandardPackage(path string) bool {
+	first, _, _ := strings.Cut(path, "/")
+	return path != "" && !strings.Contains(first, ".")
+}
+
+func containsImport(imports []goImport, imp goImport) bool {
+	for _, existing := range imports {
+		if existing == imp {
+			return true
+		}
+	}
+	return false
+}
+
+// renderGo renders a parsed literal as a Go expression of a Params value
+func renderGo(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case pyInt:
+		if n, _ := new(big.Int).SetString(string(v), 10); !n.IsInt64() {
+			return "", fmt.Errorf("%s is too large for a Go int", v)
+		}
+		return string(v), nil
+	case pyFloat:
+		return string(v), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("must be a finite number")
+		}
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return formatFloat(v), nil
+	case string:
+		return strconv.Quote(v), nil
+	case []interface{}:
+		return renderGoItems(v)
+	case pyTuple:
+		return renderGoItems(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dict := make(pyDict, len(keys))
+		for i, key := range keys {
+			dict[i] = pyItem{Key: key, Value: v[key]}
+		}
+		return renderGo(dict)
+	case pyDict:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			key, ok := item.Key.(string)
+			if !ok {
+				return "", fmt.Errorf("dict keys must be strings in Go, got %s", pyTypeName(item.Key))
+			}
+			val, err := renderGo(item.Value)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = strconv.Quote(key) + ": " + val
+		}
+		return "map[string]any{" + strings.Join(parts, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func renderGoItems(items []interface{}) (string, error) {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		rendered, err := renderGo(item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = rendered
+	}
+	return "[]any{" + strings.Join(parts, ", ") + "}", nil
+}
+
+// GenerateGo generates a Go program that reads --data, calls the Go
+// components stage by stage and writes --output. A component has the
+// signature func(Table, Params, Context) (Table, error); components may
+// only import the standard library.
+func GenerateGo(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	imports, code, err := splitGoSource(componentCode)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := harnessStages(workflow, harnessSyntax{
+		Language: LanguageGo,
+		Title:    "Go",
+		IsName:   isGoName,
+		Quote:    strconv.Quote,
+		Render:   renderGo,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, path := range goHarnessImports {
+		if entry := (goImport{Path: path}); !containsImport(imports, entry) {
+			imports = append(imports, entry)
+		}
+	}
+	sort.Slice(imports, func(i, j int) bool {
+		if imports[i].Path != imports[j].Path {
+			return imports[i].Path < imports[j].Path
+		}
+		return imports[i].Name < imports[j].Name
+	})
+
+	var sb strings.Builder
+	for _, line := range harnessHeader(workflow, componentCode, "Auto-generated Pipeline Program (Go)", harnessCommand(goLanguage{}, workflow), opts) {
+		sb.WriteString(strings.TrimRight("// "+line, " ") + "\n")
+	}
+	sb.WriteString("\npackage main\n\nimport (\n")
+	for _, imp := range imports {
+		if imp.Name != "" {
+			sb.WriteString("\t" + imp.Name + " ")
+		} else {
+			sb.WriteString("\t")
+		}
+		sb.WriteString(strconv.Quote(imp.Path) + "\n")
+	}
+	sb.WriteString(")\n\n")
+
+	sb.WriteString(sectionComment("//", "COMPONENT FUNCTIONS"))
+	if code != "" {
+		sb.WriteString(code + "\n\n")
+	}
+
+	sb.WriteString(sectionComment("//", "PIPELINE EXECUTION"))
+	sb.WriteString(goRuntime)
+
+	seed := "nil"
+	if opts.Seed != nil {
+		seed = fmt.Sprintf("harnessSeed(%d)", *opts.Seed)
+	}
+	sb.WriteString(fmt.Sprintf(`
+func main() {
+	data := flag.String("data", "", "Input data file (CSV, TSV or JSON Lines)")
+	target := flag.String("target", %s, "Target column name")
+	output := flag.String("output", "output.csv", "Output file")
+	flag.Parse()
+	if *data == "" {
+		fmt.Fprintln(os.Stderr, "--data is required")
+		os.Exit(2)
+	}
+
+	if err := harnessRun(*data, *target, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		os.Exit(1)
+	}
+}
+
+func harnessRun(dataFile, target, outputFile string) error {
+	table, err := harnessRead(dataFile)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("✓ Loaded %%d rows from %%s\n", len(table.Rows), dataFile)
+	ctx := Context{TargetColumn: target, Seed: %s}
+`, strconv.Quote(workflow.Target()), seed))
+
+	for _, stage := range stages {
+		sb.WriteString(fmt.Sprintf("\n\t// STAGE %d\n\tctx.Stage = %d\n", stage.Number, stage.Number))
+		for _, step := range stage.Steps {
+			params := make([]string, len(step.Params))
+			for i, param := range step.Params {
+				params[i] = strconv.Quote(param.Name) + ": " + param.Value
+			}
+			sb.WriteString(fmt.Sprintf("\tif table, err = harnessStep(%s, %s, table, Params{%s}, ctx); err != nil {\n\t\treturn err\n\t}\n",
+				step.Name, step.Function, strings.Join(params, ", ")))
+		}
+	}
+
+	sb.WriteString(`
+	if err := harnessWrite(outputFile, table); err != nil {
+		return err
+	}
+	fmt.Printf("✓ Output saved to: %s\n", outputFile)
+	return nil
+}
+`)
+	return []byte(sb.String()), nil
+}
+
+// goRuntime declares the types components use and reads and writes the
+// data. Its helpers are prefixed so they do not clash with components.
+const goRuntime = `// Table is the data passed from component to component
+type Table struct {
+	Columns []string
+	Rows    [][]string
+}
+
+// Params holds the variables of a component
+type Params map[string]any
+
+// Context describes the run to a component
+type Context struct {
+	TargetColumn string
+	Stage        int
+	Seed         *int64 // nil unless the workflow was exported with a seed
+}
+
+// Column returns the index of a column, or -1
+func (t Table) Column(name string) int {
+	for i, column := range t.Columns {
+		if column == name {
+			return i
+		}
+	}
+	return -1
+}
+
+func harnessSeed(seed int64) *int64 {
+	return &seed
+}
+
+func harnessStep(name string, fn func(Table, Params, Context) (Table, error), table Table, params Params, ctx Context) (Table, error) {
+	fmt.Println("→", name)
+	result, err := fn(table, params, ctx)
+	if err != nil {
+		return Table{}, fmt.Errorf("%s: %w", name, err)
+	}
+	return result, nil
+}
+
+func harnessExtension(file string) string {
+	return strings.TrimPrefix(strings.ToLower(filepath.Ext(file)), ".")
+}
+
+func harnessRead(file string) (Table, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return Table{}, err
+	}
+	defer f.Close()
+
+	switch ext := harnessExtension(file); ext {
+	case "jsonl", "ndjson":
+		return harnessReadJSONL(f)
+	case "tsv", "tab", "csv", "txt", "":
+		r := csv.NewReader(f)
+		r.FieldsPerRecord = -1
+		if ext == "tsv" || ext == "tab" {
+			r.Comma = '\t'
+			r.LazyQuotes = true
+		}
+		records, err := r.ReadAll()
+		if err != nil {
+			return Table{}, fmt.Errorf("read %s: %w", file, err)
+		}
+		if len(records) == 0 {
+			return Table{}, nil
+		}
+		records[0][0] = strings.TrimPrefix(records[0][0], "\ufeff")
+		table := Table{Columns: records[0]}
+		for _, record := range records[1:] {
+			row := make([]string, len(table.Columns))
+			copy(row, record)
+			table.Rows = append(table.Rows, row)
+		}
+		return table, nil
+	default:
+		return Table{}, fmt.Errorf("cannot read .%s files, use CSV, TSV or JSON Lines", ext)
+	}
+}
+
+// harnessReadJSONL reads JSON objects, one per line, keeping the keys in
+// order of appearance; nested values are kept as JSON text
+func harnessReadJSONL(r io.Reader) (Table, error) {
+	var table Table
+	index := map[string]int{}
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	for {
+		if tok, err := dec.Token(); errors.Is(err, io.EOF) {
+			return table, nil
+		} else if err != nil {
+			return Table{}, err
+		} else if tok != json.Delim('{') {
+			return Table{}, fmt.Errorf("row %d: expected a JSON object", len(table.Rows)+1)
+		}
+		row := make([]string, len(table.Columns))
+		for dec.More() {
+			key, err := dec.Token()
+			if err != nil {
+				return Table{}, err
+			}
+			var raw json.RawMessage
+			if err := dec.Decode(&raw); err != nil {
+				return Table{}, err
+			}
+			name := key.(string)
+			i, ok := index[name]
+			if !ok {
+				i = len(table.Columns)
+				index[name] = i
+				table.Columns = append(table.Columns, name)
+				row = append(row, "")
+			}
+			var text string
+			if err := json.Unmarshal(raw, &text); err != nil && string(raw) != "null" {
+				text = string(raw)
+			}
+			row[i] = text
+		}
+		if _, err := dec.Token(); err != nil {
+			return Table{}, err
+		}
+		table.Rows = append(table.Rows, row)
+	}
+}
+
+func harnessWrite(file string, table Table) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+
+	switch harnessExtension(file) {
+	case "jsonl", "ndjson":
+		err = harnessWriteJSONL(f, table)
+	default:
+		w := csv.NewWriter(f)
+		if ext := harnessExtension(file); ext == "tsv" || ext == "tab" {
+			w.Comma = '\t'
+		}
+		if err = w.Write(table.Columns); err == nil {
+			err = w.WriteAll(table.Rows)
+		}
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// harnessWriteJSONL writes a JSON object per row; numbers and booleans are
+// written as such and empty cells as null
+func harnessWriteJSONL(w io.Writer, table Table) error {
+	for _, row := range table.Rows {
+		var sb strings.Builder
+		sb.WriteByte('{')
+		for i, column := range table.Columns {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			name, _ := json.Marshal(column)
+			sb.Write(name)
+			sb.WriteString(": ")
+			var cell string
+			if i < len(row) {
+				cell = row[i]
+			}
+			if _, err := strconv.ParseFloat(cell, 64); err == nil && json.Valid([]byte(cell)) || cell == "true" || cell == "false" {
+				sb.WriteString(cell)
+			} else if cell == "" {
+				sb.WriteString("null")
+			} else {
+				value, _ := json.Marshal(cell)
+				sb.Write(value)
+			}
+		}
+		sb.WriteString("}\n")
+		if _, err := io.WriteString(w, sb.String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+`
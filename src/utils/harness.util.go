@@ -0,0 +1,110 @@
+// src/utils/harness.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// harnessStep is a component call of a JavaScript or Go harness
+type harnessStep struct {
+	Stage int
+	// Name is the display name as a string literal of the harness language
+	Name     string
+	Function string
+	Params   []kwarg
+}
+
+// harnessStage is a stage with at least one step
+type harnessStage struct {
+	Number int
+	Steps  []harnessStep
+}
+
+// harnessSyntax is what a harness generator renders in its language
+type harnessSyntax struct {
+	// Language is the name of the language and Title how it is written
+	Language string
+	Title    string
+	// IsName reports whether a function name can be called as is
+	IsName func(name string) bool
+	Quote  func(s string) string
+	Render func(value interface{}) (string, error)
+}
+
+// harnessStages lays out the calls of the workflow's nodes stage by stage,
+// rendering names and variables with syntax
+func harnessStages(workflow WorkflowConfig, syntax harnessSyntax) ([]harnessStage, error) {
+	var stages []harnessStage
+	groups, err := organizeByStage(workflow.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		stage := harnessStage{Number: group.Number}
+		for _, node := range group.Nodes {
+			compName, funcName := componentNames(node)
+			if !syntax.IsName(funcName) {
+				return nil, &NodeError{NodeID: node.ID, Field: "code", Message: fmt.Sprintf("%q is not a valid %s function name", funcName, syntax.Title), Language: syntax.Language}
+			}
+			params, err := nodeParams(node, syntax.Render)
+			if nodeErr, ok := err.(*NodeError); ok {
+				nodeErr.Language = syntax.Language
+			}
+			if err != nil {
+				return nil, err
+			}
+			stage.Steps = append(stage.Steps, harnessStep{
+				Stage:    group.Number,
+				Name:     syntax.Quote(commentText(compName)),
+				Function: funcName,
+				Params:   params,
+			})
+		}
+		stages = append(stages, stage)
+	}
+	return stages, nil
+}
+
+// harnessHeader renders the header comment lines of a harness, without
+// comment markers
+func harnessHeader(workflow WorkflowConfig, componentCode, title, run string, opts GenerateOptions) []string {
+	return []string{
+		title,
+		"Generated at: " + opts.timestamp(),
+		"Version: " + commentText(workflow.Version),
+		fmt.Sprintf("Total Components: %d", len(workflow.Nodes)),
+		"Workflow Hash: " + WorkflowHash(workflow, componentCode),
+		"",
+		"Run:",
+		"    " + run,
+	}
+}
+
+// harnessCommand renders the command line of a harness in its header
+func harnessCommand(l Language, workflow WorkflowConfig) string {
+	return strings.NewReplacer(
+		"{file}", "workflow"+l.Extension(),
+		"{data}", "data"+dataExtension(workflow),
+		"{target}", shellQuote(workflow.Target()),
+		"{output}", "output.csv",
+	).Replace(l.Runtime().Command())
+}
+
+// dataExtension returns the extension of the workflow's data file
+func dataExtension(workflow WorkflowConfig) string {
+	return strings.TrimPrefix(dataFileName(workflow), "data")
+}
+
+// harnessFormats are the data formats the JavaScript and Go harnesses
+// read and write; the others need pandas
+var harnessFormats = []string{FormatCSV, FormatTSV, FormatJSONL}
+
+// SupportsDataFormat reports whether programs generated for language read
+// data of the dataset format
+func SupportsDataFormat(language, format string) bool {
+	if language == "" || language == LanguagePython || format == "" {
+		return true
+	}
+	return containsString(harnessFormats, format)
+}
@@ -0,0 +1,521 @@
+// src/utils/javascriptGenerator.util.go
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// javascriptLanguage runs JavaScript components in a Node.js harness
+type javascriptLanguage struct{}
+
+func (javascriptLanguage) Name() string        { return LanguageJavaScript }
+func (javascriptLanguage) Extension() string   { return ".js" }
+func (javascriptLanguage) ContentType() string { return "text/javascript; charset=utf-8" }
+
+func (javascriptLanguage) FunctionName(code string) string {
+	return JavaScriptFunctionName(code)
+}
+
+func (javascriptLanguage) Generate(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	return GenerateJavaScript(workflow, componentCode, opts)
+}
+
+func (javascriptLanguage) Requirements(workflow WorkflowConfig, componentCode string) []string {
+	return JavaScriptRequirements(workflow, componentCode)
+}
+
+func (javascriptLanguage) Manifest(requirements []string) (string, []byte) {
+	if len(requirements) == 0 {
+		return "", nil
+	}
+	dependencies := make(map[string]string, len(requirements))
+	for _, requirement := range requirements {
+		dependencies[requirement] = "*"
+	}
+	manifest, _ := json.MarshalIndent(map[string]interface{}{
+		"name":         "builder-workflow",
+		"private":      true,
+		"dependencies": dependencies,
+	}, "", "  ")
+	return "package.json", append(manifest, '\n')
+}
+
+func (javascriptLanguage) CheckSyntax(code string) []Diagnostic {
+	return checkJavaScriptSyntax(code)
+}
+
+func (javascriptLanguage) Runtime() Runtime {
+	return Runtime{
+		Image:          "node:20-slim",
+		DebianPackages: []string{"nodejs", "npm"},
+		Install:        "npm install --omit=dev --no-audit --no-fund",
+		Run:            "node",
+		Check:          []string{"node", "-e", javascriptCheckProgram},
+	}
+}
+
+// jsFunctionPattern matches the first top-level function declaration, or a
+// top-level function or arrow function assigned to a binding
+var jsFunctionPattern = regexp.MustCompile(`(?m)^(?:export\s+)?(?:(?:async\s+)?function\s*\*?\s*([A-Za-z_$][\w$]*)\s*\(|(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s+)?(?:function\b|\(|[A-Za-z_$][\w$]*\s*=>))`)
+
+// JavaScriptFunctionName returns the name of the first top-level function
+// in JavaScript code, or "" when there is none
+func JavaScriptFunctionName(code string) string {
+	if m := jsFunctionPattern.FindStringSubmatch(code); m != nil {
+		if m[1] != "" {
+			return m[1]
+		}
+		return m[2]
+	}
+	return ""
+}
+
+var jsReservedWords = map[string]bool{
+	"await": true, "break": true, "case": true, "catch": true, "class": true, "const": true,
+	"continue": true, "debugger": true, "default": true, "delete": true, "do": true, "else": true,
+	"enum": true, "export": true, "extends": true, "false": true, "finally": true, "for": true,
+	"function": true, "if": true, "implements": true, "import": true, "in": true, "instanceof": true,
+	"interface": true, "let": true, "new": true, "null": true, "package": true, "private": true,
+	"protected": true, "public": true, "return": true, "static": true, "super": true, "switch": true,
+	"this": true, "throw": true, "true": true, "try": true, "typeof": true, "var": true,
+	"void": true, "while": true, "with": true, "yield": true,
+}
+
+var jsIdentifierPattern = regexp.MustCompile(`^[A-Za-z_$][\w$]*$`)
+
+// isJavaScriptName reports whether s can be used as a JavaScript binding
+func isJavaScriptName(s string) bool {
+	return jsIdentifierPattern.MatchString(s) && !jsReservedWords[s]
+}
+
+// jsImportPattern matches ES module imports, which CommonJS cannot load
+var jsImportPattern = regexp.MustCompile(`(?m)^[ \t]*import\s*(?:[\w$*{'"])`)
+
+// jsExportPattern matches the export keyword of top-level declarations
+var jsExportPattern = regexp.MustCompile(`(?m)^export\s+(?:default\s+)?((?:async\s+)?function\b|class\b|const\b|let\b|var\b)`)
+
+// jsRequirePattern matches require() calls with a string literal
+var jsRequirePattern = regexp.MustCompile(`\brequire\s*\(\s*['"]([^'"]+)['"]\s*\)`)
+
+// jsRequireLine matches a top-level statement binding a required module
+var jsRequireLine = regexp.MustCompile(`^(?:const|let|var)\s+[^=]+=\s*require\s*\(\s*['"][^'"]+['"]\s*\)(?:\.[\w$]+)*;?\s*$`)
+
+// nodeBuiltins are the modules that come with Node.js
+var nodeBuiltins = map[string]bool{
+	"assert": true, "async_hooks": true, "buffer": true, "child_process": true, "cluster": true,
+	"console": true, "constants": true, "crypto": true, "dgram": true, "dns": true, "events": true,
+	"fs": true, "http": true, "http2": true, "https": true, "module": true, "net": true, "os": true,
+	"path": true, "perf_hooks": true, "process": true, "querystring": true, "readline": true,
+	"stream": true, "string_decoder": true, "timers": true, "tls": true, "tty": true, "url": true,
+	"util": true, "v8": true, "vm": true, "worker_threads": true, "zlib": true,
+}
+
+// JavaScriptRequirements returns the npm packages required by the
+// component code, and apache-arrow when stages exchange Arrow files
+func JavaScriptRequirements(workflow WorkflowConfig, componentCode string) []string {
+	var packages []string
+	for _, m := range jsRequirePattern.FindAllStringSubmatch(componentCode, -1) {
+		if name := npmPackage(m[1]); name != "" && !containsString(packages, name) {
+			packages = append(packages, name)
+		}
+	}
+	if workflow.Bridge == BridgeArrow && !containsString(packages, "apache-arrow") {
+		packages = append(packages, "apache-arrow")
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// npmPackage returns the package a require() specifier loads, or "" for
+// relative paths and Node.js built-in modules
+func npmPackage(specifier string) string {
+	if strings.HasPrefix(specifier, ".") || strings.HasPrefix(specifier, "/") || strings.HasPrefix(specifier, "node:") {
+		return ""
+	}
+	parts := strings.Split(specifier, "/")
+	if strings.HasPrefix(specifier, "@") {
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[0] + "/" + parts[1]
+	}
+	if nodeBuiltins[parts[0]] {
+		return ""
+	}
+	return parts[0]
+}
+
+// prepareJavaScript turns component code into CommonJS the harness can
+// include: export keywords are dropped and repeated require() bindings,
+// common when components are pasted together, are kept once
+func prepareJavaScript(code string) (string, error) {
+	if m := jsImportPattern.FindStringIndex(code); m != nil {
+		line := strings.Count(code[:m[0]], "\n") + 1
+		return "", &CodeError{Language: LanguageJavaScript, Message: fmt.Sprintf("line %d: import statements are not supported, use require()", line)}
+	}
+	code = jsExportPattern.ReplaceAllString(code, "$1")
+
+	seen := map[string]bool{}
+	lines := strings.Split(code, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if jsRequireLine.MatchString(line) {
+			key := strings.TrimSuffix(strings.TrimSpace(line), ";")
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		kept = append(kept, line)
+	}
+	return strings.Join(kept, "\n"), nil
+}
+
+// jsQuote renders s as a JavaScript string literal
+func jsQuote(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// maxSafeInteger is the largest integer a JavaScript number holds exactly
+var maxSafeInteger = big.NewInt(1<<53 - 1)
+
+// renderJavaScript renders a parsed literal as JavaScript source
+func renderJavaScript(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case pyInt:
+		n, _ := new(big.Int).SetString(string(v), 10)
+		if new(big.Int).Abs(n).Cmp(maxSafeInteger) > 0 {
+			return "", fmt.Errorf("%s is too large for a JavaScript number", v)
+		}
+		return string(v), nil
+	case pyFloat:
+		return string(v), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("must be a finite number")
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	case string:
+		return jsQuote(v), nil
+	case []interface{}:
+		return renderJavaScriptItems(v)
+	case pyTuple:
+		return renderJavaScriptItems(v)
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dict := make(pyDict, len(keys))
+		for i, key := range keys {
+			dict[i] = pyItem{Key: key, Value: v[key]}
+		}
+		return renderJavaScript(dict)
+	case pyDict:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			key, ok := item.Key.(string)
+			if !ok {
+				return "", fmt.Errorf("dict keys must be strings in JavaScript, got %s", pyTypeName(item.Key))
+			}
+			val, err := renderJavaScript(item.Value)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = jsQuote(key) + ": " + val
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func renderJavaScriptItems(items []interface{}) (string, error) {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		rendered, err := renderJavaScript(item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = rendered
+	}
+	return "[" + strings.Join(parts, ", ") + "]", nil
+}
+
+// GenerateJavaScript generates a Node.js program that reads --data, calls
+// the JavaScript components stage by stage and writes --output. A
+// component is called as fn(rows, params, context), where rows is an array
+// of objects keyed by column, and returns the new rows, or a promise of
+// them; returning nothing keeps the rows it was given.
+func GenerateJavaScript(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	code, err := prepareJavaScript(componentCode)
+	if err != nil {
+		return nil, err
+	}
+	stages, err := harnessStages(workflow, harnessSyntax{
+		Language: LanguageJavaScript,
+		Title:    "JavaScript",
+		IsName:   isJavaScriptName,
+		Quote:    jsQuote,
+		Render:   renderJavaScript,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	sb.WriteString("#!/usr/bin/env node\n/*\n")
+	for _, line := range harnessHeader(workflow, componentCode, "Auto-generated Pipeline Script (Node.js)", harnessCommand(javascriptLanguage{}, workflow), opts) {
+		sb.WriteString(strings.TrimRight(" * "+strings.ReplaceAll(line, "*/", "* /"), " ") + "\n")
+	}
+	sb.WriteString(" */\n\n")
+
+	sb.WriteString(sectionComment("//", "COMPONENT FUNCTIONS"))
+	sb.WriteString(strings.Trim(code, "\n") + "\n\n")
+
+	sb.WriteString(sectionComment("//", "PIPELINE EXECUTION"))
+	sb.WriteString("(function () {\n  'use strict';\n")
+	sb.WriteString(javascriptRuntime)
+
+	seed := "null"
+	if opts.Seed != nil {
+		seed = strconv.FormatInt(*opts.Seed, 10)
+	}
+	sb.WriteString(fmt.Sprintf(`
+  async function main() {
+    const args = parseArgs(process.argv.slice(2), { data: null, target: %s, output: 'output.csv' });
+    if (!args.data) {
+      throw new Error('--data is required');
+    }
+
+    let rows = readData(args.data);
+    console.log(`+"`✓ Loaded ${rows.length} rows from ${args.data}`"+`);
+    const context = { targetColumn: args.target, stage: 0, seed: %s };
+`, jsQuote(workflow.Target()), seed))
+
+	for _, stage := range stages {
+		sb.WriteString(fmt.Sprintf("\n    // STAGE %d\n    context.stage = %d;\n", stage.Number, stage.Number))
+		for _, step := range stage.Steps {
+			params := make([]string, len(step.Params))
+			for i, param := range step.Params {
+				params[i] = jsQuote(param.Name) + ": " + param.Value
+			}
+			sb.WriteString(fmt.Sprintf("    rows = await runComponent(%s, %s, rows, {%s}, context);\n", step.Name, step.Function, strings.Join(params, ", ")))
+		}
+	}
+
+	sb.WriteString(`
+    writeData(rows, args.output);
+    console.log(` + "`✓ Output saved to: ${args.output}`" + `);
+  }
+
+  main().catch((err) => {
+    console.error(err && err.stack ? err.stack : err);
+    process.exit(1);
+  });
+})();
+`)
+	return []byte(sb.String()), nil
+}
+
+// sectionComment renders the banner comments that divide generated code
+func sectionComment(marker, title string) string {
+	rule := marker + " " + strings.Repeat("=", 60) + "\n"
+	return rule + marker + " " + title + "\n" + rule + "\n"
+}
+
+// javascriptRuntime reads and writes the data and calls the components. It
+// runs inside a function so its names do not clash with the components'.
+const javascriptRuntime = `
+  const fs = require('fs');
+  const path = require('path');
+
+  function parseArgs(argv, defaults) {
+    const args = Object.assign({}, defaults);
+    for (let i = 0; i < argv.length; i++) {
+      const match = /^--([a-z-]+)(?:=([\s\S]*))?$/.exec(argv[i]);
+      if (!match || !(match[1] in args)) {
+        throw new Error(` + "`unknown argument ${argv[i]}`" + `);
+      }
+      const value = match[2] !== undefined ? match[2] : argv[++i];
+      if (value === undefined) {
+        throw new Error(` + "`--${match[1]} needs a value`" + `);
+      }
+      args[match[1]] = value;
+    }
+    return args;
+  }
+
+  function extensionOf(file) {
+    return path.extname(String(file)).slice(1).toLowerCase();
+  }
+
+  function arrowModule() {
+    try {
+      return require('apache-arrow');
+    } catch (err) {
+      throw new Error('Arrow files need the apache-arrow package: npm install apache-arrow');
+    }
+  }
+
+  // parseDelimited splits text into records; CSV fields may be quoted
+  function parseDelimited(text, delimiter) {
+    const records = [];
+    let record = [];
+    let field = '';
+    let quoted = false;
+    for (let i = 0; i < text.length; i++) {
+      const ch = text[i];
+      if (quoted) {
+        if (ch === '"' && text[i + 1] === '"') {
+          field += '"';
+          i++;
+        } else if (ch === '"') {
+          quoted = false;
+        } else {
+          field += ch;
+        }
+      } else if (ch === '"' && field === '' && delimiter === ',') {
+        quoted = true;
+      } else if (ch === delimiter) {
+        record.push(field);
+        field = '';
+      } else if (ch === '\n' || ch === '\r') {
+        if (ch === '\r' && text[i + 1] === '\n') {
+          i++;
+        }
+        record.push(field);
+        records.push(record);
+        record = [];
+        field = '';
+      } else {
+        field += ch;
+      }
+    }
+    if (field !== '' || record.length > 0) {
+      record.push(field);
+      records.push(record);
+    }
+    return records.filter((r) => r.length > 1 || r[0] !== '');
+  }
+
+  // cellValue reads empty cells as null and numeric cells as numbers
+  function cellValue(text) {
+    if (text === '') {
+      return null;
+    }
+    return /^[-+]?(\d+\.?\d*|\.\d+)([eE][-+]?\d+)?$/.test(text) ? Number(text) : text;
+  }
+
+  function readData(file) {
+    const ext = extensionOf(file);
+    if (ext === 'arrow' || ext === 'feather') {
+      const arrow = arrowModule();
+      return arrow.tableFromIPC(fs.readFileSync(file)).toArray().map((row) => {
+        const record = {};
+        for (const [key, value] of Object.entries(row.toJSON())) {
+          record[key] = typeof value === 'bigint' ? Number(value) : value;
+        }
+        return record;
+      });
+    }
+
+    const text = fs.readFileSync(file, 'utf8').replace(/^\uFEFF/, '');
+    if (ext === 'jsonl' || ext === 'ndjson') {
+      const rows = [];
+      text.split(/\r?\n/).forEach((line, i) => {
+        if (line.trim() === '') {
+          return;
+        }
+        const row = JSON.parse(line);
+        if (row === null || typeof row !== 'object' || Array.isArray(row)) {
+          throw new Error(` + "`${file} line ${i + 1}: expected a JSON object`" + `);
+        }
+        rows.push(row);
+      });
+      return rows;
+    }
+    if (!['csv', 'tsv', 'tab', 'txt', ''].includes(ext)) {
+      throw new Error(` + "`cannot read .${ext} files, use CSV, TSV, JSON Lines or Arrow`" + `);
+    }
+
+    const [header, ...records] = parseDelimited(text, ext === 'tsv' || ext === 'tab' ? '\t' : ',');
+    if (!header) {
+      return [];
+    }
+    return records.map((record) => {
+      const row = {};
+      header.forEach((name, i) => {
+        row[name] = cellValue(record[i] === undefined ? '' : record[i]);
+      });
+      return row;
+    });
+  }
+
+  // columnsOf returns the keys of the rows in order of appearance
+  function columnsOf(rows) {
+    const columns = new Set();
+    for (const row of rows) {
+      Object.keys(row).forEach((key) => columns.add(key));
+    }
+    return [...columns];
+  }
+
+  function formatCell(value, delimiter) {
+    if (value === null || value === undefined) {
+      return '';
+    }
+    const text = typeof value === 'object' && !(value instanceof Date) ? JSON.stringify(value) : String(value);
+    if (delimiter === '\t') {
+      return text.replace(/[\t\r\n]/g, ' ');
+    }
+    return /[",\r\n]/.test(text) ? '"' + text.replace(/"/g, '""') + '"' : text;
+  }
+
+  function writeData(rows, file) {
+    const ext = extensionOf(file);
+    if (ext === 'arrow' || ext === 'feather') {
+      const arrow = arrowModule();
+      fs.writeFileSync(file, arrow.tableToIPC(arrow.tableFromJSON(rows), 'file'));
+      return;
+    }
+    if (ext === 'jsonl' || ext === 'ndjson') {
+      fs.writeFileSync(file, rows.map((row) => JSON.stringify(row) + '\n').join(''));
+      return;
+    }
+
+    const delimiter = ext === 'tsv' || ext === 'tab' ? '\t' : ',';
+    const columns = columnsOf(rows);
+    const lines = [columns.map((c) => formatCell(c, delimiter)).join(delimiter)];
+    for (const row of rows) {
+      lines.push(columns.map((c) => formatCell(row[c], delimiter)).join(delimiter));
+    }
+    fs.writeFileSync(file, lines.join('\n') + '\n');
+  }
+
+  // runComponent calls a component; returning nothing keeps the rows
+  async function runComponent(name, fn, rows, params, context) {
+    console.log(` + "`→ ${name}`" + `);
+    const result = await fn(rows, params, context);
+    if (result === undefined) {
+      return rows;
+    }
+    if (!Array.isArray(result)) {
+      throw new Error(` + "`${name} must return an array of rows, got ${typeof result}`" + `);
+    }
+    return result;
+  }
+`
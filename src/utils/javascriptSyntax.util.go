@@ -0,0 +1,155 @@
+// src/utils/javascriptSyntax.util.go
+package utils
+
+import "strings"
+
+// jsRegexKeywords are the keywords after which a slash starts a regular
+// expression rather than a division
+var jsRegexKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true, "new": true,
+	"delete": true, "void": true, "throw": true, "case": true, "do": true, "else": true,
+	"yield": true, "await": true,
+}
+
+// openTemplate is a template literal inside one of its ${} substitutions
+type openTemplate struct {
+	start int // offset of the opening backtick
+	depth int // bracket depth outside the substitution
+}
+
+// checkJavaScriptSyntax tokenizes JavaScript code when node is not
+// available to compile it. It reports unterminated strings, template
+// literals, comments and regular expressions and unbalanced brackets, not
+// invalid statements.
+func checkJavaScriptSyntax(code string) []Diagnostic {
+	s := &syntaxScanner{code: code}
+	var templates []openTemplate
+	regexAllowed := true // a slash here starts a regular expression
+
+	for i := 0; i < len(code); {
+		ch := code[i]
+		switch {
+		case strings.HasPrefix(code[i:], "//"):
+			i = lineEnd(code, i)
+		case strings.HasPrefix(code[i:], "/*"):
+			end := strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				s.report(i, "unterminated comment")
+				return s.diagnostics
+			}
+			i += end + 4
+		case ch == '"' || ch == '\'':
+			i = javascriptString(s, i)
+			regexAllowed = false
+		case ch == '`':
+			i = javascriptTemplate(s, &templates, i, i+1)
+			regexAllowed = false
+		case ch == '/' && regexAllowed:
+			i = javascriptRegex(s, i)
+			regexAllowed = false
+		case ch == '}' && len(templates) > 0 && s.depth() == templates[len(templates)-1].depth+1:
+			// the end of a substitution resumes its template literal
+			s.close(i)
+			t := templates[len(templates)-1]
+			templates = templates[:len(templates)-1]
+			i = javascriptTemplate(s, &templates, t.start, i+1)
+			regexAllowed = false
+		case ch == '(' || ch == '[' || ch == '{':
+			s.open(i)
+			regexAllowed = true
+			i++
+		case ch == ')' || ch == ']' || ch == '}':
+			s.close(i)
+			regexAllowed = ch == '}'
+			i++
+		case ch == '_' || ch == '$' || ch >= 0x80 || 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || '0' <= ch && ch <= '9':
+			j := i + 1
+			for j < len(code) && (code[j] == '_' || code[j] == '$' || code[j] == '.' && '0' <= ch && ch <= '9' || code[j] >= 0x80 ||
+				'a' <= code[j] && code[j] <= 'z' || 'A' <= code[j] && code[j] <= 'Z' || '0' <= code[j] && code[j] <= '9') {
+				j++
+			}
+			regexAllowed = jsRegexKeywords[code[i:j]]
+			i = j
+		case ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r':
+			i++
+		default:
+			regexAllowed = true
+			i++
+		}
+	}
+
+	for _, t := range templates {
+		s.report(t.start, "unterminated template literal")
+	}
+	if len(templates) == 0 {
+		s.unclosed()
+	}
+	return s.diagnostics
+}
+
+// javascriptString skips the string literal opening at offset i
+func javascriptString(s *syntaxScanner, i int) int {
+	code := s.code
+	for j := i + 1; j < len(code); j++ {
+		switch code[j] {
+		case '\\':
+			j++
+		case code[i]:
+			return j + 1
+		case '\n':
+			s.report(i, "unterminated string literal")
+			return j
+		}
+	}
+	s.report(i, "unterminated string literal")
+	return len(code)
+}
+
+// javascriptTemplate skips the text of the template literal opened at start
+// from offset i, up to its end or the next ${, which opens a substitution
+func javascriptTemplate(s *syntaxScanner, templates *[]openTemplate, start, i int) int {
+	code := s.code
+	for ; i < len(code); i++ {
+		switch {
+		case code[i] == '\\':
+			i++
+		case code[i] == '`':
+			return i + 1
+		case strings.HasPrefix(code[i:], "${"):
+			*templates = append(*templates, openTemplate{start: start, depth: s.depth()})
+			s.open(i + 1)
+			return i + 2
+		}
+	}
+	s.report(start, "unterminated template literal")
+	return len(code)
+}
+
+// javascriptRegex skips the regular expression literal opening at offset i
+// and its flags
+func javascriptRegex(s *syntaxScanner, i int) int {
+	code := s.code
+	inClass := false
+	for j := i + 1; j < len(code); j++ {
+		switch code[j] {
+		case '\\':
+			j++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if inClass {
+				continue
+			}
+			for j++; j < len(code) && ('a' <= code[j] && code[j] <= 'z'); j++ {
+			}
+			return j
+		case '\n':
+			s.report(i, "unterminated regular expression")
+			return j
+		}
+	}
+	s.report(i, "unterminated regular expression")
+	return len(code)
+}
@@ -0,0 +1,262 @@
+// src/utils/language.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Component languages
+const (
+	LanguagePython     = "python"
+	LanguageJavaScript = "javascript"
+	LanguageGo         = "go"
+)
+
+// DefaultLanguage is the language of nodes and components without one
+const DefaultLanguage = LanguagePython
+
+// Languages lists the component languages
+var Languages = []string{LanguagePython, LanguageJavaScript, LanguageGo}
+
+// Bridges pass data between the stages of a workflow whose components are
+// in different languages, as files of the bridge format
+const (
+	BridgeCSV   = "csv"
+	BridgeArrow = "arrow"
+)
+
+// Bridges lists the bridge formats
+var Bridges = []string{BridgeCSV, BridgeArrow}
+
+// IsValidBridge reports whether name is a bridge format
+func IsValidBridge(name string) bool {
+	return containsString(Bridges, name)
+}
+
+// bridgeExtensions are the file extensions of the bridge formats
+var bridgeExtensions = map[string]string{BridgeCSV: ".csv", BridgeArrow: ".arrow"}
+
+// Language generates the program that runs a workflow whose components are
+// all written in it, and describes how the program is installed and run
+type Language interface {
+	Name() string
+	// Extension and ContentType describe the generated program
+	Extension() string
+	ContentType() string
+	// FunctionName returns the function a component's code defines, or ""
+	FunctionName(code string) string
+	// Generate renders the program: the component code and a main that
+	// reads --data, runs the nodes stage by stage and writes --output
+	Generate(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error)
+	// Requirements returns the packages the program needs installed
+	Requirements(workflow WorkflowConfig, componentCode string) []string
+	// Manifest renders the file that installs requirements; an empty name
+	// means the language has nothing to install
+	Manifest(requirements []string) (name string, content []byte)
+	// CheckSyntax checks code in-process, for when the runtime's Check
+	// command is not installed
+	CheckSyntax(code string) []Diagnostic
+	Runtime() Runtime
+}
+
+// Runtime describes how the programs of a language are installed and run
+type Runtime struct {
+	// Image is the Docker image with the runtime, and DebianPackages the
+	// packages that add it to the image of another language
+	Image          string
+	DebianPackages []string
+	// Install installs the manifest in a Dockerfile RUN step
+	Install string
+	// Run is the command that runs a program file
+	Run string
+	// Check compiles code read from stdin without running it and prints
+	// its diagnostics as a JSON array; empty when CheckSyntax is exact
+	Check []string
+}
+
+// Command returns the command line running a program on a data file;
+// {file}, {data}, {target} and {output} are to be replaced. Every
+// generated program takes the same options.
+func (r Runtime) Command() string {
+	return r.Run + " {file} --data {data} --target {target} --output {output}"
+}
+
+var languages = map[string]Language{}
+
+// RegisterLanguage makes a component language available by name
+func RegisterLanguage(l Language) {
+	languages[l.Name()] = l
+}
+
+// GetLanguage returns a registered language; empty means DefaultLanguage
+func GetLanguage(name string) (Language, bool) {
+	if name == "" {
+		name = DefaultLanguage
+	}
+	l, ok := languages[name]
+	return l, ok
+}
+
+// IsValidLanguage reports whether name is a registered language
+func IsValidLanguage(name string) bool {
+	_, ok := languages[name]
+	return ok
+}
+
+// NodeLanguage returns the language of a node's component
+func NodeLanguage(node Node) string {
+	if node.Language == "" {
+		return DefaultLanguage
+	}
+	return node.Language
+}
+
+// CodeError reports component code a generator cannot read
+type CodeError struct {
+	Language string
+	Message  string
+}
+
+func (e *CodeError) Error() string {
+	return fmt.Sprintf("%s code: %s", e.Language, e.Message)
+}
+
+// segment is a run of consecutive stages whose components share a language
+type segment struct {
+	Language string
+	Stages   []int
+	Nodes    []Node
+}
+
+// name returns the base name of the segment's program, e.g. "stage1-2"
+func (s segment) name() string {
+	if len(s.Stages) == 1 {
+		return fmt.Sprintf("stage%d", s.Stages[0])
+	}
+	return fmt.Sprintf("stage%d-%d", s.Stages[0], s.Stages[len(s.Stages)-1])
+}
+
+// WorkflowLanguage returns the language every node of the workflow is
+// written in, or "" when the workflow mixes languages over its bridge. A
+// workflow without nodes is in DefaultLanguage. Mixing languages without a
+// bridge, or inside one stage, is rejected with a *NodeError.
+func WorkflowLanguage(workflow WorkflowConfig) (string, error) {
+	segments, err := languageSegments(workflow)
+	if err != nil {
+		return "", err
+	}
+	switch len(segments) {
+	case 0:
+		return DefaultLanguage, nil
+	case 1:
+		return segments[0].Language, nil
+	}
+	return "", nil
+}
+
+// languageSegments splits the workflow's stages into segments of one
+// language, checking the languages and the bridge on the way
+func languageSegments(workflow WorkflowConfig) ([]segment, error) {
+	if workflow.Bridge != "" && !IsValidBridge(workflow.Bridge) {
+		return nil, fmt.Errorf("unknown bridge %q, must be one of: %s", workflow.Bridge, strings.Join(Bridges, ", "))
+	}
+
+	var segments []segment
+	groups, err := organizeByStage(workflow.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		stageNum, nodes := group.Number, group.Nodes
+		language := NodeLanguage(nodes[0])
+		for _, node := range nodes {
+			if !IsValidLanguage(NodeLanguage(node)) {
+				return nil, &NodeError{NodeID: node.ID, Field: "language", Message: fmt.Sprintf("invalid language %q, must be one of: %s", node.Language, strings.Join(Languages, ", "))}
+			}
+			if NodeLanguage(node) != language {
+				return nil, &NodeError{NodeID: node.ID, Field: "language", Message: fmt.Sprintf(
+					"is %s but stage %d also runs %s components; the components of a stage must share a language", NodeLanguage(node), stageNum, language)}
+			}
+		}
+
+		if n := len(segments); n > 0 && segments[n-1].Language == language {
+			segments[n-1].Stages = append(segments[n-1].Stages, stageNum)
+			segments[n-1].Nodes = append(segments[n-1].Nodes, nodes...)
+			continue
+		}
+		if len(segments) > 0 && workflow.Bridge == "" {
+			return nil, &NodeError{NodeID: nodes[0].ID, Field: "language", Message: fmt.Sprintf(
+				"is %s but earlier stages run %s components; configure a bridge (%s) to pass data between languages",
+				language, segments[len(segments)-1].Language, strings.Join(Bridges, ", "))}
+		}
+		segments = append(segments, segment{Language: language, Stages: []int{stageNum}, Nodes: nodes})
+	}
+
+	if len(segments) > 1 && workflow.Bridge == BridgeArrow {
+		for _, s := range segments {
+			if s.Language == LanguageGo {
+				return nil, &NodeError{NodeID: s.Nodes[0].ID, Field: "language", Message: "is go, whose harness exchanges data as CSV only; use the csv bridge"}
+			}
+		}
+	}
+	return segments, nil
+}
+
+// WorkflowLanguages returns the languages of the workflow's nodes in the
+// order of Languages
+func WorkflowLanguages(workflow WorkflowConfig) []string {
+	var names []string
+	for _, name := range Languages {
+		for _, node := range workflow.Nodes {
+			if NodeLanguage(node) == name {
+				names = append(names, name)
+				break
+			}
+		}
+	}
+	return names
+}
+
+// pythonLanguage runs components through the executable pipeline script
+type pythonLanguage struct{}
+
+func (pythonLanguage) Name() string        { return LanguagePython }
+func (pythonLanguage) Extension() string   { return ".py" }
+func (pythonLanguage) ContentType() string { return "text/x-python; charset=utf-8" }
+
+func (pythonLanguage) FunctionName(code string) string {
+	return FunctionName(code)
+}
+
+func (pythonLanguage) Generate(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	script, err := GenerateExecutableScript(workflow, componentCode, opts)
+	return []byte(script), err
+}
+
+func (pythonLanguage) Requirements(workflow WorkflowConfig, componentCode string) []string {
+	return WorkflowRequirements(workflow, componentCode)
+}
+
+func (pythonLanguage) Manifest(requirements []string) (string, []byte) {
+	return "requirements.txt", []byte(strings.Join(requirements, "\n") + "\n")
+}
+
+func (pythonLanguage) CheckSyntax(code string) []Diagnostic {
+	return checkPythonSyntax(code)
+}
+
+func (pythonLanguage) Runtime() Runtime {
+	return Runtime{
+		Image:   "python:3.11-slim",
+		Install: "pip install --no-cache-dir -r requirements.txt",
+		Run:     "python",
+		Check:   []string{"python3", "-c", pythonCheckProgram},
+	}
+}
+
+func init() {
+	RegisterLanguage(pythonLanguage{})
+	RegisterLanguage(javascriptLanguage{})
+	RegisterLanguage(goLanguage{})
+}
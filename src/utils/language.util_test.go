@@ -0,0 +1,190 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// languageWorkflow returns a workflow running one node per language given,
+// stage by stage
+func languageWorkflow(bridge string, languages ...string) WorkflowConfig {
+	workflow := WorkflowConfig{Version: "1.0", TargetColumn: "y", Bridge: bridge}
+	for i, language := range languages {
+		workflow.Nodes = append(workflow.Nodes, Node{
+			ID:       string(rune('a' + i)),
+			Name:     "Step",
+			Stage:    i + 1,
+			Language: language,
+			Code:     map[string]string{"": "step", LanguagePython: "step", LanguageJavaScript: "step", LanguageGo: "Step"}[language],
+		})
+	}
+	return workflow
+}
+
+func TestWorkflowLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		workflow WorkflowConfig
+		want     string
+		wantErr  string
+	}{
+		{"no nodes", WorkflowConfig{}, LanguagePython, ""},
+		{"default", languageWorkflow("", "", LanguagePython), LanguagePython, ""},
+		{"javascript", languageWorkflow("", LanguageJavaScript, LanguageJavaScript), LanguageJavaScript, ""},
+		{"bridged", languageWorkflow(BridgeCSV, LanguagePython, LanguageGo), "", ""},
+		{"no bridge", languageWorkflow("", LanguagePython, LanguageJavaScript), "", "configure a bridge"},
+		{"unknown bridge", languageWorkflow("xml", LanguagePython), "", "unknown bridge"},
+		{"unknown language", languageWorkflow("", "ruby"), "", "invalid language"},
+		{"arrow with go", languageWorkflow(BridgeArrow, LanguageJavaScript, LanguageGo), "", "use the csv bridge"},
+		{"mixed stage", WorkflowConfig{Nodes: []Node{
+			{ID: "a", Stage: 1, Code: "f"},
+			{ID: "b", Stage: 1, Code: "g", Language: LanguageJavaScript},
+		}}, "", "must share a language"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := WorkflowLanguage(tt.workflow)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("WorkflowLanguage() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("WorkflowLanguage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFunctionNames(t *testing.T) {
+	tests := []struct {
+		language string
+		code     string
+		want     string
+	}{
+		{LanguageJavaScript, "const fs = require('fs');\nfunction clean(rows, params) {\n  return rows;\n}", "clean"},
+		{LanguageJavaScript, "async function load(rows) { return rows }", "load"},
+		{LanguageJavaScript, "const scale = (rows) => rows;", "scale"},
+		{LanguageJavaScript, "// no component here", ""},
+		{LanguageGo, "package main\n\nfunc Scale(t Table, p Params, c Context) (Table, error) {\n\treturn t, nil\n}\n\nfunc helper() {}", "Scale"},
+		{LanguageGo, "func (t Table) Width() int { return 0 }", ""},
+	}
+
+	for _, tt := range tests {
+		language, _ := GetLanguage(tt.language)
+		if got := language.FunctionName(tt.code); got != tt.want {
+			t.Errorf("%s FunctionName(%q) = %q, want %q", tt.language, tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateJavaScript(t *testing.T) {
+	workflow := languageWorkflow("", LanguageJavaScript)
+	workflow.Nodes[0].Variables = map[string]interface{}{"k": "5", "s": "'x'"}
+	code := "const _ = require('lodash');\nexport function step(rows) { return rows }\nconst _ = require('lodash');"
+
+	program, err := GenerateJavaScript(workflow, code, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"#!/usr/bin/env node", `rows = await runComponent("Step", step, rows, {"k": 5, "s": "x"}, context);`, "\nfunction step(rows)"} {
+		if !strings.Contains(string(program), want) {
+			t.Errorf("program does not contain %q", want)
+		}
+	}
+	if n := strings.Count(string(program), "require('lodash')"); n != 1 {
+		t.Errorf("program requires lodash %d times, want 1", n)
+	}
+	if got := JavaScriptRequirements(workflow, code); strings.Join(got, " ") != "lodash" {
+		t.Errorf("JavaScriptRequirements() = %v, want [lodash]", got)
+	}
+
+	var codeErr *CodeError
+	if _, err := GenerateJavaScript(workflow, "import fs from 'fs';", GenerateOptions{}); !errors.As(err, &codeErr) {
+		t.Errorf("import statement: error = %v, want a *CodeError", err)
+	}
+	workflow.Nodes[0].Variables = map[string]interface{}{"k": "12345678901234567890"}
+	var nodeErr *NodeError
+	if _, err := GenerateJavaScript(workflow, code, GenerateOptions{}); !errors.As(err, &nodeErr) || nodeErr.Field != "variables.k" || nodeErr.Language != LanguageJavaScript {
+		t.Errorf("unsafe integer: error = %v, want a javascript *NodeError on variables.k", err)
+	}
+}
+
+func TestGenerateGo(t *testing.T) {
+	workflow := languageWorkflow("", LanguageGo)
+	workflow.Nodes[0].Variables = map[string]interface{}{"k": "5", "cols": "['a', 'b']"}
+	code := "package main\n\nimport \"strings\"\n\nfunc Step(t Table, p Params, c Context) (Table, error) {\n\t_ = strings.ToLower\n\treturn t, nil\n}\n" +
+		"package main\n\nimport (\n\t\"strings\"\n\t\"sort\"\n)\n\nfunc helper() { _ = strings.ToUpper; sort.Strings(nil) }\n"
+
+	program, err := GenerateGo(workflow, code, GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(program)
+	if n := strings.Count(src, "package main"); n != 1 {
+		t.Errorf("program has %d package clauses, want 1", n)
+	}
+	if n := strings.Count(src, "\t\"strings\"\n"); n != 1 {
+		t.Errorf("program imports strings %d times, want 1", n)
+	}
+	for _, want := range []string{"\t\"sort\"\n", `harnessStep("Step", Step, table, Params{"cols": []any{"a", "b"}, "k": 5}, ctx)`} {
+		if !strings.Contains(src, want) {
+			t.Errorf("program does not contain %q", want)
+		}
+	}
+
+	var codeErr *CodeError
+	if _, err := GenerateGo(workflow, "import \"github.com/pkg/errors\"\n\nfunc Step(t Table, p Params, c Context) (Table, error) { return t, nil }", GenerateOptions{}); !errors.As(err, &codeErr) {
+		t.Errorf("third-party import: error = %v, want a *CodeError", err)
+	}
+}
+
+func TestNewBridgeBundle(t *testing.T) {
+	workflow := languageWorkflow(BridgeCSV, LanguagePython, LanguageJavaScript, LanguageJavaScript, LanguageGo)
+	code := map[string]string{
+		LanguagePython:     "def step(df):\n    return df",
+		LanguageJavaScript: "function step(rows) { return rows }",
+		LanguageGo:         "func Step(t Table, p Params, c Context) (Table, error) { return t, nil }",
+	}
+
+	project, err := NewBridgeBundle(workflow, code, "x,y\n1,2\n", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{}
+	for _, file := range project.Files {
+		files[file.Name] = string(file.Content)
+	}
+	for _, name := range []string{"stage1.py", "stage2-3.js", "stage4.go", "requirements.txt", "run.sh", "Dockerfile", "README.md", "workflow.json", "data.csv"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle has no %s", name)
+		}
+	}
+	if _, ok := files["package.json"]; ok {
+		t.Error("bundle has a package.json without JavaScript requirements")
+	}
+	for _, want := range []string{"python stage1.py --data \"$data\"", "--output run/stage1.csv", "node stage2-3.js --data run/stage1.csv", "go run stage4.go --data run/stage2-3.csv", "--output \"$output\""} {
+		if !strings.Contains(files["run.sh"], want) {
+			t.Errorf("run.sh does not contain %q:\n%s", want, files["run.sh"])
+		}
+	}
+}
+
+func TestGetExporterForLanguage(t *testing.T) {
+	if _, ok := GetExporterFor("airflow", LanguageJavaScript); ok {
+		t.Error("airflow exporter available for javascript")
+	}
+	exporter, ok := GetExporterFor(DefaultExportFormat, LanguageGo)
+	if !ok {
+		t.Fatal("no script exporter for go")
+	}
+	if want := "go run {file} --data {data} --target {target} --output output.csv"; exporter.Command != want {
+		t.Errorf("Command = %q, want %q", exporter.Command, want)
+	}
+}
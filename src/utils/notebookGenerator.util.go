@@ -0,0 +1,190 @@
+// src/utils/notebookGenerator.util.go
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Notebook is an nbformat 4 document
+type Notebook struct {
+	Cells         []NotebookCell         `json:"cells"`
+	Metadata      map[string]interface{} `json:"metadata"`
+	NBFormat      int                    `json:"nbformat"`
+	NBFormatMinor int                    `json:"nbformat_minor"`
+}
+
+// NotebookCell is a markdown or code cell
+type NotebookCell struct {
+	ID       string                 `json:"id"`
+	CellType string                 `json:"cell_type"`
+	Metadata map[string]interface{} `json:"metadata"`
+	Source   []string               `json:"source"`
+}
+
+// MarshalJSON adds the execution_count and outputs fields that nbformat
+// requires on code cells and forbids on markdown cells
+func (c NotebookCell) MarshalJSON() ([]byte, error) {
+	type plain NotebookCell
+	if c.CellType != "code" {
+		return json.Marshal(plain(c))
+	}
+	return json.Marshal(struct {
+		plain
+		ExecutionCount *int          `json:"execution_count"`
+		Outputs        []interface{} `json:"outputs"`
+	}{plain: plain(c), Outputs: []interface{}{}})
+}
+
+// notebookBuilder appends cells with sequential IDs
+type notebookBuilder struct {
+	cells []NotebookCell
+}
+
+func (b *notebookBuilder) markdown(text string) {
+	b.add(NotebookCell{CellType: "markdown", Metadata: map[string]interface{}{}, Source: sourceLines(text)})
+}
+
+func (b *notebookBuilder) code(text string, tags ...string) {
+	metadata := map[string]interface{}{}
+	if len(tags) > 0 {
+		metadata["tags"] = tags
+	}
+	b.add(NotebookCell{CellType: "code", Metadata: metadata, Source: sourceLines(text)})
+}
+
+// block adds a code cell rendered from a template block, without the blank
+// lines around it
+func (b *notebookBuilder) block(tmpl *ScriptTemplate, name string, data interface{}) error {
+	code, err := tmpl.render(name, data)
+	if err != nil {
+		return err
+	}
+	b.code(strings.Trim(code, "\n"))
+	return nil
+}
+
+func (b *notebookBuilder) add(cell NotebookCell) {
+	cell.ID = fmt.Sprintf("cell-%03d", len(b.cells)+1)
+	b.cells = append(b.cells, cell)
+}
+
+// GenerateNotebook renders the workflow as a Jupyter notebook: a tagged
+// parameters cell (papermill-compatible), one cell per component definition,
+// a markdown heading per stage and one cell per execution step
+func GenerateNotebook(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	data, err := newScriptData(workflow, componentCode, opts)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := opts.template()
+	b := &notebookBuilder{}
+
+	b.markdown(fmt.Sprintf("# Auto-generated Pipeline\n\n"+
+		"- Generated at: %s\n- Version: %s\n- Total Components: %d\n- Workflow Hash: %s\n\n"+
+		"Edit the parameters cell below, or inject values with papermill, then run all cells.",
+		data.GeneratedAt, data.Version, data.TotalComponents, data.WorkflowHash))
+
+	if err := b.block(tmpl, "imports", data); err != nil {
+		return nil, err
+	}
+
+	b.markdown("## Parameters")
+	b.code(fmt.Sprintf(`data_file = %s
+target_column = %s
+output_file = 'output.csv'
+skip_split_warning = False`, pyQuote(dataFileName(workflow)), data.TargetColumn), "parameters")
+
+	b.markdown("## Component functions")
+	for _, definition := range splitDefinitions(componentCode) {
+		b.code(definition)
+	}
+
+	b.markdown("## Load data")
+	if err := b.block(tmpl, "setup", data); err != nil {
+		return nil, err
+	}
+
+	for _, stage := range data.Stages {
+		names := make([]string, len(stage.Steps))
+		for i, step := range stage.Steps {
+			names[i] = step.Node.Name
+		}
+		b.markdown(fmt.Sprintf("## Stage %d\n\n%s", stage.Number, "- "+strings.Join(names, "\n- ")))
+
+		for _, step := range stage.Steps {
+			if err := b.block(tmpl, "component", step); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	b.markdown("## Validation and output")
+	for _, name := range []string{"validation", "save_output"} {
+		if err := b.block(tmpl, name, data); err != nil {
+			return nil, err
+		}
+	}
+
+	notebook := Notebook{
+		Cells: b.cells,
+		Metadata: map[string]interface{}{
+			"kernelspec": map[string]string{
+				"display_name": "Python 3",
+				"language":     "python",
+				"name":         "python3",
+			},
+			"language_info": map[string]string{"name": "python"},
+		},
+		NBFormat:      4,
+		NBFormatMinor: 5,
+	}
+
+	return json.MarshalIndent(notebook, "", " ")
+}
+
+// splitDefinitions splits concatenated component code into top-level
+// blocks: anything before the first definition, then one block per
+// def/class including its decorators
+func splitDefinitions(code string) []string {
+	var blocks []string
+	var current []string
+	decorated := false
+
+	flush := func() {
+		block := strings.Trim(strings.Join(current, "\n"), "\n")
+		if strings.TrimSpace(block) != "" {
+			blocks = append(blocks, block)
+		}
+		current = nil
+	}
+
+	for _, line := range strings.Split(code, "\n") {
+		topLevel := line != "" && line[0] != ' ' && line[0] != '\t'
+		startsDefinition := topLevel && (strings.HasPrefix(line, "def ") ||
+			strings.HasPrefix(line, "async def ") ||
+			strings.HasPrefix(line, "class ") ||
+			strings.HasPrefix(line, "@"))
+
+		if startsDefinition && !decorated {
+			flush()
+		}
+		if topLevel {
+			decorated = strings.HasPrefix(line, "@")
+		}
+		current = append(current, line)
+	}
+	flush()
+
+	return blocks
+}
+
+// sourceLines splits text into nbformat source lines, each keeping its newline
+func sourceLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateNotebook(t *testing.T) {
+	code, err := os.ReadFile(filepath.Join("testdata", "components.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow := loadWorkflow(t, "workflow.json")
+
+	out, err := GenerateNotebook(workflow, string(code), goldenOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Decode generically to check the document as nbformat sees it
+	var notebook struct {
+		NBFormat      int                    `json:"nbformat"`
+		NBFormatMinor int                    `json:"nbformat_minor"`
+		Metadata      map[string]interface{} `json:"metadata"`
+		Cells         []map[string]json.RawMessage
+	}
+	if err := json.Unmarshal(out, &notebook); err != nil {
+		t.Fatalf("notebook is not valid JSON: %v", err)
+	}
+	if notebook.NBFormat != 4 || notebook.NBFormatMinor != 5 {
+		t.Errorf("nbformat = %d.%d, want 4.5", notebook.NBFormat, notebook.NBFormatMinor)
+	}
+	if _, ok := notebook.Metadata["kernelspec"]; !ok {
+		t.Error("metadata has no kernelspec")
+	}
+
+	ids := map[string]bool{}
+	var codeCells, definitions int
+	parameters := false
+	for i, raw := range notebook.Cells {
+		var cell struct {
+			ID       string                 `json:"id"`
+			CellType string                 `json:"cell_type"`
+			Metadata map[string]interface{} `json:"metadata"`
+			Source   []string               `json:"source"`
+		}
+		data, _ := json.Marshal(raw)
+		if err := json.Unmarshal(data, &cell); err != nil {
+			t.Fatalf("cell %d: %v", i, err)
+		}
+		if cell.ID == "" || ids[cell.ID] {
+			t.Errorf("cell %d: id %q is empty or repeated", i, cell.ID)
+		}
+		ids[cell.ID] = true
+		if cell.Metadata == nil || len(cell.Source) == 0 {
+			t.Errorf("cell %d: metadata and source are required", i)
+		}
+		for j, line := range cell.Source[:max(len(cell.Source)-1, 0)] {
+			if !strings.HasSuffix(line, "\n") {
+				t.Errorf("cell %d: source line %d does not keep its newline", i, j)
+			}
+		}
+
+		_, hasOutputs := raw["outputs"]
+		_, hasCount := raw["execution_count"]
+		switch cell.CellType {
+		case "code":
+			codeCells++
+			if !hasOutputs || !hasCount {
+				t.Errorf("code cell %d lacks outputs or execution_count", i)
+			}
+			if strings.HasPrefix(cell.Source[0], "def ") {
+				definitions++
+			}
+			if tags, ok := cell.Metadata["tags"].([]interface{}); ok && len(tags) == 1 && tags[0] == "parameters" {
+				parameters = true
+			}
+		case "markdown":
+			if hasOutputs || hasCount {
+				t.Errorf("markdown cell %d has code cell fields", i)
+			}
+		default:
+			t.Errorf("cell %d: unexpected cell_type %q", i, cell.CellType)
+		}
+	}
+
+	if !parameters {
+		t.Error("no code cell is tagged parameters")
+	}
+	if definitions != 5 {
+		t.Errorf("%d component definition cells, want one per function in components.py", definitions)
+	}
+	if codeCells < definitions+len(workflow.Nodes) {
+		t.Errorf("%d code cells, want at least one per definition and one per node", codeCells)
+	}
+}
+
+func TestSplitDefinitions(t *testing.T) {
+	code := "import os\n\n@cache\ndef a():\n    return 1\n\n\nclass B:\n    def c(self):\n        pass\n"
+	want := []string{"import os", "@cache\ndef a():\n    return 1", "class B:\n    def c(self):\n        pass"}
+
+	got := splitDefinitions(code)
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("splitDefinitions() = %q, want %q", got, want)
+	}
+}
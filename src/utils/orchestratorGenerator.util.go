@@ -0,0 +1,349 @@
+// src/utils/orchestratorGenerator.util.go
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Task ids of the tasks added around the workflow nodes
+const (
+	loadDataTaskID   = "load_data"
+	saveOutputTaskID = "save_output"
+)
+
+// stateHelpersCode persists the pipeline state between tasks. Every task
+// runs in its own process, so the state is pickled to one file per task.
+const stateHelpersCode = `STATE_KEYS = [
+    'data_file', 'df', 'X', 'y', 'current_data', 'model', 'le',
+    'X_train', 'X_test', 'y_train', 'y_test', 'split_performed',
+]
+
+
+def _load_state(run_dir, upstream):
+    """Merge the state saved by the upstream tasks, later tasks win"""
+    state = dict.fromkeys(STATE_KEYS)
+    for task_id in upstream:
+        with open(os.path.join(run_dir, f"{task_id}.pkl"), 'rb') as f:
+            state.update(pickle.load(f))
+    return state
+
+
+def _save_state(run_dir, task_id, values):
+    """Save the pipeline state of a task for its downstream tasks"""
+    os.makedirs(run_dir, exist_ok=True)
+    with open(os.path.join(run_dir, f"{task_id}.pkl"), 'wb') as f:
+        pickle.dump({key: values.get(key) for key in STATE_KEYS}, f)
+`
+
+// stateLoadCode restores the variables used by the component execution code
+const stateLoadCode = `    state = _load_state(run_dir, upstream)
+    data_file = state['data_file']
+    df, X, y = state['df'], state['X'], state['y']
+    current_data, model, le = state['current_data'], state['model'], state['le']
+    X_train, X_test = state['X_train'], state['X_test']
+    y_train, y_test = state['y_train'], state['y_test']
+    split_performed = state['split_performed']
+
+`
+
+// orchestratedTask is one task of an orchestrator export
+type orchestratedTask struct {
+	ID       string
+	Name     string
+	Function string
+	Upstream []string
+	Node     *Node
+	Stage    int
+	Index    int
+	Total    int
+}
+
+// planTasks turns the workflow into tasks in dependency order. Without edges
+// the nodes run one after another in stage order; with edges every node
+// waits for its sources. A load_data task feeds the first nodes and a
+// save_output task collects the last ones.
+func planTasks(workflow WorkflowConfig) ([]orchestratedTask, error) {
+	var nodes []orchestratedTask
+	taskIDs := make(map[string]string)
+	used := map[string]bool{loadDataTaskID: true, saveOutputTaskID: true}
+
+	groups, err := organizeByStage(workflow.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		for i := range group.Nodes {
+			node := group.Nodes[i]
+			if _, ok := taskIDs[node.ID]; ok && node.ID != "" {
+				return nil, fmt.Errorf("duplicate node id %q", node.ID)
+			}
+
+			id := taskID(node.ID, len(nodes)+1)
+			for n := 2; used[id]; n++ {
+				id = fmt.Sprintf("%s_%d", taskID(node.ID, len(nodes)+1), n)
+			}
+			used[id] = true
+			taskIDs[node.ID] = id
+
+			compName, _, err := nodeFunction(node)
+			if err != nil {
+				return nil, err
+			}
+			nodes = append(nodes, orchestratedTask{
+				ID:       id,
+				Name:     compName,
+				Function: "run_" + id,
+				Node:     &node,
+				Stage:    group.Number,
+				Index:    i + 1,
+				Total:    len(group.Nodes),
+			})
+		}
+	}
+
+	if len(workflow.Edges) == 0 {
+		previous := loadDataTaskID
+		for i := range nodes {
+			nodes[i].Upstream = []string{previous}
+			previous = nodes[i].ID
+		}
+	} else {
+		// Edges of nodes in stages left out of the code go with them
+		skipped := make(map[string]bool)
+		for _, node := range workflow.Nodes {
+			if _, ok := taskIDs[node.ID]; !ok {
+				skipped[node.ID] = true
+			}
+		}
+		var edges []Edge
+		for _, edge := range workflow.Edges {
+			if !skipped[edge.Source] && !skipped[edge.Target] {
+				edges = append(edges, edge)
+			}
+		}
+
+		var err error
+		if nodes, err = applyEdges(nodes, edges, taskIDs); err != nil {
+			return nil, err
+		}
+	}
+
+	// Nodes nothing depends on are the outputs of the workflow
+	hasDownstream := make(map[string]bool)
+	for _, task := range nodes {
+		for _, up := range task.Upstream {
+			hasDownstream[up] = true
+		}
+	}
+	var sinks []string
+	for _, task := range nodes {
+		if !hasDownstream[task.ID] {
+			sinks = append(sinks, task.ID)
+		}
+	}
+	if len(sinks) == 0 {
+		sinks = []string{loadDataTaskID}
+	}
+
+	tasks := make([]orchestratedTask, 0, len(nodes)+2)
+	tasks = append(tasks, orchestratedTask{ID: loadDataTaskID, Name: "Load data", Function: "load_data"})
+	tasks = append(tasks, nodes...)
+	tasks = append(tasks, orchestratedTask{ID: saveOutputTaskID, Name: "Save output", Function: "save_output", Upstream: sinks})
+	return tasks, nil
+}
+
+// applyEdges sets the upstream tasks from the edges and orders the tasks
+// topologically, keeping stage order between independent tasks
+func applyEdges(nodes []orchestratedTask, edges []Edge, taskIDs map[string]string) ([]orchestratedTask, error) {
+	position := make(map[string]int, len(nodes))
+	for i, task := range nodes {
+		position[task.ID] = i
+	}
+
+	for _, edge := range edges {
+		source, ok := taskIDs[edge.Source]
+		if !ok {
+			return nil, fmt.Errorf("edge source %q is not a node", edge.Source)
+		}
+		target, ok := taskIDs[edge.Target]
+		if !ok {
+			return nil, fmt.Errorf("edge target %q is not a node", edge.Target)
+		}
+		if source == target {
+			return nil, fmt.Errorf("node %q depends on itself", edge.Source)
+		}
+
+		task := &nodes[position[target]]
+		if !containsString(task.Upstream, source) {
+			task.Upstream = append(task.Upstream, source)
+		}
+	}
+
+	ordered := make([]orchestratedTask, 0, len(nodes))
+	done := make(map[string]bool, len(nodes))
+	for len(ordered) < len(nodes) {
+		progressed := false
+		for _, task := range nodes {
+			if done[task.ID] || !allDone(task.Upstream, done) {
+				continue
+			}
+			if len(task.Upstream) == 0 {
+				task.Upstream = []string{loadDataTaskID}
+			}
+			ordered = append(ordered, task)
+			done[task.ID] = true
+			progressed = true
+			break
+		}
+		if !progressed {
+			return nil, fmt.Errorf("workflow edges contain a cycle")
+		}
+	}
+
+	// Upstream state is merged in order, so list the latest task last
+	order := map[string]int{loadDataTaskID: -1}
+	for i, task := range ordered {
+		order[task.ID] = i
+	}
+	for _, task := range ordered {
+		sort.Slice(task.Upstream, func(i, j int) bool {
+			return order[task.Upstream[i]] < order[task.Upstream[j]]
+		})
+	}
+	return ordered, nil
+}
+
+func allDone(ids []string, done map[string]bool) bool {
+	for _, id := range ids {
+		if !done[id] {
+			return false
+		}
+	}
+	return true
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// taskID derives a Python identifier from a node id
+func taskID(nodeID string, index int) string {
+	id := strings.Trim(nonIdentifier.ReplaceAllString(strings.ToLower(nodeID), "_"), "_")
+	if id == "" {
+		return fmt.Sprintf("node_%d", index)
+	}
+	if id[0] >= '0' && id[0] <= '9' {
+		return "node_" + id
+	}
+	return id
+}
+
+// pythonStringList renders ids as a Python list of strings
+func pythonStringList(ids []string) string {
+	quoted := make([]string, len(ids))
+	for i, id := range ids {
+		quoted[i] = fmt.Sprintf("'%s'", id)
+	}
+	return "[" + strings.Join(quoted, ", ") + "]"
+}
+
+// writeTaskModule writes the part shared by the orchestrator exports: the
+// component functions and one plain Python function per task
+func writeTaskModule(sb *strings.Builder, workflow WorkflowConfig, componentCode, title, description, imports string, tasks []orchestratedTask, opts GenerateOptions) error {
+	data, err := newScriptData(workflow, componentCode, opts)
+	if err != nil {
+		return err
+	}
+	tmpl := opts.template()
+
+	// block renders a template block into the body of a task function
+	block := func(name string, data interface{}) error {
+		code, err := tmpl.render(name, data)
+		sb.WriteString(indentLines(4, code))
+		return err
+	}
+
+	sb.WriteString(fmt.Sprintf(`"""
+Auto-generated %s
+Generated at: %s
+Version: %s
+Total Components: %d
+Workflow Hash: %s
+
+%s
+"""
+
+import os
+import pickle
+`, title, data.GeneratedAt, docstringText(data.Version), data.TotalComponents, data.WorkflowHash, description))
+	sb.WriteString(data.Imports)
+	sb.WriteString(imports)
+	sb.WriteString(data.Seed)
+	sb.WriteString(data.DataIO)
+	sb.WriteString(`
+# ============================================================
+# COMPONENT FUNCTIONS
+# ============================================================
+
+`)
+	sb.WriteString(componentCode)
+	sb.WriteString(`
+
+# ============================================================
+# PIPELINE STATE
+# ============================================================
+
+`)
+	sb.WriteString(stateHelpersCode)
+	sb.WriteString(`
+
+# ============================================================
+# TASKS
+# ============================================================
+
+def load_data(run_dir, data_file, target_column=` + data.TargetColumn + `):
+    """Load the input data and initialise the pipeline state"""
+`)
+	if err := block("setup", data); err != nil {
+		return err
+	}
+	sb.WriteString(fmt.Sprintf("    _save_state(run_dir, '%s', locals())\n", loadDataTaskID))
+
+	for _, task := range tasks {
+		if task.Node == nil {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf(`
+
+# Stage %d: %s
+def %s(run_dir, upstream, target_column=%s):
+`, task.Stage, task.Name, task.Function, data.TargetColumn))
+		step, err := newScriptStep(*task.Node, task.Index, task.Total, task.Stage, data.scriptTask)
+		if err != nil {
+			return err
+		}
+		sb.WriteString(stateLoadCode)
+		if err := block("component", step); err != nil {
+			return err
+		}
+		sb.WriteString(fmt.Sprintf("    _save_state(run_dir, '%s', locals())\n", task.ID))
+	}
+
+	sb.WriteString(`
+
+def save_output(run_dir, upstream, output_file='output.csv', skip_split_warning=False):
+    """Check the run and save the processed features"""
+`)
+	sb.WriteString(stateLoadCode)
+	if err := block("validation", data); err != nil {
+		return err
+	}
+	return block("save_output", data)
+}
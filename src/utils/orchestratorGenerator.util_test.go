@@ -0,0 +1,102 @@
+package utils
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files")
+
+// goldenOptions pins the timestamp and seed so the golden files are stable
+func goldenOptions() GenerateOptions {
+	seed := int64(42)
+	return GenerateOptions{Timestamp: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Seed: &seed}
+}
+
+func loadWorkflow(t *testing.T, name string) WorkflowConfig {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var workflow WorkflowConfig
+	if err := json.Unmarshal(data, &workflow); err != nil {
+		t.Fatal(err)
+	}
+	return workflow
+}
+
+func TestOrchestratorExportersGolden(t *testing.T) {
+	code, err := os.ReadFile(filepath.Join("testdata", "components.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		format   string
+		workflow string
+		golden   string
+	}{
+		{"airflow", "workflow.json", "airflow_dag.golden"},
+		{"prefect", "workflow.json", "prefect_flow.golden"},
+		{"airflow", "workflow_edges.json", "airflow_dag_edges.golden"},
+		{"prefect", "workflow_edges.json", "prefect_flow_edges.golden"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			exporter, ok := GetExporter(tt.format)
+			if !ok {
+				t.Fatalf("format %q is not registered", tt.format)
+			}
+
+			got, err := exporter.Generate(loadWorkflow(t, tt.workflow), string(code), goldenOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", tt.golden)
+			if *update {
+				if err := os.WriteFile(path, got, 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatalf("%v (run go test -update to create it)", err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("output differs from %s (run go test -update after checking the change)", path)
+			}
+		})
+	}
+}
+
+func TestPlanTasksRejectsInvalidEdges(t *testing.T) {
+	nodes := []Node{{ID: "a", Stage: 1, Code: "f"}, {ID: "b", Stage: 2, Code: "g"}}
+
+	tests := []struct {
+		name  string
+		edges []Edge
+		want  string
+	}{
+		{"unknown source", []Edge{{Source: "x", Target: "b"}}, "not a node"},
+		{"self loop", []Edge{{Source: "a", Target: "a"}}, "depends on itself"},
+		{"cycle", []Edge{{Source: "a", Target: "b"}, {Source: "b", Target: "a"}}, "cycle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := planTasks(WorkflowConfig{Nodes: nodes, Edges: tt.edges})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
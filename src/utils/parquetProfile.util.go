@@ -0,0 +1,495 @@
+// src/utils/parquetProfile.util.go
+package utils
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// Parquet physical types
+const (
+	parquetBoolean   = 0
+	parquetInt32     = 1
+	parquetInt64     = 2
+	parquetInt96     = 3
+	parquetFloat     = 4
+	parquetDouble    = 5
+	parquetByteArray = 6
+	parquetFixed     = 7
+)
+
+// Parquet converted types the profile distinguishes
+const (
+	convertedEnum            = 4
+	convertedDecimal         = 5
+	convertedDate            = 6
+	convertedTimeMillis      = 7
+	convertedTimeMicros      = 8
+	convertedTimestampMillis = 9
+	convertedTimestampMicros = 10
+)
+
+// maxParquetFooter caps the metadata read from a Parquet file
+const maxParquetFooter = 16 << 20
+
+// profileParquet profiles a Parquet file from the metadata in its footer:
+// the schema, the row count and the column chunk statistics. The data
+// pages are not read, so columns have no mean, quantiles or top values,
+// and Distinct is only known when the writer recorded it.
+func profileParquet(r io.ReaderAt, size int64) (*DatasetProfile, error) {
+	if size < 12 {
+		return nil, errors.New("not a Parquet file")
+	}
+	tail := make([]byte, 8)
+	if _, err := r.ReadAt(tail, size-8); err != nil {
+		return nil, err
+	}
+	if string(tail[4:]) != "PAR1" {
+		return nil, errors.New("not a Parquet file")
+	}
+	length := int64(binary.LittleEndian.Uint32(tail))
+	if length > maxParquetFooter || length > size-12 {
+		return nil, errParquetFooter
+	}
+	footer := make([]byte, length)
+	if _, err := r.ReadAt(footer, size-8-length); err != nil {
+		return nil, err
+	}
+
+	metadata, err := (&thriftReader{buf: footer}).readStruct()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", errParquetFooter, err)
+	}
+	return parquetProfile(metadata)
+}
+
+// parquetColumn is a leaf of the Parquet schema
+type parquetColumn struct {
+	name          string
+	physical      int64
+	converted     int64 // -1 when not set
+	logical       thriftStruct
+	stats         ColumnProfile
+	min, max      float64
+	hasRange      bool
+	distinctKnown bool
+}
+
+// errParquetFooter reports metadata that decodes but is not the Parquet
+// FileMetaData it should be
+var errParquetFooter = errors.New("invalid Parquet footer")
+
+// maxParquetNesting caps how deeply schema groups nest
+const maxParquetNesting = 64
+
+func parquetProfile(metadata thriftStruct) (*DatasetProfile, error) {
+	schema := metadata.list(2)
+	if len(schema) == 0 {
+		return nil, fmt.Errorf("%w: no schema", errParquetFooter)
+	}
+	root, ok := schema[0].(thriftStruct)
+	if !ok {
+		return nil, fmt.Errorf("%w: schema element 0 is not a struct", errParquetFooter)
+	}
+	columns, _, err := parquetLeaves(schema, 1, root.int(5), "", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := metadata.list(4)
+	for g, item := range groups {
+		group, ok := item.(thriftStruct)
+		if !ok {
+			return nil, fmt.Errorf("%w: row group %d is not a struct", errParquetFooter, g)
+		}
+		for i, item := range group.list(1) {
+			if i >= len(columns) {
+				break
+			}
+			chunk, ok := item.(thriftStruct)
+			if !ok {
+				return nil, fmt.Errorf("%w: column chunk %d of row group %d is not a struct", errParquetFooter, i, g)
+			}
+			if err := columns[i].addStatistics(chunk.child(3).child(12), len(groups)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	rows := metadata.int(3)
+	if rows < 0 || rows > math.MaxInt32 {
+		return nil, fmt.Errorf("%w: %d rows", errParquetFooter, rows)
+	}
+	p := &DatasetProfile{Rows: int(rows), Columns: make([]ColumnProfile, len(columns))}
+	for i, column := range columns {
+		p.Columns[i] = column.profile(p.Rows)
+	}
+	p.TargetCandidates = targetCandidates(p)
+	return p, nil
+}
+
+// parquetLeaves flattens the children of a schema group into its leaf
+// columns, naming nested columns by their dotted path
+func parquetLeaves(schema []interface{}, next int, children int64, prefix string, depth int) ([]*parquetColumn, int, error) {
+	if depth > maxParquetNesting {
+		return nil, next, fmt.Errorf("%w: schema nested too deeply", errParquetFooter)
+	}
+	var leaves []*parquetColumn
+	for c := int64(0); c < children && next < len(schema); c++ {
+		element, ok := schema[next].(thriftStruct)
+		if !ok {
+			return nil, next, fmt.Errorf("%w: schema element %d is not a struct", errParquetFooter, next)
+		}
+		next++
+		name := prefix + element.string(4)
+		if n := element.int(5); n > 0 {
+			nested, after, err := parquetLeaves(schema, next, n, name+".", depth+1)
+			if err != nil {
+				return nil, after, err
+			}
+			leaves, next = append(leaves, nested...), after
+			continue
+		}
+		converted := int64(-1)
+		if _, ok := element[6]; ok {
+			converted = element.int(6)
+		}
+		leaves = append(leaves, &parquetColumn{
+			name:      name,
+			physical:  element.int(1),
+			converted: converted,
+			logical:   element.child(10),
+			stats:     ColumnProfile{Name: name},
+			min:       math.Inf(1),
+			max:       math.Inf(-1),
+		})
+	}
+	return leaves, next, nil
+}
+
+// addStatistics merges the statistics of one column chunk
+func (c *parquetColumn) addStatistics(stats thriftStruct, groups int) error {
+	if stats == nil {
+		return nil
+	}
+	nulls := stats.int(3)
+	if nulls < 0 || nulls > math.MaxInt32 {
+		return fmt.Errorf("%w: column %q has %d nulls", errParquetFooter, c.name, nulls)
+	}
+	c.stats.Nulls += int(nulls)
+	if value, ok := stats[4]; ok && groups == 1 {
+		distinct, ok := value.(int64)
+		if !ok || distinct < 0 || distinct > math.MaxInt32 {
+			return fmt.Errorf("%w: column %q has an invalid distinct count", errParquetFooter, c.name)
+		}
+		c.stats.Distinct = int(distinct)
+		c.distinctKnown = true
+	}
+
+	// min_value and max_value replace the deprecated min and max
+	lo, hi := stats.bytes(6), stats.bytes(5)
+	if lo == nil || hi == nil {
+		lo, hi = stats.bytes(2), stats.bytes(1)
+	}
+	if lo == nil || hi == nil {
+		return nil
+	}
+	if min, ok := c.decode(lo); ok {
+		c.min = math.Min(c.min, min)
+		c.hasRange = true
+	}
+	if max, ok := c.decode(hi); ok {
+		c.max = math.Max(c.max, max)
+		c.hasRange = true
+	}
+	return nil
+}
+
+// decode reads a plain-encoded statistics value as a number
+func (c *parquetColumn) decode(b []byte) (float64, bool) {
+	switch {
+	case c.physical == parquetInt32 && len(b) == 4:
+		return float64(int32(binary.LittleEndian.Uint32(b))), true
+	case c.physical == parquetInt64 && len(b) == 8:
+		return float64(int64(binary.LittleEndian.Uint64(b))), true
+	case c.physical == parquetFloat && len(b) == 4:
+		return float64(math.Float32frombits(binary.LittleEndian.Uint32(b))), true
+	case c.physical == parquetDouble && len(b) == 8:
+		return math.Float64frombits(binary.LittleEndian.Uint64(b)), true
+	}
+	return 0, false
+}
+
+func (c *parquetColumn) columnType() string {
+	logical := c.logical
+	switch {
+	case c.physical == parquetBoolean:
+		return ColumnBool
+	case c.physical == parquetInt96, c.converted == convertedDate, c.converted == convertedTimestampMillis,
+		c.converted == convertedTimestampMicros, logical.has(6), logical.has(8):
+		return ColumnDatetime
+	case c.converted == convertedDecimal, logical.has(5), c.physical == parquetFloat, c.physical == parquetDouble:
+		return ColumnFloat
+	case c.physical == parquetInt32, c.physical == parquetInt64:
+		return ColumnInt
+	case c.converted == convertedEnum, logical.has(4):
+		return ColumnCategorical
+	}
+	if c.distinctKnown && c.stats.Distinct <= categoricalMaxDistinct {
+		return ColumnCategorical
+	}
+	return ColumnText
+}
+
+// timestampUnit returns the duration of one unit of a datetime column
+func (c *parquetColumn) timestampUnit() (time.Duration, bool) {
+	switch {
+	case c.converted == convertedDate || c.logical.has(6):
+		return 24 * time.Hour, true
+	case c.converted == convertedTimestampMillis:
+		return time.Millisecond, true
+	case c.converted == convertedTimestampMicros:
+		return time.Microsecond, true
+	case c.logical.has(8):
+		unit := c.logical.child(8).child(2)
+		switch {
+		case unit.has(1):
+			return time.Millisecond, true
+		case unit.has(2):
+			return time.Microsecond, true
+		case unit.has(3):
+			return time.Nanosecond, true
+		}
+	}
+	return 0, false
+}
+
+func (c *parquetColumn) profile(rows int) ColumnProfile {
+	column := c.stats
+	column.Type = c.columnType()
+	column.DistinctCapped = !c.distinctKnown
+	if !c.hasRange {
+		return column
+	}
+
+	switch column.Type {
+	case ColumnInt, ColumnFloat:
+		if c.converted != convertedDecimal && !c.logical.has(5) {
+			column.Min, column.Max = &c.min, &c.max
+		}
+	case ColumnDatetime:
+		if unit, ok := c.timestampUnit(); ok {
+			column.Earliest = time.Unix(0, 0).UTC().Add(time.Duration(c.min) * unit).Format(time.RFC3339)
+			column.Latest = time.Unix(0, 0).UTC().Add(time.Duration(c.max) * unit).Format(time.RFC3339)
+		}
+	}
+	return column
+}
+
+// thriftStruct is a decoded Thrift struct by field ID. Values are int64,
+// float64, bool, []byte, []interface{} or thriftStruct; maps are skipped.
+type thriftStruct map[int16]interface{}
+
+func (s thriftStruct) has(id int16) bool {
+	_, ok := s[id]
+	return ok
+}
+
+func (s thriftStruct) int(id int16) int64 {
+	v, _ := s[id].(int64)
+	return v
+}
+
+func (s thriftStruct) bytes(id int16) []byte {
+	v, _ := s[id].([]byte)
+	return v
+}
+
+func (s thriftStruct) string(id int16) string {
+	return string(s.bytes(id))
+}
+
+func (s thriftStruct) list(id int16) []interface{} {
+	v, _ := s[id].([]interface{})
+	return v
+}
+
+func (s thriftStruct) child(id int16) thriftStruct {
+	v, _ := s[id].(thriftStruct)
+	return v
+}
+
+// Thrift compact protocol types
+const (
+	thriftStop       = 0
+	thriftTrue       = 1
+	thriftFalse      = 2
+	thriftByte       = 3
+	thriftI16        = 4
+	thriftI32        = 5
+	thriftI64        = 6
+	thriftDouble     = 7
+	thriftBinary     = 8
+	thriftList       = 9
+	thriftSet        = 10
+	thriftMap        = 11
+	thriftStructType = 12
+)
+
+// maxThriftDepth guards against deeply nested malicious metadata
+const maxThriftDepth = 32
+
+var errThriftEOF = errors.New("unexpected end of metadata")
+
+// thriftReader decodes the Thrift compact protocol Parquet metadata uses
+type thriftReader struct {
+	buf   []byte
+	pos   int
+	depth int
+}
+
+func (r *thriftReader) byte() (byte, error) {
+	if r.pos >= len(r.buf) {
+		return 0, errThriftEOF
+	}
+	b := r.buf[r.pos]
+	r.pos++
+	return b, nil
+}
+
+func (r *thriftReader) varint() (uint64, error) {
+	v, n := binary.Uvarint(r.buf[r.pos:])
+	if n <= 0 {
+		return 0, errThriftEOF
+	}
+	r.pos += n
+	return v, nil
+}
+
+func (r *thriftReader) zigzag() (int64, error) {
+	v, err := r.varint()
+	return int64(v>>1) ^ -int64(v&1), err
+}
+
+func (r *thriftReader) readStruct() (thriftStruct, error) {
+	if r.depth++; r.depth > maxThriftDepth {
+		return nil, errors.New("metadata nested too deeply")
+	}
+	defer func() { r.depth-- }()
+
+	s := thriftStruct{}
+	var id int16
+	for {
+		header, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		typ := header & 0x0f
+		if typ == thriftStop {
+			return s, nil
+		}
+		if delta := header >> 4; delta != 0 {
+			id += int16(delta)
+		} else {
+			v, err := r.zigzag()
+			if err != nil {
+				return nil, err
+			}
+			id = int16(v)
+		}
+
+		switch typ {
+		case thriftTrue, thriftFalse:
+			s[id] = typ == thriftTrue
+		default:
+			value, err := r.readValue(typ)
+			if err != nil {
+				return nil, err
+			}
+			if value != nil {
+				s[id] = value
+			}
+		}
+	}
+}
+
+func (r *thriftReader) readValue(typ byte) (interface{}, error) {
+	switch typ {
+	case thriftTrue, thriftFalse:
+		// Booleans inside lists are a whole byte
+		b, err := r.byte()
+		return b == thriftTrue, err
+	case thriftByte:
+		b, err := r.byte()
+		return int64(int8(b)), err
+	case thriftI16, thriftI32, thriftI64:
+		return r.zigzag()
+	case thriftDouble:
+		if r.pos+8 > len(r.buf) {
+			return nil, errThriftEOF
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(r.buf[r.pos:]))
+		r.pos += 8
+		return v, nil
+	case thriftBinary:
+		n, err := r.varint()
+		if err != nil {
+			return nil, err
+		}
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, errThriftEOF
+		}
+		b := r.buf[r.pos : r.pos+int(n)]
+		r.pos += int(n)
+		return b, nil
+	case thriftList, thriftSet:
+		header, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		n := uint64(header >> 4)
+		if n == 15 {
+			if n, err = r.varint(); err != nil {
+				return nil, err
+			}
+		}
+		// Every element takes at least one byte
+		if n > uint64(len(r.buf)-r.pos) {
+			return nil, errThriftEOF
+		}
+		items := make([]interface{}, 0, n)
+		for i := uint64(0); i < n; i++ {
+			item, err := r.readValue(header & 0x0f)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case thriftMap:
+		n, err := r.varint()
+		if err != nil || n == 0 {
+			return nil, err
+		}
+		types, err := r.byte()
+		if err != nil {
+			return nil, err
+		}
+		for i := uint64(0); i < n; i++ {
+			if _, err := r.readValue(types >> 4); err != nil {
+				return nil, err
+			}
+			if _, err := r.readValue(types & 0x0f); err != nil {
+				return nil, err
+			}
+		}
+		return nil, nil
+	case thriftStructType:
+		return r.readStruct()
+	}
+	return nil, fmt.Errorf("unknown field type %d", typ)
+}
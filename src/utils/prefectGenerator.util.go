@@ -0,0 +1,83 @@
+// src/utils/prefectGenerator.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+const prefectImports = `import tempfile
+from prefect import flow, task
+`
+
+// GeneratePrefectFlow renders the workflow as a Prefect flow with one task
+// per node. Tasks pass the pipeline state through pickle files and wait for
+// their upstream tasks before they start.
+func GeneratePrefectFlow(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	tasks, err := planTasks(seedWorkflow(workflow, componentCode, opts))
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+
+	if err := writeTaskModule(&sb, workflow, componentCode, "Prefect flow",
+		`Every component runs as its own Prefect task. The tasks exchange the
+pipeline state through pickle files in the run directory.`,
+		prefectImports, tasks, opts); err != nil {
+		return nil, err
+	}
+
+	sb.WriteString(`
+
+# ============================================================
+# FLOW DEFINITION
+# ============================================================
+
+@flow(name='builder-workflow')
+def builder_workflow(data_file, target_column=` + targetDefault(workflow) + `, output_file='output.csv', run_dir=None):
+    """Run every component of the workflow as a task"""
+    run_dir = run_dir or tempfile.mkdtemp(prefix='builder_run_')
+
+`)
+
+	for _, task := range tasks {
+		var args string
+		switch task.ID {
+		case loadDataTaskID:
+			args = "run_dir, data_file, target_column"
+		case saveOutputTaskID:
+			args = fmt.Sprintf("run_dir, %s, output_file", pythonStringList(task.Upstream))
+		default:
+			args = fmt.Sprintf("run_dir, %s, target_column", pythonStringList(task.Upstream))
+		}
+
+		waitFor := make([]string, len(task.Upstream))
+		for i, up := range task.Upstream {
+			waitFor[i] = up + "_run"
+		}
+		if len(waitFor) > 0 {
+			args += fmt.Sprintf(", wait_for=[%s]", strings.Join(waitFor, ", "))
+		}
+
+		sb.WriteString(fmt.Sprintf("    %s_run = task(%s, name=%s).submit(%s)\n",
+			task.ID, task.Function, pyQuote(task.Name), args))
+	}
+
+	sb.WriteString(fmt.Sprintf(`    %s_run.result()
+    return run_dir
+
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser(description='Run the Prefect flow')
+    parser.add_argument('--data', required=True, help='Input data file (CSV, TSV, JSON Lines, Parquet or Excel)')
+    %s
+    parser.add_argument('--output', default='output.csv', help='Output file (default: output.csv)')
+    parser.add_argument('--run-dir', default=None, help='Directory for intermediate task state (default: temporary directory)')
+
+    args = parser.parse_args()
+    builder_workflow(args.data, args.target, args.output, args.run_dir)
+`, saveOutputTaskID, targetArgument(workflow)))
+
+	return []byte(sb.String()), nil
+}
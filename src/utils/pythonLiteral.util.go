@@ -0,0 +1,638 @@
+// src/utils/pythonLiteral.util.go
+package utils
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Parsed Python literals that have no natural Go representation. Lists are
+// []interface{}, strings are string, True/False are bool and None is nil.
+type (
+	pyInt   string // canonical integer literal
+	pyFloat string // canonical float literal
+	pyTuple []interface{}
+	pyDict  []pyItem // keeps the source order of the keys
+)
+
+type pyItem struct {
+	Key   interface{}
+	Value interface{}
+}
+
+// NodeError reports a node field that cannot be rendered as source code
+type NodeError struct {
+	NodeID  string
+	Field   string // "code", "language", "role", "stage" or "variables.<name>"
+	Message string
+	// Language is the language the field is rendered in, python when empty
+	Language string
+}
+
+func (e *NodeError) Error() string {
+	return fmt.Sprintf("node %q: %s %s", e.NodeID, e.Field, e.Message)
+}
+
+// Literal kinds: how the variables of an input type are parsed and
+// rendered, see DataType.Literal
+const (
+	literalString = "string"
+	literalInt    = "int"
+	literalFloat  = "float"
+	literalBool   = "bool"
+	literalList   = "list"
+	literalTuple  = "tuple"
+	literalDict   = "dict"
+	literalNone   = "none"
+	literalAny    = "any" // the value decides the Python type
+)
+
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true,
+	"async": true, "await": true, "break": true, "class": true, "continue": true, "def": true,
+	"del": true, "elif": true, "else": true, "except": true, "finally": true, "for": true,
+	"from": true, "global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true, "raise": true,
+	"return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// isPythonName reports whether s can be used as a Python name
+func isPythonName(s string) bool {
+	return isIdentifier(s) && !pythonKeywords[s]
+}
+
+// PythonLiteral renders value as a Python literal of the declared input
+// type. The builder UI sends every value as text, so strings are parsed as
+// Python literals for non-string types. Values that do not match the type
+// are rejected; "None" is accepted for every type.
+func PythonLiteral(value interface{}, declared string) (string, error) {
+	value, err := literalValue(value, declared)
+	if err != nil {
+		return "", err
+	}
+	return renderPython(value)
+}
+
+// literalValue parses and checks value as PythonLiteral does and returns
+// it as a parsed literal, for rendering in Python or another language
+func literalValue(value interface{}, declared string) (interface{}, error) {
+	kind := literalKind(declared)
+	declared = strings.ToLower(strings.TrimSpace(declared))
+
+	if s, ok := value.(string); ok {
+		if strings.TrimSpace(s) == "None" {
+			return nil, nil
+		}
+		switch {
+		case kind == literalString:
+			return s, nil
+		case kind == literalAny:
+			if parsed, err := parsePythonLiteral(s); err == nil {
+				value = parsed
+			} else {
+				return s, nil
+			}
+		case kind == literalBool && (s == "true" || s == "false"):
+			value = s == "true"
+		default:
+			parsed, err := parsePythonLiteral(s)
+			if err != nil {
+				return nil, fmt.Errorf("is not a valid %s literal: %v", declared, err)
+			}
+			value = parsed
+		}
+	}
+
+	if value == nil {
+		return nil, nil
+	}
+
+	if kind != literalAny {
+		converted, ok := convertLiteral(value, kind)
+		if !ok {
+			return nil, fmt.Errorf("must be a %s, got %s", declared, pyTypeName(value))
+		}
+		value = converted
+	}
+	return value, nil
+}
+
+// convertLiteral checks value against a literal kind and converts it to
+// the kind where Python would (int to float, list to tuple)
+func convertLiteral(value interface{}, kind string) (interface{}, bool) {
+	switch kind {
+	case literalString:
+		_, ok := value.(string)
+		return value, ok
+	case literalInt:
+		switch v := value.(type) {
+		case pyInt:
+			return v, true
+		case float64:
+			// Rendered exactly, as large floats would be in exponent form
+			if math.IsInf(v, 0) || v != math.Trunc(v) {
+				return nil, false
+			}
+			n, _ := new(big.Float).SetFloat64(v).Int(nil)
+			return pyInt(n.String()), true
+		}
+	case literalFloat:
+		switch v := value.(type) {
+		case pyFloat:
+			return v, true
+		case pyInt:
+			return pyFloat(string(v) + ".0"), true
+		case float64:
+			return pyFloat(formatFloat(v)), true
+		}
+	case literalBool:
+		_, ok := value.(bool)
+		return value, ok
+	case literalDict:
+		switch value.(type) {
+		case pyDict, map[string]interface{}:
+			return value, true
+		}
+	case literalNone:
+		return value, value == nil
+	case literalList, literalTuple, literalSequence:
+		switch v := value.(type) {
+		case []interface{}:
+			if kind == literalTuple {
+				return pyTuple(v), true
+			}
+			return v, true
+		case pyTuple:
+			if kind == literalList {
+				return []interface{}(v), true
+			}
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func pyTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "None"
+	case bool:
+		return literalBool
+	case pyInt:
+		return literalInt
+	case pyFloat:
+		return literalFloat
+	case float64:
+		if v == math.Trunc(v) {
+			return literalInt
+		}
+		return literalFloat
+	case string:
+		return literalString
+	case []interface{}:
+		return literalList
+	case pyTuple:
+		return literalTuple
+	case pyDict, map[string]interface{}:
+		return literalDict
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// renderPython renders a JSON decoded or parsed value as Python source
+func renderPython(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "None", nil
+	case bool:
+		if v {
+			return "True", nil
+		}
+		return "False", nil
+	case pyInt:
+		return string(v), nil
+	case pyFloat:
+		return string(v), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("must be a finite number")
+		}
+		if v == math.Trunc(v) && math.Abs(v) < 1e15 {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+		return formatFloat(v), nil
+	case string:
+		return pyQuote(v), nil
+	case []interface{}:
+		items, err := renderItems(v)
+		return "[" + items + "]", err
+	case pyTuple:
+		items, err := renderItems(v)
+		if len(v) == 1 {
+			items += ","
+		}
+		return "(" + items + ")", err
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		dict := make(pyDict, len(keys))
+		for i, key := range keys {
+			dict[i] = pyItem{Key: key, Value: v[key]}
+		}
+		return renderPython(dict)
+	case pyDict:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			key, err := renderPython(item.Key)
+			if err != nil {
+				return "", err
+			}
+			val, err := renderPython(item.Value)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = key + ": " + val
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	default:
+		return "", fmt.Errorf("unsupported value of type %T", value)
+	}
+}
+
+func renderItems(items []interface{}) (string, error) {
+	parts := make([]string, len(items))
+	for i, item := range items {
+		rendered, err := renderPython(item)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = rendered
+	}
+	return strings.Join(parts, ", "), nil
+}
+
+// formatFloat renders f the way Python's repr does for common values
+func formatFloat(f float64) string {
+	s := strconv.FormatFloat(f, 'g', -1, 64)
+	if !strings.ContainsAny(s, ".eE") {
+		s += ".0"
+	}
+	return s
+}
+
+// pyQuote renders s as a single-quoted Python string literal
+func pyQuote(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('\'')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			sb.WriteString(fmt.Sprintf(`\x%02x`, s[i]))
+			i++
+			continue
+		}
+		i += size
+
+		switch r {
+		case '\\':
+			sb.WriteString(`\\`)
+		case '\'':
+			sb.WriteString(`\'`)
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\r':
+			sb.WriteString(`\r`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			switch {
+			case r < 0x80 && !unicode.IsPrint(r):
+				sb.WriteString(fmt.Sprintf(`\x%02x`, r))
+			case !unicode.IsPrint(r) && r <= 0xFFFF:
+				sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+			case !unicode.IsPrint(r):
+				sb.WriteString(fmt.Sprintf(`\U%08x`, r))
+			default:
+				sb.WriteRune(r)
+			}
+		}
+	}
+	sb.WriteByte('\'')
+	return sb.String()
+}
+
+// fstringText escapes s for use inside a double-quoted f-string
+func fstringText(s string) string {
+	quoted := pyQuote(s)
+	quoted = quoted[1 : len(quoted)-1]
+	return strings.NewReplacer(`\'`, `'`, `"`, `\"`, "{", "{{", "}", "}}").Replace(quoted)
+}
+
+// commentText keeps s on a single line for use in a Python comment
+func commentText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// docstringText escapes s for use inside a triple-quoted docstring
+func docstringText(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, `\`, `\\`), `"""`, `\"\"\"`)
+}
+
+// parsePythonLiteral parses a Python literal: numbers, strings, True,
+// False, None and nested lists, tuples and dicts of them. Anything else,
+// including names, calls and f-strings, is an error.
+func parsePythonLiteral(s string) (interface{}, error) {
+	p := &literalParser{src: s}
+	value, err := p.value()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos < len(p.src) {
+		return nil, fmt.Errorf("unexpected %q at offset %d", p.src[p.pos:], p.pos)
+	}
+	return value, nil
+}
+
+type literalParser struct {
+	src string
+	pos int
+}
+
+func (p *literalParser) skipSpace() {
+	for p.pos < len(p.src) && strings.IndexByte(" \t\r\n", p.src[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *literalParser) value() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, fmt.Errorf("unexpected end of input")
+	}
+
+	switch ch := p.src[p.pos]; {
+	case ch == '[':
+		p.pos++
+		items, _, err := p.sequence(']')
+		return items, err
+	case ch == '(':
+		p.pos++
+		items, trailingComma, err := p.sequence(')')
+		if err != nil {
+			return nil, err
+		}
+		if len(items) == 1 && !trailingComma {
+			// (x) is a parenthesised value, not a tuple
+			return items[0], nil
+		}
+		return pyTuple(items), nil
+	case ch == '{':
+		p.pos++
+		return p.dict()
+	case ch == '\'' || ch == '"':
+		return p.str()
+	case ch == '-' || ch == '+' || ch == '.' || (ch >= '0' && ch <= '9'):
+		return p.number()
+	default:
+		start := p.pos
+		for p.pos < len(p.src) && (isIdentifier(p.src[start : p.pos+1])) {
+			p.pos++
+		}
+		switch word := p.src[start:p.pos]; word {
+		case "True":
+			return true, nil
+		case "False":
+			return false, nil
+		case "None":
+			return nil, nil
+		case "":
+			return nil, fmt.Errorf("unexpected %q at offset %d", string(ch), start)
+		default:
+			return nil, fmt.Errorf("%q is not a literal", word)
+		}
+	}
+}
+
+// sequence parses comma separated values up to end, which has been opened
+func (p *literalParser) sequence(end byte) ([]interface{}, bool, error) {
+	items := []interface{}{}
+	trailingComma := false
+	for {
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == end {
+			p.pos++
+			return items, trailingComma, nil
+		}
+		item, err := p.value()
+		if err != nil {
+			return nil, false, err
+		}
+		items = append(items, item)
+
+		p.skipSpace()
+		trailingComma = false
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			trailingComma = true
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != end {
+			return nil, false, fmt.Errorf("expected %q at offset %d", string(end), p.pos)
+		}
+	}
+}
+
+func (p *literalParser) dict() (interface{}, error) {
+	dict := pyDict{}
+	for {
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == '}' {
+			p.pos++
+			return dict, nil
+		}
+		key, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		switch key.(type) {
+		case []interface{}, pyDict:
+			return nil, fmt.Errorf("dict keys must be hashable")
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			return nil, fmt.Errorf("expected ':' at offset %d", p.pos)
+		}
+		p.pos++
+		value, err := p.value()
+		if err != nil {
+			return nil, err
+		}
+		dict = append(dict, pyItem{Key: key, Value: value})
+
+		p.skipSpace()
+		if p.pos < len(p.src) && p.src[p.pos] == ',' {
+			p.pos++
+			continue
+		}
+		if p.pos >= len(p.src) || p.src[p.pos] != '}' {
+			return nil, fmt.Errorf("expected '}' at offset %d", p.pos)
+		}
+	}
+}
+
+func (p *literalParser) str() (interface{}, error) {
+	quote := p.src[p.pos : p.pos+1]
+	if strings.HasPrefix(p.src[p.pos:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+	start := p.pos
+	p.pos += len(quote)
+
+	var sb strings.Builder
+	for p.pos < len(p.src) {
+		if strings.HasPrefix(p.src[p.pos:], quote) {
+			p.pos += len(quote)
+			return sb.String(), nil
+		}
+
+		ch := p.src[p.pos]
+		if ch == '\n' && len(quote) == 1 {
+			break
+		}
+		if ch != '\\' {
+			sb.WriteByte(ch)
+			p.pos++
+			continue
+		}
+
+		if p.pos+1 >= len(p.src) {
+			break
+		}
+		esc := p.src[p.pos+1]
+		p.pos += 2
+		switch esc {
+		case '\n':
+		case '\\', '\'', '"':
+			sb.WriteByte(esc)
+		case 'n':
+			sb.WriteByte('\n')
+		case 'r':
+			sb.WriteByte('\r')
+		case 't':
+			sb.WriteByte('\t')
+		case '0':
+			sb.WriteByte(0)
+		case 'x', 'u', 'U':
+			digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[esc]
+			if p.pos+digits > len(p.src) {
+				return nil, fmt.Errorf("truncated \\%c escape at offset %d", esc, p.pos-2)
+			}
+			code, err := strconv.ParseUint(p.src[p.pos:p.pos+digits], 16, 32)
+			if err != nil || code > unicode.MaxRune {
+				return nil, fmt.Errorf("invalid \\%c escape at offset %d", esc, p.pos-2)
+			}
+			sb.WriteRune(rune(code))
+			p.pos += digits
+		default:
+			// Python keeps unknown escapes as written
+			sb.WriteByte('\\')
+			sb.WriteByte(esc)
+		}
+	}
+	return nil, fmt.Errorf("unterminated string starting at offset %d", start)
+}
+
+func (p *literalParser) number() (interface{}, error) {
+	start := p.pos
+	if p.src[p.pos] == '-' || p.src[p.pos] == '+' {
+		p.pos++
+	}
+	for p.pos < len(p.src) {
+		ch := p.src[p.pos]
+		isExponentSign := (ch == '-' || ch == '+') && p.pos > start && (p.src[p.pos-1] == 'e' || p.src[p.pos-1] == 'E') && !strings.ContainsAny(p.src[start:p.pos], "xX")
+		if !(ch == '.' || ch == '_' || isExponentSign || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+
+	if n, ok := pythonInt(text); ok {
+		return pyInt(n.String()), nil
+	}
+	// ParseFloat also accepts hex floats, inf and nan, which are not literals
+	if strings.ContainsAny(text, ".eE") && !strings.ContainsAny(text, "xXpPiInN") && pythonUnderscores(text, false) {
+		if f, err := strconv.ParseFloat(strings.ReplaceAll(text, "_", ""), 64); err == nil && !math.IsInf(f, 0) {
+			return pyFloat(formatFloat(f)), nil
+		}
+	}
+	return nil, fmt.Errorf("%q is not a number", text)
+}
+
+// pythonInt parses an integer literal with Python's rules: decimals are
+// base 10 without leading zeros, other bases need a 0x, 0o or 0b prefix,
+// and single underscores may separate digits
+func pythonInt(text string) (*big.Int, bool) {
+	digits := strings.TrimLeft(text, "+-")
+	if len(text)-len(digits) > 1 {
+		return nil, false
+	}
+	base := 10
+	if len(digits) > 1 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+	}
+	if base != 10 {
+		// An underscore may follow the prefix
+		digits = strings.TrimPrefix(digits[2:], "_")
+	} else if strings.Trim(digits, "0_") != "" && digits[0] == '0' {
+		return nil, false
+	}
+	if !pythonUnderscores(digits, base == 16) {
+		return nil, false
+	}
+	n, ok := new(big.Int).SetString(strings.ReplaceAll(digits, "_", ""), base)
+	if !ok {
+		return nil, false
+	}
+	if text[0] == '-' {
+		n.Neg(n)
+	}
+	return n, true
+}
+
+// pythonUnderscores reports whether every underscore in a number sits
+// between two digits, as Python requires
+func pythonUnderscores(text string, hex bool) bool {
+	isDigit := func(i int) bool {
+		if i < 0 || i >= len(text) {
+			return false
+		}
+		ch := text[i]
+		return ch >= '0' && ch <= '9' || hex && (ch >= 'a' && ch <= 'f' || ch >= 'A' && ch <= 'F')
+	}
+	for i := range len(text) {
+		if text[i] == '_' && !(isDigit(i-1) && isDigit(i+1)) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,131 @@
+package utils
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPythonLiteral(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		declared string
+		want     string
+	}{
+		{"standard", "string", "'standard'"},
+		{"it's", "string", `'it\'s'`},
+		{"a\nb\\c", "string", `'a\nb\\c'`},
+		{"{{other}}", "string", "'{{other}}'"},
+		{"0.2", "string", "'0.2'"},
+		{"None", "string", "None"},
+		{"42", "int", "42"},
+		{float64(7), "int", "7"},
+		{"1_000", "int", "1000"},
+		{"-0x_1f", "int", "-31"},
+		{"0o17", "int", "15"},
+		{"0b1_01", "int", "5"},
+		{"00", "int", "0"},
+		{"[010.5, 1_0e1]", "list", "[10.5, 100.0]"},
+		{float64(1e20), "int", "100000000000000000000"},
+		{float64(-1 << 60), "int", "-1152921504606846976"},
+		{"0.25", "float", "0.25"},
+		{"3", "float", "3.0"},
+		{0.25, "float", "0.25"},
+		{"True", "bool", "True"},
+		{"false", "bool", "False"},
+		{true, "bool", "True"},
+		{"[1, 'a', [2.5]]", "list", "[1, 'a', [2.5]]"},
+		{[]interface{}{"x", float64(1)}, "list", "['x', 1]"},
+		{"(1, 2)", "tuple", "(1, 2)"},
+		{"(1,)", "tuple", "(1,)"},
+		{"[3, 4]", "tuple", "(3, 4)"},
+		{"{'b': 1, 'a': (2, 3)}", "dict", "{'b': 1, 'a': (2, 3)}"},
+		{map[string]interface{}{"b": "x", "a": float64(1)}, "dict", "{'a': 1, 'b': 'x'}"},
+		{"(not a tuple", "any", "'(not a tuple'"},
+		{"0.2", "", "0.2"},
+		{"mean", "", "'mean'"},
+		{"[1, 2]", "any", "[1, 2]"},
+		{"__import__('os')", "", `'__import__(\'os\')'`},
+		{"2024-01-01", "datetime", "'2024-01-01'"},
+		{nil, "int", "None"},
+	}
+
+	for _, tt := range tests {
+		got, err := PythonLiteral(tt.value, tt.declared)
+		if err != nil {
+			t.Errorf("PythonLiteral(%#v, %q) error: %v", tt.value, tt.declared, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("PythonLiteral(%#v, %q) = %s, want %s", tt.value, tt.declared, got, tt.want)
+		}
+	}
+}
+
+func TestPythonLiteralRejectsMismatches(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		declared string
+	}{
+		{"abc", "int"},
+		{"1.5", "int"},
+		{float64(1.5), "int"},
+		{"010", "int"},
+		{"0_10", "int"},
+		{"[010]", "list"},
+		{"1__000", "int"},
+		{"1_", "int"},
+		{"0x", "int"},
+		{"0o8", "int"},
+		{"1_.5", "float"},
+		{math.Inf(1), "int"},
+		{"yes", "bool"},
+		{"(not a tuple", "tuple"},
+		{"[1, 2", "list"},
+		{"{'a': 1}", "list"},
+		{"os.system('x')", "dict"},
+		{"f'{x}'", "list"},
+		{float64(1), "string"},
+		{"[1]", "DataFrame"},
+	}
+
+	for _, tt := range tests {
+		if got, err := PythonLiteral(tt.value, tt.declared); err == nil {
+			t.Errorf("PythonLiteral(%#v, %q) = %s, want an error", tt.value, tt.declared, got)
+		}
+	}
+}
+
+func TestGenerateRejectsUnsafeNodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		node  Node
+		field string
+	}{
+		{"function name", Node{ID: "n1", Code: "os.system('rm -rf /') or f", Stage: 1}, "code"},
+		{"variable name", Node{ID: "n1", Code: "f", Stage: 1, Variables: map[string]interface{}{"a=1); print(1); (": "x"}}, "variables.a=1); print(1); ("},
+		{"typed variable", Node{ID: "n1", Code: "f", Stage: 1, Inputs: []Input{{Name: "k", Type: "int"}}, Variables: map[string]interface{}{"k": "five"}}, "variables.k"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := GenerateExecutableScript(WorkflowConfig{Nodes: []Node{tt.node}}, "", GenerateOptions{})
+			var nodeErr *NodeError
+			if !errors.As(err, &nodeErr) || nodeErr.Field != tt.field {
+				t.Fatalf("err = %v, want a NodeError for %s", err, tt.field)
+			}
+		})
+	}
+}
+
+func TestComponentNamesAreEscaped(t *testing.T) {
+	node := Node{ID: "n1", Name: "Say \"hi\" {x}\nnow", Code: "f", Stage: 1}
+	script, err := GenerateExecutableScript(WorkflowConfig{Nodes: []Node{node}}, "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `print(f"  [1/1] Executing: Say \"hi\" {{x}} now")`; !strings.Contains(script, want) {
+		t.Errorf("script does not contain %s", want)
+	}
+}
@@ -0,0 +1,168 @@
+// src/utils/pythonSyntax.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// checkPythonSyntax tokenizes Python code as the interpreter does when no
+// interpreter is available to compile it. It reports unterminated strings,
+// unbalanced brackets and broken indentation, not invalid statements.
+func checkPythonSyntax(code string) []Diagnostic {
+	s := &syntaxScanner{code: code}
+	indents := []pythonIndentation{{}}
+	lineStart := true // at the start of a logical line
+	blockLine := 0    // line of the ':' waiting for an indented block
+	var last byte     // last token character of the logical line
+
+	for i := 0; i < len(code); {
+		if lineStart && s.depth() == 0 {
+			indent, j := measurePythonIndent(code, i)
+			if j == len(code) {
+				break
+			}
+			if code[j] == '\n' || code[j] == '\r' || code[j] == '#' {
+				i = lineEnd(code, j) + 1 // blank and comment lines do not indent
+				continue
+			}
+			lineStart = false
+
+			top := indents[len(indents)-1]
+			switch {
+			case indent.Width > top.Width != (indent.Spaces > top.Spaces) || indent.Width == top.Width && indent.Spaces != top.Spaces:
+				s.report(j, "inconsistent use of tabs and spaces in indentation")
+			case indent.Width > top.Width:
+				if blockLine == 0 {
+					s.report(j, "unexpected indent")
+				}
+				indents = append(indents, indent)
+			case blockLine != 0:
+				s.report(j, fmt.Sprintf("expected an indented block after line %d", blockLine))
+			case indent.Width < top.Width:
+				for len(indents) > 1 && indents[len(indents)-1].Width > indent.Width {
+					indents = indents[:len(indents)-1]
+				}
+				if indents[len(indents)-1] != indent {
+					s.report(j, "unindent does not match any outer indentation level")
+					indents = append(indents, indent)
+				}
+			}
+			blockLine = 0
+			i = j
+			continue
+		}
+
+		switch ch := code[i]; {
+		case ch == '#':
+			i = lineEnd(code, i)
+			continue
+		case ch == '\\' && strings.HasPrefix(code[i+1:], "\n"):
+			i += 2
+			continue
+		case ch == '\\' && strings.HasPrefix(code[i+1:], "\r\n"):
+			i += 3
+			continue
+		case ch == '\n':
+			if s.depth() == 0 {
+				if last == ':' {
+					blockLine, _ = sourcePosition(code, i)
+				}
+				lineStart, last = true, 0
+			}
+		case ch == '"' || ch == '\'':
+			i = pythonString(s, i)
+			last = ch
+			continue
+		case ch == '(' || ch == '[' || ch == '{':
+			s.open(i)
+			last = ch
+		case ch == ')' || ch == ']' || ch == '}':
+			s.close(i)
+			last = ch
+		case ch == ' ' || ch == '\t' || ch == '\r' || ch == '\f':
+		default:
+			last = ch
+		}
+		i++
+	}
+
+	if s.depth() == 0 && (blockLine != 0 || last == ':') {
+		if blockLine == 0 {
+			blockLine, _ = sourcePosition(code, len(code))
+		}
+		s.report(len(code), fmt.Sprintf("expected an indented block after line %d", blockLine))
+	}
+	s.unclosed()
+	return s.diagnostics
+}
+
+// pythonIndentation is the indentation of a line measured with tabs
+// advancing to the next multiple of 8, as the interpreter does, and with
+// tabs as single spaces; indentation that only compares the same way with
+// both is consistent
+type pythonIndentation struct {
+	Width  int
+	Spaces int
+}
+
+// measurePythonIndent measures the indentation starting at offset i and
+// returns it with the offset of the first character after it
+func measurePythonIndent(code string, i int) (pythonIndentation, int) {
+	var indent pythonIndentation
+	for ; i < len(code); i++ {
+		switch code[i] {
+		case ' ':
+			indent.Width++
+			indent.Spaces++
+		case '\t':
+			indent.Width = (indent.Width/8 + 1) * 8
+			indent.Spaces++
+		case '\f':
+			indent = pythonIndentation{}
+		default:
+			return indent, i
+		}
+	}
+	return indent, i
+}
+
+// lineEnd returns the offset of the newline ending the line at offset i,
+// or len(code) on the last line
+func lineEnd(code string, i int) int {
+	if end := strings.IndexByte(code[i:], '\n'); end >= 0 {
+		return i + end
+	}
+	return len(code)
+}
+
+// pythonString skips the string literal opening at offset i and returns the
+// offset after it; an unterminated string ends at its line or the code
+func pythonString(s *syntaxScanner, i int) int {
+	code := s.code
+	quote := code[i : i+1]
+	if strings.HasPrefix(code[i:], strings.Repeat(quote, 3)) {
+		quote = strings.Repeat(quote, 3)
+	}
+
+	for j := i + len(quote); j < len(code); j++ {
+		switch {
+		case code[j] == '\\':
+			j++
+		case strings.HasPrefix(code[j:], quote):
+			return j + len(quote)
+		case code[j] == '\n' && len(quote) == 1:
+			line, _ := sourcePosition(code, j)
+			s.report(i, fmt.Sprintf("unterminated string literal (detected at line %d)", line))
+			return j
+		}
+	}
+
+	line, _ := sourcePosition(code, len(strings.TrimRight(code, "\r\n")))
+	if len(quote) == 3 {
+		s.report(i, fmt.Sprintf("unterminated triple-quoted string literal (detected at line %d)", line))
+	} else {
+		s.report(i, fmt.Sprintf("unterminated string literal (detected at line %d)", line))
+	}
+	return len(code)
+}
@@ -0,0 +1,139 @@
+// src/utils/references.util.go
+package utils
+
+import (
+	"go/token"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// callPattern matches a name followed by an opening parenthesis
+var callPattern = regexp.MustCompile(`[A-Za-z_$][A-Za-z0-9_$]*\s*\(`)
+
+// definitionKeywords precede the names of functions and classes defined in
+// code
+var definitionKeywords = map[string][]string{
+	LanguagePython:     {"def", "class"},
+	LanguageJavaScript: {"function"},
+	LanguageGo:         {"func"},
+}
+
+// FunctionCalls returns the names of the functions code calls that it
+// does not define itself, sorted. Method calls and names inside strings
+// and comments are left out; components calling each other are found by
+// matching these names with the function names of other components.
+func FunctionCalls(language, code string) []string {
+	if language == "" {
+		language = DefaultLanguage
+	}
+	keywords := definitionKeywords[language]
+	code = blankLiterals(language, code)
+
+	defined := map[string]bool{}
+	called := map[string]bool{}
+	for _, m := range callPattern.FindAllStringIndex(code, -1) {
+		// Names matched inside longer names and method calls are skipped
+		if isNameByte(code, m[0]-1) || m[0] > 0 && code[m[0]-1] == '.' {
+			continue
+		}
+		name := strings.TrimRight(code[m[0]:m[1]-1], " \t\r\n")
+		before := strings.TrimRight(code[:m[0]], " \t")
+		definition := false
+		for _, keyword := range keywords {
+			if strings.HasSuffix(before, keyword) && !isNameByte(before, len(before)-len(keyword)-1) {
+				definition = true
+			}
+		}
+		if definition {
+			defined[name] = true
+		} else {
+			called[name] = true
+		}
+	}
+
+	var names []string
+	for name := range called {
+		if !defined[name] && !isKeyword(language, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// isKeyword reports whether name is a keyword of language, such as if or
+// return, which may be followed by a parenthesis
+func isKeyword(language, name string) bool {
+	switch language {
+	case LanguageJavaScript:
+		return jsReservedWords[name]
+	case LanguageGo:
+		return token.IsKeyword(name)
+	}
+	return pythonKeywords[name]
+}
+
+// isNameByte reports whether s[i] is part of a name; out of range is not
+func isNameByte(s string, i int) bool {
+	if i < 0 || i >= len(s) {
+		return false
+	}
+	c := s[i]
+	return c == '_' || c == '$' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9'
+}
+
+// blankLiterals replaces the contents of strings and comments with spaces,
+// keeping line breaks, so that only code is searched for calls
+func blankLiterals(language, code string) string {
+	out := []byte(code)
+	blank := func(from, to int) {
+		for i := from; i < to && i < len(out); i++ {
+			if out[i] != '\n' {
+				out[i] = ' '
+			}
+		}
+	}
+
+	for i := 0; i < len(code); i++ {
+		c := code[i]
+		switch {
+		case language == LanguagePython && c == '#',
+			language != LanguagePython && strings.HasPrefix(code[i:], "//"):
+			end := lineEnd(code, i)
+			blank(i, end)
+			i = end - 1
+		case language != LanguagePython && strings.HasPrefix(code[i:], "/*"):
+			end := strings.Index(code[i+2:], "*/")
+			if end < 0 {
+				end = len(code)
+			} else {
+				end += i + 4
+			}
+			blank(i, end)
+			i = end - 1
+		case c == '"' || c == '\'' || c == '`':
+			quote := code[i : i+1]
+			if language == LanguagePython && strings.HasPrefix(code[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+			}
+			end := i + len(quote)
+			for end < len(code) && !strings.HasPrefix(code[end:], quote) {
+				if code[end] == '\n' && len(quote) == 1 && c != '`' {
+					break // unterminated
+				}
+				if code[end] == '\\' && (c != '`' || language == LanguageJavaScript) {
+					end++
+				}
+				end++
+			}
+			end = min(end, len(code))
+			blank(i+len(quote), end)
+			if strings.HasPrefix(code[end:], quote) {
+				end += len(quote)
+			}
+			i = end - 1
+		}
+	}
+	return string(out)
+}
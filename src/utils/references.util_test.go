@@ -0,0 +1,30 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFunctionCalls(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		code     string
+		want     []string
+	}{
+		{"python", LanguagePython, "import pandas as pd\n\nclass Scaler(Base):\n    pass\n\ndef clean(df, cols=normalize_names(['a'])):\n    # drop_rows(df)\n    s = \"fill_gaps(df)\"\n    doc = '''\n    helper(x)\n    '''\n    if (len(df) > 0):\n        df = remove_outliers(df).copy()\n    return pd.DataFrame(Scaler().fit(df))\n",
+			[]string{"len", "normalize_names", "remove_outliers"}},
+		{"javascript", LanguageJavaScript, "function clean(rows) {\n  // drop(rows)\n  const s = `fill(${rows})`;\n  if (rows.length) { return normalize(rows).map(r => trim(r)); }\n  /* dedupe(rows) */\n  return rows;\n}\n",
+			[]string{"normalize", "trim"}},
+		{"go", LanguageGo, "func Clean(t Table, p Params) (Table, error) {\n\t// Drop(t)\n\ts := \"Fill(t)\"\n\tif len(s) > 0 {\n\t\treturn Normalize(t, p)\n\t}\n\treturn t, nil\n}\n",
+			[]string{"Normalize", "len"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FunctionCalls(tt.language, tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FunctionCalls() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
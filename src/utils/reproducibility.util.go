@@ -0,0 +1,200 @@
+// src/utils/reproducibility.util.go
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// RandomStateParam is the parameter seeded components receive
+const RandomStateParam = "random_state"
+
+// GenerateOptions controls the parts of generated code that would otherwise
+// differ between runs
+type GenerateOptions struct {
+	// Timestamp is written to the header instead of the current time
+	Timestamp time.Time
+	// Seed, when set, seeds random and numpy and is passed as random_state
+	// to every component that accepts it
+	Seed *int64
+	// Template renders the script and the pipeline code of the notebook,
+	// Airflow and Prefect exports; the default template when nil
+	Template *ScriptTemplate
+}
+
+// template returns the template to render with
+func (o GenerateOptions) template() *ScriptTemplate {
+	if o.Template == nil {
+		return DefaultScriptTemplate()
+	}
+	return o.Template
+}
+
+// timestamp returns the header timestamp in RFC 3339
+func (o GenerateOptions) timestamp() string {
+	if o.Timestamp.IsZero() {
+		return time.Now().Format(time.RFC3339)
+	}
+	return o.Timestamp.UTC().Format(time.RFC3339)
+}
+
+// WorkflowHash returns a sha256 over the workflow and the component code.
+// ExportedAt, DatasetID and DataFormat are left out so re-exporting the
+// same workflow, or running it on other data, keeps its hash, and the
+// default task type, target column and language hash like missing ones.
+func WorkflowHash(workflow WorkflowConfig, componentCode string) string {
+	workflow.ExportedAt = ""
+	workflow.DatasetID = ""
+	workflow.DataFormat = ""
+	if workflow.TaskType == DefaultTaskType {
+		workflow.TaskType = ""
+	}
+	if workflow.TargetColumn == DefaultTargetColumn {
+		workflow.TargetColumn = ""
+	}
+	workflow.Nodes = append([]Node(nil), workflow.Nodes...)
+	for i := range workflow.Nodes {
+		if workflow.Nodes[i].Language == DefaultLanguage {
+			workflow.Nodes[i].Language = ""
+		}
+	}
+	// encoding/json sorts map keys, so equal workflows encode equally
+	data, _ := json.Marshal(workflow)
+
+	h := sha256.New()
+	h.Write(data)
+	h.Write([]byte{0})
+	h.Write([]byte(componentCode))
+	return "sha256:" + hex.EncodeToString(h.Sum(nil))
+}
+
+// seedCode seeds the Python random number generators, or is empty when no
+// seed is set
+func seedCode(opts GenerateOptions) string {
+	if opts.Seed == nil {
+		return ""
+	}
+	return fmt.Sprintf(`
+# Fixed seed for reproducible runs
+import os
+import random
+SEED = %d
+os.environ['PYTHONHASHSEED'] = str(SEED)  # applies to worker subprocesses
+random.seed(SEED)
+np.random.seed(SEED)
+`, *opts.Seed)
+}
+
+// seedWorkflow returns a copy of the workflow in which every node whose
+// component accepts random_state, by declared input or by its signature in
+// componentCode, and does not set it, gets random_state set to the seed
+func seedWorkflow(workflow WorkflowConfig, componentCode string, opts GenerateOptions) WorkflowConfig {
+	if opts.Seed == nil {
+		return workflow
+	}
+
+	nodes := make([]Node, len(workflow.Nodes))
+	for i, node := range workflow.Nodes {
+		nodes[i] = node
+		if value, ok := node.Variables[RandomStateParam]; ok && value != "" {
+			continue
+		}
+
+		_, funcName := componentNames(node)
+		accepts := containsString(functionParams(componentCode, funcName), RandomStateParam)
+		for _, input := range node.Inputs {
+			accepts = accepts || input.Name == RandomStateParam
+		}
+		if !accepts {
+			continue
+		}
+
+		variables := make(map[string]interface{}, len(node.Variables)+1)
+		for name, value := range node.Variables {
+			variables[name] = value
+		}
+		variables[RandomStateParam] = float64(*opts.Seed)
+		nodes[i].Variables = variables
+	}
+
+	workflow.Nodes = nodes
+	return workflow
+}
+
+// functionParams returns the parameter names of a top-level function
+// defined in code
+func functionParams(code, funcName string) []string {
+	var names []string
+	for _, param := range signatureParams(code, funcName) {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// signatureParam is a parameter of a Python function with its annotation
+type signatureParam struct {
+	Name       string
+	Annotation string // "" when the parameter has none
+}
+
+// signatureParams returns the parameters of a top-level function defined
+// in code
+func signatureParams(code, funcName string) []signatureParam {
+	def := regexp.MustCompile(`(?m)^def\s+` + regexp.QuoteMeta(funcName) + `\s*\(`)
+	loc := def.FindStringIndex(code)
+	if loc == nil {
+		return nil
+	}
+
+	// Collect the text up to the closing parenthesis of the signature
+	depth := 1
+	end := loc[1]
+	for ; end < len(code) && depth > 0; end++ {
+		switch code[end] {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+	}
+	signature := code[loc[1] : end-1]
+
+	var params []signatureParam
+	depth = 0
+	start := 0
+	for i := 0; i <= len(signature); i++ {
+		if i < len(signature) {
+			switch signature[i] {
+			case '(', '[', '{':
+				depth++
+				continue
+			case ')', ']', '}':
+				depth--
+				continue
+			case ',':
+			default:
+				continue
+			}
+			if depth > 0 {
+				continue
+			}
+		}
+
+		param := strings.TrimSpace(signature[start:i])
+		start = i + 1
+		param = strings.TrimLeft(param, "*")
+		if j := strings.Index(param, "="); j >= 0 {
+			param = param[:j]
+		}
+		name, annotation, _ := strings.Cut(param, ":")
+		if name = strings.TrimSpace(name); isIdentifier(name) {
+			params = append(params, signatureParam{Name: name, Annotation: strings.TrimSpace(annotation)})
+		}
+	}
+	return params
+}
@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	code, err := os.ReadFile(filepath.Join("testdata", "components.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow := loadWorkflow(t, "workflow.json")
+
+	for _, format := range ExportFormats() {
+		t.Run(format, func(t *testing.T) {
+			exporter, _ := GetExporter(format)
+			first, err := exporter.Generate(workflow, string(code), goldenOptions())
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 0; i < 5; i++ {
+				again, err := exporter.Generate(workflow, string(code), goldenOptions())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(first, again) {
+					t.Fatalf("run %d differs from the first run", i+2)
+				}
+			}
+
+			var zips [2]bytes.Buffer
+			for i := range zips {
+				bundle, err := NewBundle(exporter, workflow, string(code), "a,target\n1,0\n", goldenOptions())
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := bundle.WriteZip(&zips[i]); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(zips[0].Bytes(), zips[1].Bytes()) {
+				t.Error("bundles differ between runs")
+			}
+		})
+	}
+}
+
+func TestVariableOrderFollowsInputs(t *testing.T) {
+	node := Node{
+		Inputs: []Input{{Name: "zeta"}, {Name: "alpha"}, {Name: "unset"}},
+		Variables: map[string]interface{}{
+			"gamma": "g", "alpha": "a", "beta": "b", "zeta": "z",
+		},
+	}
+
+	want := []string{"zeta", "alpha", "beta", "gamma"}
+	if got := variableOrder(node); !reflect.DeepEqual(got, want) {
+		t.Errorf("variableOrder = %v, want %v", got, want)
+	}
+}
+
+func TestSeedWorkflow(t *testing.T) {
+	code := "def fit(X, y,\n        n_estimators=100, *, random_state=None):\n    pass\n\ndef clean(df):\n    pass\n"
+	workflow := WorkflowConfig{Nodes: []Node{
+		{ID: "fit", Code: "fit"},
+		{ID: "clean", Code: "clean"},
+		{ID: "declared", Code: "other", Inputs: []Input{{Name: RandomStateParam}}},
+		{ID: "explicit", Code: "fit", Variables: map[string]interface{}{RandomStateParam: float64(7)}},
+	}}
+
+	seed := int64(3)
+	seeded := seedWorkflow(workflow, code, GenerateOptions{Seed: &seed})
+
+	want := map[string]interface{}{"fit": float64(3), "clean": nil, "declared": float64(3), "explicit": float64(7)}
+	for _, node := range seeded.Nodes {
+		if got := node.Variables[RandomStateParam]; got != want[node.ID] {
+			t.Errorf("%s: random_state = %v, want %v", node.ID, got, want[node.ID])
+		}
+	}
+	if workflow.Nodes[0].Variables != nil {
+		t.Error("seedWorkflow modified the original workflow")
+	}
+}
+
+func TestWorkflowHash(t *testing.T) {
+	workflow := loadWorkflow(t, "workflow.json")
+	hash := WorkflowHash(workflow, "code")
+
+	reexported := workflow
+	reexported.ExportedAt = "2025-06-01T12:00:00Z"
+	if got := WorkflowHash(reexported, "code"); got != hash {
+		t.Errorf("hash changed with ExportedAt: %s != %s", got, hash)
+	}
+	if got := WorkflowHash(workflow, "other code"); got == hash {
+		t.Error("hash did not change with the component code")
+	}
+
+	defaultTarget := workflow
+	defaultTarget.TargetColumn = DefaultTargetColumn
+	if got := WorkflowHash(defaultTarget, "code"); got != hash {
+		t.Errorf("hash changed with the default target column: %s != %s", got, hash)
+	}
+}
+
+func TestBundleCommandUsesTargetColumn(t *testing.T) {
+	workflow := loadWorkflow(t, "workflow.json")
+	workflow.TargetColumn = "churn rate"
+	exporter, _ := GetExporter(DefaultExportFormat)
+
+	bundle, err := NewBundle(exporter, workflow, "", "", goldenOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range bundle.Files {
+		if file.Name == "README.md" && !bytes.Contains(file.Content, []byte("--target 'churn rate' --output")) {
+			t.Errorf("README does not pass the target column:\n%s", file.Content)
+		}
+	}
+}
+
+func TestBundleUsesDataFormat(t *testing.T) {
+	workflow := loadWorkflow(t, "workflow.json")
+	workflow.DataFormat = FormatParquet
+	exporter, _ := GetExporter("ipynb")
+
+	bundle, err := NewBundle(exporter, workflow, "", "PAR1", goldenOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{}
+	for _, file := range bundle.Files {
+		files[file.Name] = file.Content
+	}
+	if _, ok := files["data.parquet"]; !ok {
+		t.Error("bundle does not hold the sample as data.parquet")
+	}
+	if !bytes.Contains(files["Dockerfile"], []byte("-p data_file data.parquet")) {
+		t.Errorf("Dockerfile does not pass the data file:\n%s", files["Dockerfile"])
+	}
+	if !bytes.Contains(files["requirements.txt"], []byte("pyarrow")) {
+		t.Errorf("requirements.txt does not hold pyarrow:\n%s", files["requirements.txt"])
+	}
+	if !bytes.Contains(files["workflow.ipynb"], []byte(`data_file = 'data.parquet'`)) {
+		t.Error("notebook parameters do not name the data file")
+	}
+
+	// The data format does not change the hash
+	if WorkflowHash(workflow, "") != WorkflowHash(loadWorkflow(t, "workflow.json"), "") {
+		t.Error("WorkflowHash depends on the data format")
+	}
+}
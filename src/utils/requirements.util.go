@@ -0,0 +1,336 @@
+// src/utils/requirements.util.go
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// pypiNames maps import names to the PyPI package that provides them when
+// the two differ
+var pypiNames = map[string]string{
+	"sklearn":           "scikit-learn",
+	"skimage":           "scikit-image",
+	"imblearn":          "imbalanced-learn",
+	"category_encoders": "category-encoders",
+	"umap":              "umap-learn",
+	"cv2":               "opencv-python",
+	"PIL":               "Pillow",
+	"yaml":              "PyYAML",
+	"bs4":               "beautifulsoup4",
+	"dateutil":          "python-dateutil",
+	"dotenv":            "python-dotenv",
+	"docx":              "python-docx",
+	"jwt":               "PyJWT",
+	"Crypto":            "pycryptodome",
+	"serial":            "pyserial",
+	"attr":              "attrs",
+	"mpl_toolkits":      "matplotlib",
+	"pyarrow":           "pyarrow",
+	"tf_keras":          "tf-keras",
+}
+
+// stdlibModules lists the standard library modules that never need a package
+var stdlibModules = map[string]bool{
+	"__future__": true, "abc": true, "argparse": true, "array": true, "ast": true,
+	"asyncio": true, "base64": true, "binascii": true, "bisect": true, "builtins": true,
+	"bz2": true, "calendar": true, "cmath": true, "codecs": true, "collections": true,
+	"concurrent": true, "configparser": true, "contextlib": true, "copy": true, "csv": true,
+	"ctypes": true, "dataclasses": true, "datetime": true, "decimal": true, "difflib": true,
+	"enum": true, "errno": true, "fnmatch": true, "fractions": true, "functools": true,
+	"gc": true, "getpass": true, "glob": true, "gzip": true, "hashlib": true,
+	"heapq": true, "hmac": true, "html": true, "http": true, "importlib": true,
+	"inspect": true, "io": true, "ipaddress": true, "itertools": true, "json": true,
+	"locale": true, "logging": true, "lzma": true, "math": true, "mimetypes": true,
+	"multiprocessing": true, "numbers": true, "operator": true, "os": true, "pathlib": true,
+	"pickle": true, "platform": true, "pprint": true, "queue": true, "random": true,
+	"re": true, "secrets": true, "shelve": true, "shutil": true, "signal": true,
+	"socket": true, "sqlite3": true, "statistics": true, "string": true, "struct": true,
+	"subprocess": true, "sys": true, "tarfile": true, "tempfile": true, "textwrap": true,
+	"threading": true, "time": true, "timeit": true, "traceback": true, "types": true,
+	"typing": true, "unicodedata": true, "unittest": true, "urllib": true, "uuid": true,
+	"warnings": true, "weakref": true, "xml": true, "zipfile": true, "zlib": true,
+	"zoneinfo": true,
+}
+
+var (
+	pinsMu sync.RWMutex
+	pins   = map[string]string{}
+)
+
+// PinPackages pins package versions in generated requirements. spec is a
+// comma separated list such as "scikit-learn==1.5.2,pandas==2.2.3".
+func PinPackages(spec string) error {
+	parsed, err := parsePins(spec)
+	if err != nil {
+		return err
+	}
+
+	pinsMu.Lock()
+	defer pinsMu.Unlock()
+	for name, version := range parsed {
+		pins[name] = version
+	}
+	return nil
+}
+
+// parsePins parses a comma separated list of name==version pins into
+// versions by normalized package name
+func parsePins(spec string) (map[string]string, error) {
+	parsed := make(map[string]string)
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, version, ok := strings.Cut(entry, "==")
+		name, version = strings.TrimSpace(name), strings.TrimSpace(version)
+		if !ok || name == "" || version == "" {
+			return nil, fmt.Errorf("invalid pin %q, expected name==version", entry)
+		}
+		parsed[normalizePackage(name)] = version
+	}
+	return parsed, nil
+}
+
+func mustParsePins(spec string) map[string]string {
+	parsed, err := parsePins(spec)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// PackageForImport returns the PyPI package providing a top-level import,
+// or false for standard library modules
+func PackageForImport(module string) (string, bool) {
+	if stdlibModules[module] {
+		return "", false
+	}
+	if name, ok := pypiNames[module]; ok {
+		return name, true
+	}
+	return module, true
+}
+
+// ScanImports returns the top-level modules imported by Python code in
+// order of first use. Relative imports, comments and string literals are
+// ignored.
+func ScanImports(code string) []string {
+	var modules []string
+	seen := make(map[string]bool)
+	add := func(name string) {
+		name = strings.TrimSpace(name)
+		if i := strings.IndexByte(name, '.'); i >= 0 {
+			name = name[:i]
+		}
+		if isIdentifier(name) && !seen[name] {
+			seen[name] = true
+			modules = append(modules, name)
+		}
+	}
+
+	for _, statement := range pythonStatements(code) {
+		fields := strings.Fields(statement)
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "import":
+			for _, part := range strings.Split(strings.TrimPrefix(statement, "import"), ",") {
+				// drop "as alias"
+				if names := strings.Fields(part); len(names) > 0 {
+					add(names[0])
+				}
+			}
+		case "from":
+			if !strings.HasPrefix(fields[1], ".") && len(fields) > 2 && fields[2] == "import" {
+				add(fields[1])
+			}
+		}
+	}
+	return modules
+}
+
+// pythonStatements splits code into simple statements with comments,
+// string literals and line continuations removed
+func pythonStatements(code string) []string {
+	var statements []string
+	var current strings.Builder
+	var quote string // open string delimiter
+	depth := 0
+
+	flush := func() {
+		if s := strings.TrimSpace(current.String()); s != "" {
+			statements = append(statements, s)
+		}
+		current.Reset()
+	}
+
+	for i := 0; i < len(code); i++ {
+		ch := code[i]
+
+		if quote != "" {
+			if ch == '\\' {
+				i++
+			} else if strings.HasPrefix(code[i:], quote) {
+				i += len(quote) - 1
+				quote = ""
+			} else if ch == '\n' && len(quote) == 1 {
+				quote = ""
+				flush()
+			}
+			continue
+		}
+
+		switch {
+		case ch == '#':
+			for i < len(code) && code[i] != '\n' {
+				i++
+			}
+			i--
+		case ch == '"' || ch == '\'':
+			quote = string(ch)
+			if strings.HasPrefix(code[i:], strings.Repeat(quote, 3)) {
+				quote = strings.Repeat(quote, 3)
+				i += 2
+			}
+			current.WriteString(" _ ")
+		case ch == '\\' && i+1 < len(code) && code[i+1] == '\n':
+			current.WriteByte(' ')
+			i++
+		case ch == '(' || ch == '[' || ch == '{':
+			depth++
+			current.WriteByte(' ')
+		case ch == ')' || ch == ']' || ch == '}':
+			if depth > 0 {
+				depth--
+			}
+			current.WriteByte(' ')
+		case ch == ';' || (ch == '\n' && depth == 0):
+			flush()
+		case ch == '\n':
+			current.WriteByte(' ')
+		default:
+			current.WriteByte(ch)
+		}
+	}
+	flush()
+	return statements
+}
+
+func isIdentifier(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i, r := range s {
+		if r != '_' && !('a' <= r && r <= 'z') && !('A' <= r && r <= 'Z') && (i == 0 || !('0' <= r && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizePackage returns the PEP 503 normalized form of a package name
+func normalizePackage(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	return strings.NewReplacer("_", "-", ".", "-").Replace(name)
+}
+
+// requirementName returns the package name of a requirement specifier
+func requirementName(requirement string) string {
+	if i := strings.IndexAny(requirement, "=<>!~[; "); i >= 0 {
+		return requirement[:i]
+	}
+	return requirement
+}
+
+// PythonRequirements returns the sorted pip requirements of the packages
+// imported by the given Python sources, with pinned versions applied
+func PythonRequirements(sources ...string) []string {
+	return MergeRequirements(importedPackages(sources...))
+}
+
+// importedPackages returns the packages imported by Python sources, with
+// repeats
+func importedPackages(sources ...string) []string {
+	var packages []string
+	for _, source := range sources {
+		for _, module := range ScanImports(source) {
+			if name, ok := PackageForImport(module); ok {
+				packages = append(packages, name)
+			}
+		}
+	}
+	return packages
+}
+
+// MergeRequirements combines requirement lists into one sorted list with a
+// single entry per package. An explicit version specifier wins over a bare
+// name, and bare names get the pinned version if one is configured.
+func MergeRequirements(lists ...[]string) []string {
+	merged := make(map[string]string)
+	for _, list := range lists {
+		for _, requirement := range list {
+			requirement = strings.TrimSpace(requirement)
+			name := requirementName(requirement)
+			if name == "" {
+				continue
+			}
+			key := normalizePackage(name)
+			if existing, ok := merged[key]; !ok || (existing == requirementName(existing) && requirement != name) {
+				merged[key] = requirement
+			}
+		}
+	}
+
+	pinsMu.RLock()
+	defer pinsMu.RUnlock()
+
+	requirements := make([]string, 0, len(merged))
+	for key, requirement := range merged {
+		if version, ok := pins[key]; ok && requirement == requirementName(requirement) {
+			requirement += "==" + version
+		}
+		requirements = append(requirements, requirement)
+	}
+	sort.Slice(requirements, func(i, j int) bool {
+		return normalizePackage(requirements[i]) < normalizePackage(requirements[j])
+	})
+	return requirements
+}
+
+// WorkflowRequirements returns the requirements of a generated workflow:
+// the packages imported by the component code and by the generated
+// pipeline, which label-encodes classification targets with scikit-learn
+// for trainers, evaluators and cross-validators and reads the workflow's
+// data format, and Arrow files of the arrow bridge, with the package pandas
+// needs for it. The dependencies of Python nodes are merged in, so the
+// versions their components state win over bare imports.
+func WorkflowRequirements(workflow WorkflowConfig, componentCode string) []string {
+	sources := []string{pythonImports, componentCode}
+	if format, ok := GetDatasetFormat(workflow.DataFormat); ok && format.Module != "" {
+		sources = append(sources, "import "+format.Module)
+	}
+	if workflow.Bridge == BridgeArrow {
+		sources = append(sources, "import pyarrow")
+	}
+	var dependencies []string
+	for _, node := range workflow.Nodes {
+		if node.Language == "" || node.Language == LanguagePython {
+			dependencies = append(dependencies, node.Dependencies...)
+		}
+	}
+	if task, err := newScriptTask(workflow.TaskType); err != nil || task.EncodeLabels {
+		for _, node := range workflow.Nodes {
+			_, funcName := componentNames(node)
+			if role, _ := nodeRole(node, funcName); role == RoleTrainer || role == RoleEvaluator || role == RoleCrossValidator {
+				sources = append(sources, "from sklearn.preprocessing import LabelEncoder")
+				break
+			}
+		}
+	}
+	return MergeRequirements(importedPackages(sources...), dependencies)
+}
@@ -0,0 +1,99 @@
+package utils
+
+import (
+	"reflect"
+	"testing"
+)
+
+// withPins pins packages for the duration of a test
+func withPins(t *testing.T, spec string) {
+	t.Helper()
+	if err := PinPackages(spec); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		pinsMu.Lock()
+		defer pinsMu.Unlock()
+		pins = map[string]string{}
+	})
+}
+
+func TestScanImports(t *testing.T) {
+	code := `import os, numpy as np
+from sklearn.ensemble import RandomForestClassifier
+from . import helpers
+from .local import thing
+import pandas.api.types  # import requests
+text = "import flask"
+doc = """
+import django
+"""
+x = (1,
+import_count) ; import yaml
+import \
+    scipy
+`
+	want := []string{"os", "numpy", "sklearn", "pandas", "yaml", "scipy"}
+	if got := ScanImports(code); !reflect.DeepEqual(got, want) {
+		t.Errorf("ScanImports() = %v, want %v", got, want)
+	}
+}
+
+func TestPackageForImport(t *testing.T) {
+	tests := []struct {
+		module, want string
+		ok           bool
+	}{
+		{"os", "", false},
+		{"sklearn", "scikit-learn", true},
+		{"PIL", "Pillow", true},
+		{"pandas", "pandas", true},
+	}
+	for _, tt := range tests {
+		if got, ok := PackageForImport(tt.module); got != tt.want || ok != tt.ok {
+			t.Errorf("PackageForImport(%q) = %q, %v, want %q, %v", tt.module, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMergeRequirements(t *testing.T) {
+	withPins(t, "numpy==1.26.4, Scikit_Learn==1.5.2")
+
+	got := MergeRequirements(
+		[]string{"pandas", "numpy", "scikit-learn"},
+		[]string{"pandas>=2.0", " requests ", "", "scikit_learn~=1.4"},
+		[]string{"pandas==1.5.3"},
+	)
+	want := []string{"numpy==1.26.4", "pandas>=2.0", "requests", "scikit_learn~=1.4"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("MergeRequirements() = %v, want %v", got, want)
+	}
+}
+
+func TestPinPackagesErrors(t *testing.T) {
+	for _, spec := range []string{"pandas", "pandas==", "==2.2.3", "pandas>=2.0"} {
+		if err := PinPackages(spec); err == nil {
+			t.Errorf("PinPackages(%q) error = nil", spec)
+		}
+	}
+}
+
+func TestWorkflowRequirements(t *testing.T) {
+	withPins(t, "pandas==2.2.3")
+
+	workflow := WorkflowConfig{
+		TaskType: TaskRegression,
+		Nodes: []Node{
+			{ID: "n1", Stage: 1, Code: "load", Dependencies: []string{"requests==2.32.3", "numpy>=1.26"}},
+			{ID: "n2", Stage: 2, Code: "scale", Language: LanguagePython, Dependencies: []string{"pandas"}},
+			{ID: "n3", Stage: 2, Code: "plot", Language: LanguageJavaScript, Dependencies: []string{"chart.js"}},
+		},
+	}
+	code := "import numpy as np\nimport requests\n"
+
+	got := WorkflowRequirements(workflow, code)
+	want := []string{"numpy>=1.26", "pandas==2.2.3", "requests==2.32.3"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("WorkflowRequirements() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,92 @@
+// src/utils/roles.util.go
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Component roles decide how the generated pipeline calls a component
+const (
+	RoleLoader         = "loader"          // reads the input data: fn(data_file, ...)
+	RoleTransform      = "transform"       // returns new features: fn(data, ...)
+	RoleRowFilter      = "row_filter"      // a transform that drops rows; the target is re-aligned
+	RoleSplitter       = "splitter"        // fn(df, target_column, ...) -> X_train, X_test, y_train, y_test
+	RoleTrainer        = "trainer"         // fn(X, y, ...) -> model
+	RoleEvaluator      = "evaluator"       // fn(y_true, y_pred, y_pred_proba, ...) -> metrics
+	RoleCrossValidator = "cross_validator" // fn(model, X, y, ...) -> cv results
+	RoleSink           = "sink"            // writes the processed data: fn(data, ...)
+)
+
+// Roles lists the component roles in pipeline order
+var Roles = []string{
+	RoleLoader, RoleTransform, RoleRowFilter, RoleSplitter,
+	RoleTrainer, RoleEvaluator, RoleCrossValidator, RoleSink,
+}
+
+// IsValidRole checks a component role
+func IsValidRole(role string) bool {
+	return containsString(Roles, role)
+}
+
+// InferRole guesses the role of a component without one from its stage
+// and function name: the role its name suggests when the stage allows it,
+// otherwise the first role of the stage. It only backfills components and
+// workflows saved before roles existed; new components state their role.
+func InferRole(funcName string, stage int) string {
+	lower := strings.ToLower(funcName)
+	guesses := []struct {
+		role  string
+		match bool
+	}{
+		{RoleCrossValidator, containsAny(lower, "cross", "kfold", "k_fold", "_cv", "crossval")},
+		{RoleSplitter, containsAny(lower, "split", "stratified")},
+		// Dropping features or columns keeps every row
+		{RoleRowFilter, containsAny(lower, "outlier", "remove", "drop", "filter") && !containsAny(lower, "feature", "column", "col_")},
+		{RoleTransform, true},
+	}
+
+	allowed, _ := StageByOrder(stage)
+	for _, guess := range guesses {
+		if guess.match && allowed.AllowsRole(guess.role) {
+			return guess.role
+		}
+	}
+	if len(allowed.Roles) > 0 {
+		return allowed.Roles[0]
+	}
+	return RoleTransform
+}
+
+// nodeRole returns the role of a node, inferred when the workflow has none
+func nodeRole(node Node, funcName string) (string, error) {
+	if node.Role == "" {
+		return InferRole(funcName, node.Stage), nil
+	}
+	if !IsValidRole(node.Role) {
+		return "", &NodeError{NodeID: node.ID, Field: "role", Message: fmt.Sprintf("invalid role %q, must be one of: %s", node.Role, strings.Join(Roles, ", "))}
+	}
+	return node.Role, nil
+}
+
+// topLevelDef matches the first top-level function definition
+var topLevelDef = regexp.MustCompile(`(?m)^(?:async\s+)?def\s+([A-Za-z_][A-Za-z0-9_]*)\s*\(`)
+
+// FunctionName returns the name of the first top-level Python function in
+// code, or "" when there is none
+func FunctionName(code string) string {
+	if m := topLevelDef.FindStringSubmatch(code); m != nil {
+		return m[1]
+	}
+	return ""
+}
+
+func containsAny(s string, substrings ...string) bool {
+	for _, sub := range substrings {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,103 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInferRole(t *testing.T) {
+	tests := []struct {
+		funcName string
+		stage    int
+		want     string
+	}{
+		{"remove_duplicates", 1, RoleRowFilter},
+		{"remove_outliers", 1, RoleRowFilter},
+		{"drop_low_variance_features", 2, RoleTransform},
+		{"drop_columns", 1, RoleTransform},
+		{"train_test_split_data", 1, RoleSplitter},
+		{"stratified_sample", 2, RoleSplitter},
+		{"scale_features", 2, RoleTransform},
+		{"train_random_forest", 3, RoleTrainer},
+		{"cross_validate_model", 4, RoleCrossValidator},
+		{"kfold_score", 4, RoleCrossValidator},
+		{"classification_metrics", 4, RoleEvaluator},
+	}
+
+	for _, tt := range tests {
+		if got := InferRole(tt.funcName, tt.stage); got != tt.want {
+			t.Errorf("InferRole(%q, %d) = %q, want %q", tt.funcName, tt.stage, got, tt.want)
+		}
+	}
+}
+
+func TestFunctionName(t *testing.T) {
+	code := "import pandas as pd\n\n@decorator\ndef load_csv(path):\n    def inner():\n        pass\n"
+	if got := FunctionName(code); got != "load_csv" {
+		t.Errorf("FunctionName = %q, want load_csv", got)
+	}
+	if got := FunctionName("x = 1\n"); got != "" {
+		t.Errorf("FunctionName = %q, want none", got)
+	}
+}
+
+func TestExplicitRoleOverridesName(t *testing.T) {
+	workflow := WorkflowConfig{Version: "1.0", Nodes: []Node{
+		{ID: "n1", Name: "Split Date", Stage: 1, Role: RoleTransform, Code: "split_date_parts"},
+		{ID: "n2", Name: "Drop Rare", Stage: 2, Role: RoleRowFilter, Code: "rare_categories"},
+	}}
+
+	script, err := GenerateExecutableScript(workflow, "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "split_date_parts(df, target_column") {
+		t.Error("transform role is called like a splitter")
+	}
+	if !strings.Contains(script, "result = split_date_parts(current_data)") {
+		t.Error("transform role is not called on the current data")
+	}
+	if !strings.Contains(script, "result = rare_categories(current_data)\n") || !strings.Contains(script, "y = y.loc[current_data.index]") {
+		t.Error("row filter role does not re-align the target")
+	}
+}
+
+func TestInvalidRoleIsNodeError(t *testing.T) {
+	workflow := WorkflowConfig{Version: "1.0", Nodes: []Node{
+		{ID: "n1", Name: "Scale", Stage: 2, Role: "scaler", Code: "scale_features"},
+	}}
+
+	_, err := GenerateExecutableScript(workflow, "", GenerateOptions{})
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.NodeID != "n1" || nodeErr.Field != "role" {
+		t.Fatalf("err = %v, want a NodeError for n1.role", err)
+	}
+}
+
+func TestLoaderAndSinkRoles(t *testing.T) {
+	script, err := GenerateExecutableScript(SampleWorkflow(), "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "pd.read_csv(data_file)") {
+		t.Error("setup still reads the data when a loader is present")
+	}
+	if !strings.Contains(script, "df = load_csv(data_file)") {
+		t.Error("loader is not called with the data file")
+	}
+	if !strings.Contains(script, "save_features(current_data, path='features.parquet')") {
+		t.Error("sink is not called with the current data")
+	}
+
+	code, err := GenerateSklearnPipeline(SampleWorkflow(), "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "df = load_csv(data_file, **{})") {
+		t.Error("sklearn pipeline does not use the loader")
+	}
+	if !strings.Contains(string(code), "save_features(pipeline[:-1].transform(X_eval), **{'path': 'features.parquet'})") {
+		t.Error("sklearn pipeline does not call the sink")
+	}
+}
@@ -0,0 +1,554 @@
+// src/utils/scriptTemplate.util.go
+package utils
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"text/template/parse"
+	"time"
+)
+
+// DefaultTemplateName names the built-in script template
+const DefaultTemplateName = "default"
+
+// MaxTemplateSize caps the source of a custom template
+const MaxTemplateSize = 64 << 10
+
+// MaxTemplateOutput caps the code one block of a template renders, so a
+// custom template cannot loop the server out of memory
+const MaxTemplateOutput = 1 << 20
+
+// templateTimeout bounds the time one block of a template renders in
+var templateTimeout = 5 * time.Second
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// templateFiles lists the embedded template files in the order their
+// source is shown
+var templateFiles = []string{"templates/script.tmpl", "templates/pipeline.tmpl", "templates/components.tmpl"}
+
+// ScriptBlocks lists the blocks a custom template may redefine
+var ScriptBlocks = []string{
+	"script", "header", "imports", "stage", "setup", "component",
+	"load", "transform", "split", "train", "cross_validate", "evaluate",
+	"sink", "validation", "save_output", "return", "main",
+}
+
+// Kinds of pipeline steps, picked by the "component" block
+const (
+	stepLoad          = "load"
+	stepTransform     = "transform"
+	stepSplit         = "split"
+	stepTrain         = "train"
+	stepCrossValidate = "cross_validate"
+	stepEvaluate      = "evaluate"
+	stepSink          = "sink"
+)
+
+// roleSteps maps component roles to the block that runs them
+var roleSteps = map[string]string{
+	RoleLoader:         stepLoad,
+	RoleTransform:      stepTransform,
+	RoleRowFilter:      stepTransform,
+	RoleSplitter:       stepSplit,
+	RoleTrainer:        stepTrain,
+	RoleEvaluator:      stepEvaluate,
+	RoleCrossValidator: stepCrossValidate,
+	RoleSink:           stepSink,
+}
+
+// ScriptTemplate renders the executable script and the pipeline code the
+// notebook, Airflow and Prefect exports share with it
+type ScriptTemplate struct {
+	Name string
+	// Blocks lists the blocks the template redefines, none for the default
+	Blocks []string
+	tmpl   *template.Template
+}
+
+// TemplateError reports a template that failed to render
+type TemplateError struct {
+	Template string
+	Err      error
+}
+
+func (e *TemplateError) Error() string {
+	return fmt.Sprintf("template %q: %v", e.Template, e.Err)
+}
+
+func (e *TemplateError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	defaultTemplateOnce sync.Once
+	defaultTemplate     *ScriptTemplate
+)
+
+// DefaultScriptTemplate returns the built-in template embedded from
+// templates/*.tmpl
+func DefaultScriptTemplate() *ScriptTemplate {
+	defaultTemplateOnce.Do(func() {
+		t := template.Must(newTemplate(DefaultTemplateName).ParseFS(templateFS, templateFiles...))
+		defaultTemplate = &ScriptTemplate{Name: DefaultTemplateName, tmpl: withInclude(t)}
+	})
+	return defaultTemplate
+}
+
+// DefaultTemplateSource returns the source of the built-in template, a
+// starting point for custom templates
+func DefaultTemplateSource() string {
+	var sources []string
+	for _, name := range templateFiles {
+		data, err := templateFS.ReadFile(name)
+		if err != nil {
+			panic(err)
+		}
+		sources = append(sources, string(data))
+	}
+	return strings.Join(sources, "\n")
+}
+
+// ParseScriptTemplate parses a custom template. The source may only hold
+// {{define}} blocks named in ScriptBlocks; every other block comes from the
+// default template. The template is rendered against a sample workflow so
+// execution errors are reported before it is used.
+func ParseScriptTemplate(name, source string) (*ScriptTemplate, error) {
+	if len(source) > MaxTemplateSize {
+		return nil, fmt.Errorf("template is larger than %d bytes", MaxTemplateSize)
+	}
+
+	// The root is named so it cannot clash with a block
+	root := name + ".tmpl"
+	overrides, err := newTemplate(root).Parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	var blocks []string
+	for _, t := range overrides.Templates() {
+		if t.Name() == root {
+			if t.Tree != nil && !parse.IsEmptyTree(t.Tree.Root) {
+				return nil, fmt.Errorf("text outside {{define}} blocks is not allowed")
+			}
+			continue
+		}
+		if !containsString(ScriptBlocks, t.Name()) {
+			return nil, fmt.Errorf("unknown block %q, must be one of: %s", t.Name(), strings.Join(ScriptBlocks, ", "))
+		}
+		blocks = append(blocks, t.Name())
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("template does not define any block")
+	}
+	sort.Strings(blocks)
+
+	t, err := DefaultScriptTemplate().tmpl.Clone()
+	if err != nil {
+		return nil, err
+	}
+	for _, block := range blocks {
+		if _, err := t.AddParseTree(block, overrides.Lookup(block).Tree); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkTemplateLoops(t); err != nil {
+		return nil, err
+	}
+
+	custom := &ScriptTemplate{Name: name, Blocks: blocks, tmpl: withInclude(t)}
+	if _, err := GenerateExecutableScript(SampleWorkflow(), "", GenerateOptions{Template: custom}); err != nil {
+		return nil, err
+	}
+	return custom, nil
+}
+
+// maxRangeDepth caps how deeply ranges nest, counting the ranges of the
+// blocks called inside a range. The default script ranges over stages and
+// their steps, which leaves one level to the blocks it includes.
+const maxRangeDepth = 3
+
+// checkTemplateLoops rejects templates whose loops are not bounded by the
+// workflow: a range over anything but a field of the data, such as
+// {{range 100000000000}}, ranges nested too deeply and blocks calling
+// themselves. Execution cannot be stopped once started, so such loops are
+// refused before the template is rendered.
+func checkTemplateLoops(t *template.Template) error {
+	depths := map[string]int{}
+	visiting := map[string]bool{}
+	var blockDepth func(name string) (int, error)
+	blockDepth = func(name string) (int, error) {
+		if depth, ok := depths[name]; ok {
+			return depth, nil
+		}
+		if visiting[name] {
+			return 0, fmt.Errorf("block %q calls itself", name)
+		}
+		block := t.Lookup(name)
+		if block == nil || block.Tree == nil {
+			// Execution reports the missing block
+			return 0, nil
+		}
+		visiting[name] = true
+		depth, err := rangeDepth(block.Tree.Root, blockDepth)
+		visiting[name] = false
+		if err != nil {
+			return 0, fmt.Errorf("block %q: %w", name, err)
+		}
+		depths[name] = depth
+		return depth, nil
+	}
+
+	var names []string
+	for _, block := range t.Templates() {
+		names = append(names, block.Name())
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		depth, err := blockDepth(name)
+		if err != nil {
+			return err
+		}
+		if depth > maxRangeDepth {
+			return fmt.Errorf("block %q nests ranges %d deep, at most %d are allowed", name, depth, maxRangeDepth)
+		}
+	}
+	return nil
+}
+
+// rangeDepth returns how deeply ranges nest under node, with blockDepth
+// giving the depth of the blocks it calls
+func rangeDepth(node parse.Node, blockDepth func(name string) (int, error)) (int, error) {
+	depth := 0
+	deepest := func(nodes ...parse.Node) error {
+		for _, n := range nodes {
+			d, err := rangeDepth(n, blockDepth)
+			if err != nil {
+				return err
+			}
+			depth = max(depth, d)
+		}
+		return nil
+	}
+
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return 0, nil
+		}
+		return depth, deepest(n.Nodes...)
+	case *parse.ActionNode:
+		return depth, deepest(n.Pipe)
+	case *parse.IfNode:
+		return depth, deepest(n.Pipe, n.List, n.ElseList)
+	case *parse.WithNode:
+		return depth, deepest(n.Pipe, n.List, n.ElseList)
+	case *parse.RangeNode:
+		if !isDataField(n.Pipe) {
+			return 0, fmt.Errorf("range over %s is not allowed, ranges must go over a field of the data such as .Steps", n.Pipe)
+		}
+		if err := deepest(n.List); err != nil {
+			return 0, err
+		}
+		depth++
+		return depth, deepest(n.ElseList)
+	case *parse.TemplateNode:
+		d, err := blockDepth(n.Name)
+		if err != nil {
+			return 0, err
+		}
+		depth = d
+		return depth, deepest(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return 0, nil
+		}
+		for _, cmd := range n.Cmds {
+			if len(cmd.Args) > 0 {
+				if ident, ok := cmd.Args[0].(*parse.IdentifierNode); ok && ident.Ident == "include" {
+					var name *parse.StringNode
+					if len(cmd.Args) > 1 {
+						name, _ = cmd.Args[1].(*parse.StringNode)
+					}
+					if name == nil {
+						return 0, fmt.Errorf("include takes the name of a block in quotes")
+					}
+					d, err := blockDepth(name.Text)
+					if err != nil {
+						return 0, err
+					}
+					depth = max(depth, d)
+				}
+			}
+			if err := deepest(cmd.Args...); err != nil {
+				return 0, err
+			}
+		}
+		return depth, nil
+	case *parse.ChainNode:
+		return depth, deepest(n.Node)
+	}
+	return 0, nil
+}
+
+// isDataField reports whether a range pipeline is a field of the data, such
+// as .Steps or $.Stages, whose length the workflow bounds
+func isDataField(pipe *parse.PipeNode) bool {
+	if len(pipe.Cmds) != 1 || len(pipe.Cmds[0].Args) != 1 {
+		return false
+	}
+	switch arg := pipe.Cmds[0].Args[0].(type) {
+	case *parse.FieldNode:
+		return true
+	case *parse.VariableNode:
+		return len(arg.Ident) > 1
+	}
+	return false
+}
+
+// SampleWorkflow returns a workflow with one node of every role, used
+// to check and preview templates
+func SampleWorkflow() WorkflowConfig {
+	return sampleWorkflow
+}
+
+var sampleWorkflow = WorkflowConfig{
+	Version: "1.0",
+	Nodes: []Node{
+		{ID: "sample_1", Name: "Load CSV", Stage: 1, Role: RoleLoader, Code: "load_csv"},
+		{ID: "sample_2", Name: "Remove Outliers", Stage: 1, Role: RoleRowFilter, Code: "remove_outliers"},
+		{ID: "sample_3", Name: "Train Test Split", Stage: 1, Role: RoleSplitter, Code: "train_test_split_data", Variables: map[string]interface{}{"test_size": 0.2}},
+		{ID: "sample_4", Name: "Scale Features", Stage: 2, Role: RoleTransform, Code: "scale_features"},
+		{ID: "sample_5", Name: "Random Forest", Stage: 3, Role: RoleTrainer, Code: "train_random_forest", Variables: map[string]interface{}{"n_estimators": float64(100)}},
+		{ID: "sample_6", Name: "Cross Validation", Stage: 4, Role: RoleCrossValidator, Code: "cross_validate_model"},
+		{ID: "sample_7", Name: "Classification Metrics", Stage: 4, Role: RoleEvaluator, Code: "classification_metrics"},
+		{ID: "sample_8", Name: "Save Features", Stage: 4, Role: RoleSink, Code: "save_features", Variables: map[string]interface{}{"path": "features.parquet"}},
+	},
+}
+
+// render executes one block of the template into a capped writer. A block
+// still running at the deadline is abandoned; its next write fails, which
+// stops it. Loops that could run without writing are refused by
+// checkTemplateLoops before a template is used.
+func (t *ScriptTemplate) render(name string, data interface{}) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), templateTimeout)
+	defer cancel()
+
+	w := &limitedWriter{ctx: ctx}
+	done := make(chan error, 1)
+	go func() {
+		done <- t.tmpl.ExecuteTemplate(w, name, data)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return "", &TemplateError{Template: t.Name, Err: templateLimitError(err)}
+		}
+		return w.sb.String(), nil
+	case <-ctx.Done():
+		return "", &TemplateError{Template: t.Name, Err: errTemplateTimeout}
+	}
+}
+
+var (
+	errTemplateOutput  = fmt.Errorf("output is larger than %d bytes", MaxTemplateOutput)
+	errTemplateTimeout = fmt.Errorf("rendering takes longer than %s", templateTimeout)
+)
+
+// limitedWriter collects template output; writes past MaxTemplateOutput or
+// after the context is done fail, which stops the execution
+type limitedWriter struct {
+	ctx context.Context
+	sb  strings.Builder
+}
+
+func (w *limitedWriter) Write(p []byte) (int, error) {
+	if w.ctx.Err() != nil {
+		return 0, errTemplateTimeout
+	}
+	if w.sb.Len()+len(p) > MaxTemplateOutput {
+		return 0, errTemplateOutput
+	}
+	return w.sb.Write(p)
+}
+
+// templateLimitError returns the limit a failed execution ran into, which
+// text/template wraps in its own message, or err
+func templateLimitError(err error) error {
+	for _, limit := range []error{errTemplateOutput, errTemplateTimeout} {
+		if errors.Is(err, limit) {
+			return limit
+		}
+	}
+	return err
+}
+
+// newTemplate returns an empty template with the functions blocks may use
+func newTemplate(name string) *template.Template {
+	return template.New(name).Option("missingkey=error").Funcs(template.FuncMap{
+		"include": func(string, interface{}) (string, error) {
+			return "", fmt.Errorf("include is not bound")
+		},
+		"indent":    indentLines,
+		"join":      strings.Join,
+		"quote":     pyQuote,
+		"literal":   PythonLiteral,
+		"fstring":   fstringText,
+		"comment":   commentText,
+		"docstring": docstringText,
+	})
+}
+
+// withInclude binds include to t, so it renders t's own blocks, and
+// returns t
+func withInclude(t *template.Template) *template.Template {
+	return t.Funcs(template.FuncMap{
+		"include": func(name string, data interface{}) (string, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), templateTimeout)
+			defer cancel()
+			w := &limitedWriter{ctx: ctx}
+			err := t.ExecuteTemplate(w, name, data)
+			return w.sb.String(), err
+		},
+	})
+}
+
+// indentLines indents every non-blank line of text by n spaces and empties
+// blank lines
+func indentLines(n int, text string) string {
+	pad := strings.Repeat(" ", n)
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			lines[i] = ""
+		} else {
+			lines[i] = pad + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// scriptData is what the "script" template and the pipeline blocks receive
+type scriptData struct {
+	GeneratedAt     string
+	Version         string
+	TotalComponents int
+	WorkflowHash    string
+	Requirements    []string
+	Imports         string
+	Seed            string
+	// DataIO defines read_data and write_data for the data file formats
+	DataIO        string
+	ComponentCode string
+	// TargetColumn is the target column default as a Python literal and
+	// TargetArgument the argparse call declaring --target
+	TargetColumn   string
+	TargetArgument string
+	scriptTask
+	// HasLoader is set when a loader component reads the data instead of
+	// the setup block
+	HasLoader bool
+	Stages    []scriptStage
+}
+
+// scriptStage is a stage with at least one step
+type scriptStage struct {
+	Number int
+	Steps  []scriptStep
+}
+
+// scriptStep is what the "component" block and the step blocks receive
+type scriptStep struct {
+	Index      int
+	Total      int
+	Stage      int
+	Name       string
+	Function   string
+	Args       string
+	Role       string
+	Kind       string
+	SyncTarget bool
+	Node       Node
+	scriptTask
+}
+
+// newScriptData hashes and seeds the workflow and lays out its steps
+func newScriptData(workflow WorkflowConfig, componentCode string, opts GenerateOptions) (*scriptData, error) {
+	task, err := newScriptTask(workflow.TaskType)
+	if err != nil {
+		return nil, err
+	}
+	hash := WorkflowHash(workflow, componentCode)
+	workflow = seedWorkflow(workflow, componentCode, opts)
+
+	data := &scriptData{
+		GeneratedAt:     opts.timestamp(),
+		Version:         workflow.Version,
+		TotalComponents: len(workflow.Nodes),
+		WorkflowHash:    hash,
+		Requirements:    WorkflowRequirements(workflow, componentCode),
+		Imports:         pythonImports,
+		Seed:            seedCode(opts),
+		DataIO:          dataIOCode,
+		ComponentCode:   componentCode,
+		TargetColumn:    targetDefault(workflow),
+		TargetArgument:  targetArgument(workflow),
+		scriptTask:      task,
+	}
+
+	groups, err := organizeByStage(workflow.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		stage := scriptStage{Number: group.Number}
+		for i, node := range group.Nodes {
+			step, err := newScriptStep(node, i+1, len(group.Nodes), group.Number, task)
+			if err != nil {
+				return nil, err
+			}
+			stage.Steps = append(stage.Steps, step)
+			data.HasLoader = data.HasLoader || step.Role == RoleLoader
+		}
+		data.Stages = append(data.Stages, stage)
+	}
+	return data, nil
+}
+
+// newScriptStep decides how a node runs from its role and the task type
+func newScriptStep(node Node, index, total, stage int, task scriptTask) (scriptStep, error) {
+	name, funcName, err := nodeFunction(node)
+	if err != nil {
+		return scriptStep{}, err
+	}
+	role, err := nodeRole(node, funcName)
+	if err != nil {
+		return scriptStep{}, err
+	}
+
+	step := scriptStep{
+		Index: index, Total: total, Stage: stage,
+		Name: name, Function: funcName, Node: node,
+		Role: role, Kind: roleSteps[role], SyncTarget: role == RoleRowFilter,
+		scriptTask: task,
+	}
+
+	// Splitters receive target_column positionally
+	var skip []string
+	if role == RoleSplitter {
+		skip = []string{"target_column"}
+	}
+
+	if step.Args, err = buildVariablesString(node, skip...); err != nil {
+		return scriptStep{}, err
+	}
+	return step, nil
+}
@@ -0,0 +1,174 @@
+package utils
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+)
+
+const parquetOutput = `{{define "save_output" -}}
+print(f"\n[SAVING OUTPUT]")
+current_data.to_parquet(output_file.replace('.csv', '.parquet'))
+{{end}}`
+
+func TestCustomTemplateOverridesBlocks(t *testing.T) {
+	custom, err := ParseScriptTemplate("parquet", parquetOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"save_output"}; !reflect.DeepEqual(custom.Blocks, want) {
+		t.Errorf("Blocks = %v, want %v", custom.Blocks, want)
+	}
+
+	code, err := os.ReadFile(filepath.Join("testdata", "components.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	workflow := loadWorkflow(t, "workflow.json")
+
+	script, err := GenerateExecutableScript(workflow, string(code), GenerateOptions{Template: custom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(script, "    current_data.to_parquet(") {
+		t.Error("script does not use the custom save_output block")
+	}
+	if strings.Contains(script, "Processed features saved to") {
+		t.Error("script still contains the default save_output block")
+	}
+	if !strings.Contains(script, "PIPELINE EXECUTION") {
+		t.Error("script lost the default setup block")
+	}
+
+	dag, err := GenerateAirflowDAG(workflow, string(code), GenerateOptions{Template: custom})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dag), "    current_data.to_parquet(") {
+		t.Error("Airflow DAG does not use the custom save_output block")
+	}
+
+	// The default template is not affected by the override
+	script, err = GenerateExecutableScript(workflow, string(code), GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "current_data.to_parquet(") {
+		t.Error("custom block leaked into the default template")
+	}
+}
+
+func TestDefaultTemplateSourceRoundTrips(t *testing.T) {
+	copied, err := ParseScriptTemplate("copy", DefaultTemplateSource())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(copied.Blocks, sortedBlocks()) {
+		t.Errorf("Blocks = %v, want every block", copied.Blocks)
+	}
+
+	opts := goldenOptions()
+	want, err := GenerateExecutableScript(sampleWorkflow, "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Template = copied
+	got, err := GenerateExecutableScript(sampleWorkflow, "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Error("the default source renders differently from the built-in template")
+	}
+}
+
+func TestParseScriptTemplateRejectsInvalidTemplates(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"syntax", `{{define "header"}}{{if}}{{end}}`, "missing value for if"},
+		{"unknown block", `{{define "footer"}}x{{end}}`, `unknown block "footer"`},
+		{"top-level text", "print('x')\n" + parquetOutput, "outside {{define}}"},
+		{"no blocks", "  \n", "does not define any block"},
+		{"missing field", `{{define "stage"}}{{.Colour}}{{end}}`, "can't evaluate field Colour"},
+		{"too large", `{{define "main"}}` + strings.Repeat("#", MaxTemplateSize) + `{{end}}`, "larger than"},
+		{"output too large", `{{define "stage"}}` + strings.Repeat(`{{template "header" .}}`, 20) + `{{end}}{{define "header"}}` + strings.Repeat("#", 60000) + `{{end}}`, "output is larger than"},
+		{"included output too large", `{{define "stage"}}` + strings.Repeat(`{{include "header" .}}`, 20) + `{{end}}{{define "header"}}` + strings.Repeat("#", 60000) + `{{end}}`, "output is larger than"},
+		{"range over a number", `{{define "header"}}{{range 100000000000}}{{end}}{{end}}`, "range over 100000000000 is not allowed"},
+		{"range over a variable", `{{define "header"}}{{$n := 100000000000}}{{range $n}}{{end}}{{end}}`, "range over $n is not allowed"},
+		{"range over dot", `{{define "header"}}{{template "main" 100000000000}}{{end}}{{define "main"}}{{range .}}{{end}}{{end}}`, "range over . is not allowed"},
+		{"ranges nested too deeply", `{{define "header"}}{{range $.Steps}}{{range $.Steps}}{{range $.Steps}}{{range $.Steps}}{{end}}{{end}}{{end}}{{end}}{{end}}`, `block "header" nests ranges 4 deep`},
+		{"ranges nested through a block", `{{define "component"}}{{range $.Params}}{{range $.Params}}{{end}}{{end}}{{end}}`, `block "script" nests ranges 4 deep`},
+		{"block calling itself", `{{define "header"}}{{include "header" .}}{{end}}`, `block "header" calls itself`},
+		{"include by expression", `{{define "header"}}{{include (print "head" "er") .}}{{end}}`, "include takes the name of a block in quotes"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseScriptTemplate("custom", tt.source)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("err = %v, want one containing %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateErrorsNameTheTemplate(t *testing.T) {
+	_, err := ParseScriptTemplate("broken", `{{define "evaluate"}}{{template "missing" .}}{{end}}`)
+	var templateErr *TemplateError
+	if !errors.As(err, &templateErr) || templateErr.Template != "broken" {
+		t.Fatalf("err = %v, want a TemplateError for broken", err)
+	}
+}
+
+func TestTemplateRenderingTimesOut(t *testing.T) {
+	defer func(timeout time.Duration) { templateTimeout = timeout }(templateTimeout)
+	templateTimeout = 10 * time.Millisecond
+
+	// ParseScriptTemplate refuses such a loop; a block still writing at the
+	// deadline is stopped by its next write
+	slow := &ScriptTemplate{Name: "slow", tmpl: template.Must(newTemplate("slow").Parse(`{{range 2000000000}}x{{end}}`))}
+	before := runtime.NumGoroutine()
+	_, err := slow.render("slow", nil)
+	if !errors.Is(err, errTemplateTimeout) {
+		t.Fatalf("err = %v, want %v", err, errTemplateTimeout)
+	}
+	waitForGoroutines(t, before)
+}
+
+func TestUnboundedLoopIsRejectedBeforeRendering(t *testing.T) {
+	before := runtime.NumGoroutine()
+	_, err := ParseScriptTemplate("spin", `{{define "header"}}{{range 100000000000}}{{end}}{{end}}`)
+	if err == nil || !strings.Contains(err.Error(), "is not allowed") {
+		t.Fatalf("err = %v, want the range rejected", err)
+	}
+	waitForGoroutines(t, before)
+}
+
+// waitForGoroutines fails the test unless the goroutines started since
+// there were n have exited within a second
+func waitForGoroutines(t *testing.T, n int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > n {
+		if time.Now().After(deadline) {
+			t.Fatalf("%d goroutines still running, want %d", runtime.NumGoroutine(), n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func sortedBlocks() []string {
+	blocks := append([]string(nil), ScriptBlocks...)
+	sort.Strings(blocks)
+	return blocks
+}
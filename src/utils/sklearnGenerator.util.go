@@ -0,0 +1,403 @@
+// src/utils/sklearnGenerator.util.go
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// sklearnWrappers adapts component functions to the scikit-learn API. The
+// wrappers are module-level so a pickled pipeline only references the
+// component functions by name; load it from a module that defines them.
+const sklearnWrappers = `def _first_output(X, fn=None, **kwargs):
+    """Call a transform component and keep only the data of tuple results"""
+    result = fn(X, **kwargs)
+    if isinstance(result, tuple):
+        return result[0]
+    return result
+
+
+class ComponentEstimator(BaseEstimator):
+    """Wraps a trainer component fn(X, y, **kwargs) -> model as an estimator.
+
+    Clustering trainers are called as fn(X, **kwargs). For classification,
+    object targets are label-encoded during fit and decoded on predict, so
+    the fitted pipeline accepts and returns the original labels.
+    """
+
+    def __init__(self, fn=None, kw_args=None, task='classification'):
+        self.fn = fn
+        self.kw_args = kw_args
+        self.task = task
+
+    def fit(self, X, y=None):
+        X = X.values if isinstance(X, pd.DataFrame) else X
+        self.label_encoder_ = None
+        if self.task == 'clustering':
+            self.model_ = self.fn(X, **(self.kw_args or {}))
+            return self
+        if self.task == 'classification' and y is not None and getattr(y, 'dtype', None) == 'object':
+            from sklearn.preprocessing import LabelEncoder
+            self.label_encoder_ = LabelEncoder()
+            y = self.label_encoder_.fit_transform(y)
+        elif hasattr(y, 'values'):
+            y = y.values
+        self.model_ = self.fn(X, y, **(self.kw_args or {}))
+        return self
+
+    def predict(self, X):
+        X = X.values if isinstance(X, pd.DataFrame) else X
+        if not hasattr(self.model_, 'predict'):
+            # Clustering models such as DBSCAN only label the data they fit
+            return self.model_.fit_predict(X)
+        y_pred = self.model_.predict(X)
+        if self.label_encoder_ is not None:
+            y_pred = self.label_encoder_.inverse_transform(y_pred)
+        return y_pred
+
+    def predict_proba(self, X):
+        X = X.values if isinstance(X, pd.DataFrame) else X
+        return self.model_.predict_proba(X)
+
+    @property
+    def classes_(self):
+        if self.label_encoder_ is not None:
+            return self.label_encoder_.classes_
+        return getattr(self.model_, 'classes_', None)
+`
+
+// sklearnStep is one node placed in the generated pipeline
+type sklearnStep struct {
+	name     string
+	compName string
+	funcName string
+	kwargs   string
+}
+
+// sklearn reserves "__" in step names for nested parameters
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9]+`)
+
+// GenerateSklearnPipeline renders the workflow as a script that builds a
+// single sklearn.pipeline.Pipeline: transforms become FunctionTransformer
+// steps and the trainer becomes the final estimator. Loaders read the data,
+// and row filters and splitters change the number of samples, so they run
+// before the pipeline is fitted. Evaluators and cross-validators check the
+// fitted pipeline, which is saved with joblib; sinks receive the
+// transformed evaluation data. The task type decides how the trainer is
+// called and what evaluators receive.
+func GenerateSklearnPipeline(workflow WorkflowConfig, componentCode string, opts GenerateOptions) ([]byte, error) {
+	task, err := newScriptTask(workflow.TaskType)
+	if err != nil {
+		return nil, err
+	}
+	hash := WorkflowHash(workflow, componentCode)
+	workflow = seedWorkflow(workflow, componentCode, opts)
+
+	var loaders, rowFilters, splitters, transforms, trainers, evaluators, validators, sinks []sklearnStep
+	groups, err := organizeByStage(workflow.Nodes)
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		for _, node := range group.Nodes {
+			compName, funcName, err := nodeFunction(node)
+			if err != nil {
+				return nil, err
+			}
+
+			role, err := nodeRole(node, funcName)
+			if err != nil {
+				return nil, err
+			}
+
+			// target_column is passed positionally to splitters
+			var skip []string
+			if role == RoleSplitter {
+				skip = append(skip, "target_column")
+			}
+			kwargs, err := buildKwargsDict(node, skip...)
+			if err != nil {
+				return nil, err
+			}
+
+			step := sklearnStep{
+				name:     fmt.Sprintf("%d_%s", len(transforms)+len(trainers)+1, strings.Trim(nonIdentifier.ReplaceAllString(funcName, "_"), "_")),
+				compName: compName,
+				funcName: funcName,
+				kwargs:   kwargs,
+			}
+
+			switch role {
+			case RoleLoader:
+				loaders = append(loaders, step)
+			case RoleSplitter:
+				splitters = append(splitters, step)
+			case RoleRowFilter:
+				rowFilters = append(rowFilters, step)
+			case RoleTrainer:
+				trainers = append(trainers, step)
+			case RoleCrossValidator:
+				validators = append(validators, step)
+			case RoleEvaluator:
+				evaluators = append(evaluators, step)
+			case RoleSink:
+				sinks = append(sinks, step)
+			default:
+				transforms = append(transforms, step)
+			}
+		}
+	}
+
+	if len(trainers) == 0 {
+		return nil, fmt.Errorf("sklearn export needs a trainer component")
+	}
+	trainer := trainers[len(trainers)-1]
+
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf(`#!/usr/bin/env python3
+"""
+Auto-generated scikit-learn Pipeline
+Generated at: %s
+Version: %s
+Total Components: %d
+Workflow Hash: %s
+Task: %s
+
+Fits every transform and the trainer as one sklearn Pipeline and saves it
+with joblib. Load the saved model from a module that defines the component
+functions below, e.g. by importing this script.
+"""
+
+`, opts.timestamp(), docstringText(workflow.Version), len(workflow.Nodes), hash, task.TaskType))
+	sb.WriteString(pythonImports)
+	sb.WriteString(seedCode(opts))
+	sb.WriteString(dataIOCode)
+	sb.WriteString(`import joblib
+from sklearn.base import BaseEstimator, clone
+from sklearn.pipeline import Pipeline
+from sklearn.preprocessing import FunctionTransformer
+
+# ============================================================
+# COMPONENT FUNCTIONS
+# ============================================================
+
+`)
+	sb.WriteString(componentCode)
+	sb.WriteString(`
+
+# ============================================================
+# SKLEARN WRAPPERS
+# ============================================================
+
+`)
+	sb.WriteString(sklearnWrappers)
+
+	// Pipeline definition
+	sb.WriteString(`
+
+# ============================================================
+# PIPELINE DEFINITION
+# ============================================================
+
+def build_pipeline():
+    """Return the unfitted pipeline"""
+    return Pipeline([
+`)
+	for _, step := range transforms {
+		sb.WriteString(fmt.Sprintf("        # %s\n", step.compName))
+		sb.WriteString(fmt.Sprintf("        ('%s', FunctionTransformer(_first_output, validate=False, kw_args={'fn': %s, **%s})),\n",
+			step.name, step.funcName, step.kwargs))
+	}
+	sb.WriteString(fmt.Sprintf("        # %s\n", trainer.compName))
+	sb.WriteString(fmt.Sprintf("        ('%s', ComponentEstimator(fn=%s, kw_args=%s, task=%s)),\n", trainer.name, trainer.funcName, trainer.kwargs, pyQuote(task.TaskType)))
+	sb.WriteString("    ])\n")
+
+	if len(trainers) > 1 {
+		skipped := make([]string, 0, len(trainers)-1)
+		for _, step := range trainers[:len(trainers)-1] {
+			skipped = append(skipped, step.compName)
+		}
+		sb.WriteString(fmt.Sprintf("\n# Only the last trainer is used as the final estimator; skipped: %s\n", strings.Join(skipped, ", ")))
+	}
+
+	// Fitting
+	sb.WriteString(`
+
+# ============================================================
+# TRAINING AND EVALUATION
+# ============================================================
+
+def fit_pipeline(data_file, target_column=` + targetDefault(workflow) + `, model_output='model.joblib', evaluate=True):
+    """Fit the pipeline on the data, evaluate it and save it"""
+
+    print("="*60)
+    print("SKLEARN PIPELINE")
+    print("="*60)
+
+`)
+
+	if len(loaders) > 0 {
+		step := loaders[0]
+		sb.WriteString(fmt.Sprintf(`    # Loader: %s
+    df = %s(data_file, **%s)
+    df = df[0] if isinstance(df, tuple) else df
+`, step.compName, step.funcName, step.kwargs))
+	} else {
+		sb.WriteString("    df = read_data(data_file)\n")
+	}
+	sb.WriteString("    print(f\"✓ Loaded {len(df)} samples\")\n\n")
+	if task.Unsupervised {
+		sb.WriteString(`    # Clustering does not need a target; drop it from the features if present
+    targets = [name.strip() for name in target_column.split(',') if name.strip() in df.columns]
+
+`)
+	} else {
+		sb.WriteString(`    # Several comma-separated target columns make a multi-output target
+    targets = [name.strip() for name in target_column.split(',')]
+    for name in targets:
+        if name not in df.columns:
+            raise KeyError(name)
+    target = targets if len(targets) > 1 else targets[0]
+
+`)
+	}
+
+	for _, step := range rowFilters {
+		sb.WriteString(fmt.Sprintf(`    # Row filter: %s (runs before the pipeline because it removes samples)
+    result = %s(df.drop(columns=targets), **%s)
+    filtered = result[0] if isinstance(result, tuple) else result
+    df = df.loc[filtered.index]
+    print(f"  ✓ %s: {len(df)} samples remaining")
+
+`, step.compName, step.funcName, step.kwargs, fstringText(step.compName)))
+	}
+
+	switch {
+	case task.Unsupervised:
+		// Splitters need a target, so clustering fits on all the data
+		sb.WriteString(`    X_train, y_train = df.drop(columns=targets), None
+    X_test, y_test = None, None
+`)
+		if len(splitters) > 0 {
+			sb.WriteString(fmt.Sprintf("    print(f\"  ⚠ Clustering has no target, skipped split: %s\")\n", fstringText(splitters[0].compName)))
+		}
+		sb.WriteString("\n")
+	case len(splitters) > 0:
+		step := splitters[0]
+		sb.WriteString(fmt.Sprintf(`    # Split: %s
+    X_train, X_test, y_train, y_test = %s(df, target, **%s)
+    print(f"  ✓ Split into train ({len(X_train)}) and test ({len(X_test)}) sets")
+
+`, step.compName, step.funcName, step.kwargs))
+	case task.Chronological:
+		sb.WriteString(fmt.Sprintf(`    # No split component: hold out the latest samples in time order
+    cut = len(df) - max(1, int(len(df) * %s))
+    X_train, y_train = df.drop(columns=targets)[:cut], df[target][:cut]
+    X_test, y_test = df.drop(columns=targets)[cut:], df[target][cut:]
+    print(f"  ℹ Held out the latest {len(X_test)} samples for evaluation")
+
+`, formatFloat(task.Holdout)))
+	default:
+		sb.WriteString(`    X_train, y_train = df.drop(columns=targets), df[target]
+    X_test, y_test = None, None
+    print(f"  ⚠ No split component, fitting and evaluating on all data")
+
+`)
+	}
+
+	sb.WriteString(`    pipeline = build_pipeline()
+    pipeline.fit(X_train, y_train)
+    print(f"  ✓ Pipeline fitted: {[name for name, _ in pipeline.steps]}")
+
+    X_eval, y_eval = (X_test, y_test) if X_test is not None else (X_train, y_train)
+
+    if evaluate:
+`)
+
+	if len(validators)+len(evaluators) == 0 {
+		sb.WriteString("        pass\n")
+	}
+	cvArgs := "X_train, y_train"
+	if task.Unsupervised {
+		cvArgs = "X_train"
+	}
+	for _, step := range validators {
+		sb.WriteString(fmt.Sprintf(`        # %s
+        try:
+            cv_results = %s(clone(pipeline), %s, **%s)
+            print(f"\n  Cross-validation: {cv_results}")
+        except Exception as e:
+            print(f"  ⚠ Cross-validation failed: {e}")
+`, step.compName, step.funcName, cvArgs, step.kwargs))
+	}
+	for _, step := range evaluators {
+		sb.WriteString(fmt.Sprintf("        # %s\n        try:\n", step.compName))
+		switch {
+		case task.Unsupervised:
+			sb.WriteString(fmt.Sprintf(`            labels = pipeline.predict(X_eval)
+            metrics = %s(pipeline[:-1].transform(X_eval), labels, **%s)
+`, step.funcName, step.kwargs))
+		case task.EncodeLabels:
+			sb.WriteString(fmt.Sprintf(`            y_pred = pipeline.predict(X_eval)
+            try:
+                y_pred_proba = pipeline.predict_proba(X_eval)
+            except Exception:
+                y_pred_proba = None
+            metrics = %s(y_eval, y_pred, y_pred_proba, **%s)
+`, step.funcName, step.kwargs))
+		default:
+			sb.WriteString(fmt.Sprintf(`            y_pred = pipeline.predict(X_eval)
+            metrics = %s(y_eval, y_pred, **%s)
+`, step.funcName, step.kwargs))
+		}
+		sb.WriteString(`            if isinstance(metrics, dict):
+                print(f"\n  Metrics:")
+                for key, value in metrics.items():
+                    if isinstance(value, (int, float)):
+                        print(f"    {key}: {value:.4f}")
+                    else:
+                        print(f"    {key}: {value}")
+        except Exception as e:
+            print(f"  ⚠ Evaluation failed: {e}")
+`)
+	}
+
+	for _, step := range sinks {
+		sb.WriteString(fmt.Sprintf(`
+    # Sink: %s
+    %s(pipeline[:-1].transform(X_eval), **%s)
+`, step.compName, step.funcName, step.kwargs))
+	}
+
+	sb.WriteString(`
+    joblib.dump(pipeline, model_output)
+    print(f"\n✓ Fitted pipeline saved to: {model_output}")
+    return pipeline
+
+# ============================================================
+# MAIN ENTRY POINT
+# ============================================================
+
+if __name__ == "__main__":
+    parser = argparse.ArgumentParser(description='Fit and save the scikit-learn pipeline')
+    parser.add_argument('--data', required=True, help='Input data file (CSV, TSV, JSON Lines, Parquet or Excel)')
+    ` + targetArgument(workflow) + `
+    parser.add_argument('--model-output', default='model.joblib', help='Where to save the fitted pipeline (default: model.joblib)')
+    parser.add_argument('--skip-eval', action='store_true', help='Skip stage 4 evaluation')
+
+    args = parser.parse_args()
+
+    try:
+        fit_pipeline(args.data, args.target, args.model_output, not args.skip_eval)
+    except FileNotFoundError as e:
+        print(f"\n❌ Error: File not found - {e}")
+        sys.exit(1)
+    except KeyError as e:
+        print(f"\n❌ Error: Column not found - {e}")
+        sys.exit(1)
+`)
+
+	return []byte(sb.String()), nil
+}
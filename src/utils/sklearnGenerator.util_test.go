@@ -0,0 +1,74 @@
+package utils
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerateSklearnPipeline(t *testing.T) {
+	code, err := os.ReadFile(filepath.Join("testdata", "components.py"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := GenerateSklearnPipeline(loadWorkflow(t, "workflow.json"), string(code), goldenOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	script := string(out)
+
+	if diags := checkPythonSyntax(script); len(diags) > 0 {
+		t.Fatalf("generated script has syntax errors: %v", diags)
+	}
+
+	// Transforms become numbered FunctionTransformer steps and the trainer
+	// the final estimator; the row filter and the splitter run before
+	pipeline := script[strings.Index(script, "def build_pipeline():"):]
+	pipeline = pipeline[:strings.Index(pipeline, "    ])\n")]
+	want := []string{
+		"('1_scale_features', FunctionTransformer(_first_output, validate=False, kw_args={'fn': scale_features, **{'method': 'standard'}}))",
+		"('2_train_random_forest', ComponentEstimator(fn=train_random_forest, kw_args={'n_estimators': 200, 'random_state': 42}, task='classification'))",
+	}
+	last := -1
+	for _, step := range want {
+		i := strings.Index(pipeline, step)
+		if i < 0 {
+			t.Fatalf("pipeline has no step %s:\n%s", step, pipeline)
+		}
+		if i < last {
+			t.Errorf("step %s is out of order", step)
+		}
+		last = i
+	}
+	for _, name := range []string{"remove_duplicates", "train_test_split_data", "classification_metrics"} {
+		if strings.Contains(pipeline, name) {
+			t.Errorf("%s must run outside the pipeline", name)
+		}
+		if !strings.Contains(script[strings.Index(script, "def fit_pipeline("):], name+"(") {
+			t.Errorf("fit_pipeline does not call %s", name)
+		}
+	}
+}
+
+func TestGenerateSklearnPipelineErrors(t *testing.T) {
+	code := "def scale_features(df):\n    return df\n"
+	tests := []struct {
+		name     string
+		workflow WorkflowConfig
+		want     string
+	}{
+		{"no trainer", WorkflowConfig{Nodes: []Node{{ID: "n1", Name: "Scale", Stage: 2, Code: "scale_features"}}},
+			"needs a trainer"},
+		{"unknown task", WorkflowConfig{TaskType: "ranking", Nodes: []Node{{ID: "n1", Name: "Scale", Stage: 2, Code: "scale_features"}}},
+			"ranking"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := GenerateSklearnPipeline(tt.workflow, code, GenerateOptions{}); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("GenerateSklearnPipeline() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+// src/utils/stages.util.go
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+)
+
+// Stage is a step of a workflow, defined in models where stages are stored
+type Stage = models.StageDefinition
+
+// How generated code treats the nodes of a stage
+const (
+	StageCodegenRun  = models.StageCodegenRun
+	StageCodegenSkip = models.StageCodegenSkip
+)
+
+// StageCodegens lists the codegen behaviours of stages
+var StageCodegens = models.StageCodegens
+
+// stageNamePattern matches names such as "stage1" or "model-monitoring"
+var stageNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,63}$`)
+
+// IsValidStageName checks a stage name
+func IsValidStageName(name string) bool {
+	return stageNamePattern.MatchString(name)
+}
+
+// DefaultStages are the stages of a new installation
+func DefaultStages() []Stage {
+	return []Stage{
+		{Name: "stage1", Order: 1, Description: "Data loading and cleaning", Codegen: StageCodegenRun,
+			Roles: []string{RoleTransform, RoleLoader, RoleRowFilter, RoleSplitter, RoleSink}},
+		{Name: "stage2", Order: 2, Description: "Feature engineering and splitting", Codegen: StageCodegenRun,
+			Roles: []string{RoleTransform, RoleLoader, RoleRowFilter, RoleSplitter, RoleSink}},
+		{Name: "stage3", Order: 3, Description: "Model training", Codegen: StageCodegenRun,
+			Roles: []string{RoleTrainer}},
+		{Name: "stage4", Order: 4, Description: "Evaluation", Codegen: StageCodegenRun,
+			Roles: []string{RoleEvaluator, RoleCrossValidator, RoleSink}},
+	}
+}
+
+var (
+	stagesMu      sync.RWMutex
+	stageRegistry = DefaultStages()
+)
+
+// Stages returns the registered stages in order
+func Stages() []Stage {
+	stagesMu.RLock()
+	defer stagesMu.RUnlock()
+	return append([]Stage(nil), stageRegistry...)
+}
+
+// SetStages replaces the registered stages; they are read by component
+// validation, role inference and every generator
+func SetStages(stages []Stage) error {
+	if len(stages) == 0 {
+		return fmt.Errorf("at least one stage is required")
+	}
+	sorted := append([]Stage(nil), stages...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Order < sorted[j].Order })
+
+	names := map[string]bool{}
+	for i, stage := range sorted {
+		if err := checkStage(stage); err != nil {
+			return fmt.Errorf("stage %q: %w", stage.Name, err)
+		}
+		if names[stage.Name] {
+			return fmt.Errorf("stage %q is defined twice", stage.Name)
+		}
+		if i > 0 && sorted[i-1].Order == stage.Order {
+			return fmt.Errorf("stages %q and %q have the same order %d", sorted[i-1].Name, stage.Name, stage.Order)
+		}
+		names[stage.Name] = true
+		if stage.Codegen == "" {
+			sorted[i].Codegen = StageCodegenRun
+		}
+	}
+
+	stagesMu.Lock()
+	defer stagesMu.Unlock()
+	stageRegistry = sorted
+	return nil
+}
+
+// ValidateStage checks a stage sent to the API and returns one FieldError
+// per problem
+func ValidateStage(s *models.Stage) []apperror.FieldError {
+	var fields []apperror.FieldError
+
+	if !IsValidStageName(s.Name) {
+		fields = append(fields, apperror.Field("name", "stage_name",
+			"must be 1-64 lower-case letters, digits, '-' or '_' starting with a letter"))
+	}
+	if s.Order < 1 {
+		fields = append(fields, apperror.Field("order", "min", "must be at least 1"))
+	}
+	for i, role := range s.Roles {
+		if !IsValidRole(role) {
+			fields = append(fields, apperror.Field(fmt.Sprintf("roles[%d]", i), "oneof",
+				fmt.Sprintf("invalid role %q, must be one of: %s", role, strings.Join(Roles, ", "))))
+		}
+	}
+	if s.Codegen != "" && !containsString(StageCodegens, s.Codegen) {
+		fields = append(fields, apperror.Field("codegen", "oneof",
+			fmt.Sprintf("invalid codegen %q, must be one of: %s", s.Codegen, strings.Join(StageCodegens, ", "))))
+	}
+
+	return fields
+}
+
+// DefaultStageCodegen runs the nodes of a stage that does not state its
+// codegen
+func DefaultStageCodegen(s *models.Stage) {
+	if s.Codegen == "" {
+		s.Codegen = StageCodegenRun
+	}
+}
+
+// checkStage reports the first problem with a stage definition
+func checkStage(s Stage) error {
+	switch {
+	case !IsValidStageName(s.Name):
+		return fmt.Errorf("invalid name %q, must be 1-64 lower-case letters, digits, '-' or '_' starting with a letter", s.Name)
+	case s.Order < 1:
+		return fmt.Errorf("order must be at least 1")
+	case s.Codegen != "" && !containsString(StageCodegens, s.Codegen):
+		return fmt.Errorf("invalid codegen %q, must be one of: %s", s.Codegen, strings.Join(StageCodegens, ", "))
+	}
+	for _, role := range s.Roles {
+		if !IsValidRole(role) {
+			return fmt.Errorf("invalid role %q, must be one of: %s", role, strings.Join(Roles, ", "))
+		}
+	}
+	return nil
+}
+
+// StageNames returns the names of the registered stages in order
+func StageNames() []string {
+	stages := Stages()
+	names := make([]string, len(stages))
+	for i, stage := range stages {
+		names[i] = stage.Name
+	}
+	return names
+}
+
+// GetStage returns the registered stage with the given name
+func GetStage(name string) (Stage, bool) {
+	for _, stage := range Stages() {
+		if stage.Name == name {
+			return stage, true
+		}
+	}
+	return Stage{}, false
+}
+
+// StageByOrder returns the registered stage of a node's Stage number
+func StageByOrder(order int) (Stage, bool) {
+	for _, stage := range Stages() {
+		if stage.Order == order {
+			return stage, true
+		}
+	}
+	return Stage{}, false
+}
+
+// stageNodes are the nodes of one stage, in workflow order
+type stageNodes struct {
+	Number int
+	Nodes  []Node
+}
+
+// organizeByStage groups the nodes by stage in stage order. Stages without
+// nodes and stages whose codegen skips them are left out; a node of an
+// unregistered stage is rejected with a *NodeError.
+func organizeByStage(nodes []Node) ([]stageNodes, error) {
+	stages := Stages()
+	byOrder := make(map[int][]Node)
+	for _, node := range nodes {
+		if _, ok := StageByOrder(node.Stage); !ok {
+			orders := make([]string, len(stages))
+			for i, stage := range stages {
+				orders[i] = strconv.Itoa(stage.Order)
+			}
+			return nil, &NodeError{NodeID: node.ID, Field: "stage", Message: fmt.Sprintf("is %d, must be one of: %s", node.Stage, strings.Join(orders, ", "))}
+		}
+		byOrder[node.Stage] = append(byOrder[node.Stage], node)
+	}
+
+	var grouped []stageNodes
+	for _, stage := range stages {
+		if len(byOrder[stage.Order]) == 0 || stage.Codegen == StageCodegenSkip {
+			continue
+		}
+		grouped = append(grouped, stageNodes{Number: stage.Order, Nodes: byOrder[stage.Order]})
+	}
+	return grouped, nil
+}
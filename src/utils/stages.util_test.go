@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+// setTestStages registers stages for the test and restores the defaults
+func setTestStages(t *testing.T, stages []Stage) {
+	t.Helper()
+	if err := SetStages(stages); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetStages(DefaultStages()) })
+}
+
+func TestSetStagesRejectsInvalidStages(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []Stage
+		want   string
+	}{
+		{"none", nil, "at least one stage"},
+		{"bad name", []Stage{{Name: "Stage 1", Order: 1}}, "invalid name"},
+		{"bad order", []Stage{{Name: "a", Order: 0}}, "order must be at least 1"},
+		{"bad role", []Stage{{Name: "a", Order: 1, Roles: []string{"scaler"}}}, `invalid role "scaler"`},
+		{"bad codegen", []Stage{{Name: "a", Order: 1, Codegen: "later"}}, `invalid codegen "later"`},
+		{"same name", []Stage{{Name: "a", Order: 1}, {Name: "a", Order: 2}}, "defined twice"},
+		{"same order", []Stage{{Name: "a", Order: 1}, {Name: "b", Order: 1}}, "same order 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetStages(tt.stages); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SetStages() error = %v, want %q", err, tt.want)
+			}
+			if got := StageNames(); strings.Join(got, ",") != "stage1,stage2,stage3,stage4" {
+				t.Errorf("stages changed to %v", got)
+			}
+		})
+	}
+}
+
+func TestCustomStages(t *testing.T) {
+	stages := append(DefaultStages(),
+		Stage{Name: "monitoring", Order: 6, Codegen: StageCodegenSkip},
+		Stage{Name: "deployment", Order: 5, Roles: []string{RoleSink}},
+	)
+	setTestStages(t, stages)
+
+	if got := strings.Join(StageNames(), ","); got != "stage1,stage2,stage3,stage4,deployment,monitoring" {
+		t.Errorf("StageNames() = %s", got)
+	}
+	if stage, ok := GetStage("monitoring"); !ok || stage.Order != 6 {
+		t.Errorf("GetStage(monitoring) = %+v, %v", stage, ok)
+	}
+	if stage, _ := StageByOrder(5); stage.Codegen != StageCodegenRun {
+		t.Errorf("codegen of deployment = %q, want the default %q", stage.Codegen, StageCodegenRun)
+	}
+	if got := InferRole("publish_model", 5); got != RoleSink {
+		t.Errorf("InferRole in deployment = %q, want %q", got, RoleSink)
+	}
+
+	workflow := WorkflowConfig{Version: "1.0", Nodes: []Node{
+		{ID: "n1", Name: "Watch", Stage: 6, Code: "watch_drift"},
+		{ID: "n2", Name: "Publish", Stage: 5, Code: "publish_model"},
+	}}
+	script, err := GenerateExecutableScript(workflow, "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(script, "watch_drift") {
+		t.Error("node of a skipped stage is in the script")
+	}
+	if !strings.Contains(script, "publish_model(") {
+		t.Error("node of a custom stage is not in the script")
+	}
+}
+
+func TestUnknownStageIsNodeError(t *testing.T) {
+	workflow := WorkflowConfig{Version: "1.0", Nodes: []Node{
+		{ID: "n1", Name: "Load", Stage: 1, Code: "load_data"},
+		{ID: "n2", Name: "Scale", Stage: 9, Code: "scale_features"},
+	}}
+	_, err := GenerateExecutableScript(workflow, "", GenerateOptions{})
+	var nodeErr *NodeError
+	if !errors.As(err, &nodeErr) || nodeErr.NodeID != "n2" || nodeErr.Field != "stage" {
+		t.Fatalf("err = %v, want a NodeError for n2.stage", err)
+	}
+	if want := "is 9, must be one of: 1, 2, 3, 4"; nodeErr.Message != want {
+		t.Errorf("Message = %q, want %q", nodeErr.Message, want)
+	}
+}
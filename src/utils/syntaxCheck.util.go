@@ -0,0 +1,195 @@
+// src/utils/syntaxCheck.util.go
+package utils
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Diagnostic is a syntax error in component code. Line and Column start at
+// 1; Column is 0 when only the line is known.
+type Diagnostic struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Column == 0 {
+		return fmt.Sprintf("line %d: %s", d.Line, d.Message)
+	}
+	return fmt.Sprintf("line %d, column %d: %s", d.Line, d.Column, d.Message)
+}
+
+// maxDiagnostics caps the diagnostics reported for one piece of code
+const maxDiagnostics = 10
+
+// SyntaxCheck is the result of checking component code
+type SyntaxCheck struct {
+	// Checker is the command that compiled the code, or BuiltinChecker
+	Checker     string       `json:"checker"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// CheckSyntax compiles code of the language without running it. The
+// language's checker command is used when it is installed; otherwise, or
+// when it fails, the in-process check of the language is.
+func CheckSyntax(ctx context.Context, language, code string) (SyntaxCheck, error) {
+	l, ok := GetLanguage(language)
+	if !ok {
+		return SyntaxCheck{}, fmt.Errorf("unknown language %q, must be one of: %s", language, strings.Join(Languages, ", "))
+	}
+
+	if command := l.Runtime().Check; len(command) > 0 {
+		if path, err := exec.LookPath(command[0]); err == nil {
+			if diagnostics, err := runSyntaxCheck(ctx, path, command[1:], code); err == nil {
+				return SyntaxCheck{Checker: command[0], Diagnostics: diagnostics}, nil
+			}
+		}
+	}
+	return SyntaxCheck{Checker: BuiltinChecker, Diagnostics: l.CheckSyntax(code)}, nil
+}
+
+// BuiltinChecker names the in-process syntax check of a language
+const BuiltinChecker = "builtin"
+
+// SyntaxChecker returns the checker CheckSyntax uses for the language: its
+// check command when installed, or BuiltinChecker
+func SyntaxChecker(l Language) string {
+	if command := l.Runtime().Check; len(command) > 0 {
+		if _, err := exec.LookPath(command[0]); err == nil {
+			return command[0]
+		}
+	}
+	return BuiltinChecker
+}
+
+// syntaxCheckTimeout bounds a checker command; CheckSyntax falls back to
+// the in-process check when it runs out
+var syntaxCheckTimeout = 5 * time.Second
+
+// runSyntaxCheck runs a checker command with the code on stdin; it prints
+// the diagnostics as a JSON array
+func runSyntaxCheck(ctx context.Context, path string, args []string, code string) ([]Diagnostic, error) {
+	ctx, cancel := context.WithTimeout(ctx, syntaxCheckTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, path, args...)
+	cmd.Stdin = strings.NewReader(code)
+	// Processes the checker started may hold its output open once it is
+	// killed
+	cmd.WaitDelay = time.Second
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	var diagnostics []Diagnostic
+	if err := json.Unmarshal(stdout.Bytes(), &diagnostics); err != nil {
+		return nil, fmt.Errorf("%s: unreadable diagnostics: %w", path, err)
+	}
+	if len(diagnostics) > maxDiagnostics {
+		diagnostics = diagnostics[:maxDiagnostics]
+	}
+	return diagnostics, nil
+}
+
+// pythonCheckProgram compiles the code on stdin as py_compile does
+const pythonCheckProgram = `import json, sys
+try:
+    compile(sys.stdin.read(), 'component.py', 'exec')
+except SyntaxError as e:
+    print(json.dumps([{'line': e.lineno or 1, 'column': e.offset or 0, 'message': e.msg}]))
+except ValueError as e:
+    print(json.dumps([{'line': 1, 'message': str(e)}]))
+else:
+    print('[]')
+`
+
+// javascriptCheckProgram compiles the code on stdin as node --check does;
+// the error's stack holds the line and a caret under the column
+const javascriptCheckProgram = `const vm = require('vm');
+const code = require('fs').readFileSync(0, 'utf8');
+try {
+  new vm.Script(code, { filename: 'component.js' });
+  console.log('[]');
+} catch (e) {
+  const lines = String(e.stack).split('\n');
+  const line = /^component\.js:(\d+)/.exec(lines[0]);
+  const caret = line && lines[2] ? lines[2].indexOf('^') + 1 : 0;
+  console.log(JSON.stringify([{ line: line ? Number(line[1]) : 1, column: caret, message: e.message }]));
+}
+`
+
+// sourcePosition returns the line and column of a byte offset into code
+func sourcePosition(code string, offset int) (int, int) {
+	line := strings.Count(code[:offset], "\n") + 1
+	start := strings.LastIndexByte(code[:offset], '\n') + 1
+	return line, utf8.RuneCountInString(code[start:offset]) + 1
+}
+
+// openBracket is a bracket waiting for its closing bracket
+type openBracket struct {
+	char   byte
+	offset int
+}
+
+// syntaxScanner collects the diagnostics of the in-process checks, which
+// tokenize code and match its brackets
+type syntaxScanner struct {
+	code        string
+	brackets    []openBracket
+	diagnostics []Diagnostic
+}
+
+var closingBrackets = map[byte]byte{'(': ')', '[': ']', '{': '}'}
+
+// open opens the bracket at offset
+func (s *syntaxScanner) open(offset int) {
+	s.brackets = append(s.brackets, openBracket{char: s.code[offset], offset: offset})
+}
+
+// close closes the innermost bracket with the one at offset, reporting a
+// closing bracket without or with a different opening bracket
+func (s *syntaxScanner) close(offset int) {
+	char := s.code[offset]
+	if len(s.brackets) == 0 {
+		s.report(offset, fmt.Sprintf("unmatched '%c'", char))
+		return
+	}
+	top := s.brackets[len(s.brackets)-1]
+	s.brackets = s.brackets[:len(s.brackets)-1]
+	if closingBrackets[top.char] != char {
+		line, _ := sourcePosition(s.code, top.offset)
+		s.report(offset, fmt.Sprintf("closing '%c' does not match opening '%c' on line %d", char, top.char, line))
+	}
+}
+
+// depth returns the number of open brackets
+func (s *syntaxScanner) depth() int {
+	return len(s.brackets)
+}
+
+// unclosed reports the brackets still open at the end of the code
+func (s *syntaxScanner) unclosed() {
+	for _, b := range s.brackets {
+		s.report(b.offset, fmt.Sprintf("'%c' was never closed", b.char))
+	}
+	s.brackets = nil
+}
+
+// report adds a diagnostic at the byte offset
+func (s *syntaxScanner) report(offset int, message string) {
+	if len(s.diagnostics) < maxDiagnostics {
+		line, column := sourcePosition(s.code, offset)
+		s.diagnostics = append(s.diagnostics, Diagnostic{Line: line, Column: column, Message: message})
+	}
+}
@@ -0,0 +1,104 @@
+package utils
+
+import (
+	"context"
+	"os/exec"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuiltinSyntaxChecks(t *testing.T) {
+	tests := []struct {
+		name     string
+		language string
+		code     string
+		want     []Diagnostic
+	}{
+		{"python valid", LanguagePython, "def f(df, cols=('a', 'b')):\n    # comment\n\n    s = '''multi\n  line'''\n    return df[list(cols)] \\\n        .copy()\n", nil},
+		{"python unclosed bracket", LanguagePython, "def f(x:\n    return x\n", []Diagnostic{{1, 6, "'(' was never closed"}}},
+		{"python mismatched bracket", LanguagePython, "x = [1, 2)\n", []Diagnostic{{1, 10, "closing ')' does not match opening '[' on line 1"}}},
+		{"python unterminated string", LanguagePython, "s = 'abc\nt = 1\n", []Diagnostic{{1, 5, "unterminated string literal (detected at line 1)"}}},
+		{"python unterminated docstring", LanguagePython, "def f():\n    \"\"\"doc\n", []Diagnostic{{2, 5, "unterminated triple-quoted string literal (detected at line 2)"}}},
+		{"python missing block", LanguagePython, "def f(x):\nreturn x\n", []Diagnostic{{2, 1, "expected an indented block after line 1"}}},
+		{"python missing block at end", LanguagePython, "def f(x):", []Diagnostic{{1, 10, "expected an indented block after line 1"}}},
+		{"python unexpected indent", LanguagePython, "def f(x):\n    y = 1\n      return x\n", []Diagnostic{{3, 7, "unexpected indent"}}},
+		{"python bad unindent", LanguagePython, "def f(x):\n    if x:\n        y = 1\n  return x\n", []Diagnostic{{4, 3, "unindent does not match any outer indentation level"}}},
+		{"python tabs and spaces", LanguagePython, "class A:\n    def f(self):\n\tpass\n", []Diagnostic{{3, 2, "inconsistent use of tabs and spaces in indentation"}}},
+
+		{"javascript valid", LanguageJavaScript, "function f(rows) {\n  const re = /[/(]+/g; // (\n  return rows.map(r => ({ ...r, a: `x${r.b + `${1}`}y`, d: r.c / 2 }));\n}\n", nil},
+		{"javascript unclosed bracket", LanguageJavaScript, "function f(x {\n  return x;\n}\n", []Diagnostic{{1, 11, "'(' was never closed"}}},
+		{"javascript mismatched bracket", LanguageJavaScript, "if (x) { y(); ]", []Diagnostic{{1, 15, "closing ']' does not match opening '{' on line 1"}}},
+		{"javascript unterminated string", LanguageJavaScript, "const s = 'abc\nconst t = 1;", []Diagnostic{{1, 11, "unterminated string literal"}}},
+		{"javascript unterminated template", LanguageJavaScript, "const t = `abc ${x", []Diagnostic{{1, 11, "unterminated template literal"}}},
+		{"javascript unterminated comment", LanguageJavaScript, "/* open", []Diagnostic{{1, 1, "unterminated comment"}}},
+		{"javascript unterminated regex", LanguageJavaScript, "const re = /abc\n", []Diagnostic{{1, 12, "unterminated regular expression"}}},
+
+		{"go valid without package", LanguageGo, "import \"strings\"\n\nfunc F(t Table) (Table, error) {\n\t_ = strings.ToLower\n\treturn t, nil\n}\n", nil},
+		{"go syntax error", LanguageGo, "// comment\nfunc F() {\n\tx := \n}", []Diagnostic{{4, 1, "expected operand, found '}'"}}},
+		{"go third-party import", LanguageGo, "package main\n\nimport \"github.com/x/y\"\n", []Diagnostic{{3, 8, `imports "github.com/x/y"; only standard library packages are available to Go components`}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			language, _ := GetLanguage(tt.language)
+			if got := language.CheckSyntax(tt.code); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("CheckSyntax() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckSyntaxRunsChecker(t *testing.T) {
+	tests := []struct {
+		language string
+		valid    string
+		code     string
+		want     Diagnostic
+	}{
+		{LanguagePython, "def f(df):\n    return df\n", "def f(:\n    return 1\n", Diagnostic{1, 7, "invalid syntax"}},
+		{LanguageJavaScript, "function f(rows) { return rows }", "function f(x {\n  return x;\n}\n", Diagnostic{1, 14, "Unexpected token '{'"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.language, func(t *testing.T) {
+			language, _ := GetLanguage(tt.language)
+			checker := language.Runtime().Check[0]
+			if _, err := exec.LookPath(checker); err != nil {
+				t.Skipf("%s is not installed", checker)
+			}
+
+			check, err := CheckSyntax(context.Background(), tt.language, tt.code)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if check.Checker != checker || SyntaxChecker(language) != checker {
+				t.Errorf("Checker = %q, want %q", check.Checker, checker)
+			}
+			if want := []Diagnostic{tt.want}; !reflect.DeepEqual(check.Diagnostics, want) {
+				t.Errorf("Diagnostics = %v, want %v", check.Diagnostics, want)
+			}
+
+			if check, err := CheckSyntax(context.Background(), tt.language, tt.valid); err != nil || len(check.Diagnostics) != 0 {
+				t.Errorf("valid code: Diagnostics = %v, error = %v", check.Diagnostics, err)
+			}
+		})
+	}
+}
+
+func TestSyntaxCheckTimesOut(t *testing.T) {
+	sleep, err := exec.LookPath("sleep")
+	if err != nil {
+		t.Skip("sleep is not installed")
+	}
+	defer func(timeout time.Duration) { syntaxCheckTimeout = timeout }(syntaxCheckTimeout)
+	syntaxCheckTimeout = 50 * time.Millisecond
+
+	start := time.Now()
+	if _, err := runSyntaxCheck(context.Background(), sleep, []string{"10"}, ""); err == nil {
+		t.Error("runSyntaxCheck() error = nil, want the checker killed")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("checker ran for %s", elapsed)
+	}
+}
@@ -0,0 +1,64 @@
+// src/utils/taskType.util.go
+package utils
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Task types change how targets are prepared, which data evaluates the
+// model and how trainers and evaluators are called
+const (
+	TaskClassification = "classification" // fn(X, y); object targets are label-encoded
+	TaskRegression     = "regression"     // fn(X, y); targets are used as-is
+	TaskClustering     = "clustering"     // fn(X); evaluators receive fn(X, labels)
+	TaskForecasting    = "forecasting"    // time-ordered regression, never shuffled
+)
+
+// DefaultTaskType is used by workflows without a task type
+const DefaultTaskType = TaskClassification
+
+// forecastHoldout is the share of the latest samples a forecasting
+// pipeline without a split component is evaluated on
+const forecastHoldout = 0.2
+
+// TaskTypes lists the supported task types
+var TaskTypes = []string{TaskClassification, TaskRegression, TaskClustering, TaskForecasting}
+
+// IsValidTaskType checks a workflow task type; empty means the default
+func IsValidTaskType(taskType string) bool {
+	return taskType == "" || containsString(TaskTypes, taskType)
+}
+
+// scriptTask tells the pipeline blocks how the task type changes them
+type scriptTask struct {
+	TaskType string
+	// EncodeLabels is set for classification: object targets are
+	// label-encoded and evaluators also receive the predicted probabilities
+	EncodeLabels bool
+	// Unsupervised is set for clustering: trainers and cross-validators
+	// are called without a target
+	Unsupervised bool
+	// Chronological is set for forecasting: without a split component the
+	// latest samples are held out in time order
+	Chronological bool
+	// Holdout is the share of samples Chronological holds out
+	Holdout float64
+}
+
+// newScriptTask checks the task type of a workflow
+func newScriptTask(taskType string) (scriptTask, error) {
+	if !IsValidTaskType(taskType) {
+		return scriptTask{}, fmt.Errorf("invalid task type %q, must be one of: %s", taskType, strings.Join(TaskTypes, ", "))
+	}
+	if taskType == "" {
+		taskType = DefaultTaskType
+	}
+	return scriptTask{
+		TaskType:      taskType,
+		EncodeLabels:  taskType == TaskClassification,
+		Unsupervised:  taskType == TaskClustering,
+		Chronological: taskType == TaskForecasting,
+		Holdout:       forecastHoldout,
+	}, nil
+}
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+)
+
+// taskWorkflow returns the sample workflow for the given task type, without
+// its loader, its sink and the nodes of the roles in without
+func taskWorkflow(taskType string, without ...string) WorkflowConfig {
+	workflow := SampleWorkflow()
+	workflow.TaskType = taskType
+	without = append(without, RoleLoader, RoleSink)
+	var nodes []Node
+	for _, node := range workflow.Nodes {
+		if !containsString(without, node.Role) {
+			nodes = append(nodes, node)
+		}
+	}
+	workflow.Nodes = nodes
+	return workflow
+}
+
+func TestTaskTypeChangesGeneratedScript(t *testing.T) {
+	tests := []struct {
+		taskType string
+		want     []string
+		unwanted []string
+	}{
+		{
+			taskType: TaskClassification,
+			want:     []string{"LabelEncoder", "model.predict_proba(X_eval)", "classification_metrics(y_encoded, y_pred, y_pred_proba)", "confusion_matrix"},
+		},
+		{
+			taskType: TaskRegression,
+			want:     []string{"train_random_forest(X_for_training, y_encoded, n_estimators=100)", "classification_metrics(y_true, y_pred)"},
+			unwanted: []string{"LabelEncoder", "predict_proba", "confusion_matrix", "Held out the latest"},
+		},
+		{
+			taskType: TaskClustering,
+			want:     []string{"train_random_forest(X_for_training, n_estimators=100)", "cross_validate_model(model, X_for_cv)", "classification_metrics(X_eval, labels)", "model.fit_predict(X_eval)"},
+			unwanted: []string{"LabelEncoder", "y_encoded", "predict_proba"},
+		},
+		{
+			taskType: TaskForecasting,
+			want:     []string{"Held out the latest", "int(len(current_data) * 0.2)", "classification_metrics(y_true, y_pred)"},
+			unwanted: []string{"LabelEncoder", "predict_proba"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.taskType, func(t *testing.T) {
+			script, err := GenerateExecutableScript(taskWorkflow(tt.taskType), "", GenerateOptions{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(script, want) {
+					t.Errorf("script does not contain %q", want)
+				}
+			}
+			for _, unwanted := range tt.unwanted {
+				if strings.Contains(script, unwanted) {
+					t.Errorf("script contains %q", unwanted)
+				}
+			}
+		})
+	}
+}
+
+func TestTaskTypeChangesSklearnPipeline(t *testing.T) {
+	code, err := GenerateSklearnPipeline(taskWorkflow(TaskClustering), "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"task='clustering'", "X_train, y_train = df.drop(columns=targets), None", "cross_validate_model(clone(pipeline), X_train, **{})", "classification_metrics(pipeline[:-1].transform(X_eval), labels, **{})"} {
+		if !strings.Contains(string(code), want) {
+			t.Errorf("clustering pipeline does not contain %q", want)
+		}
+	}
+
+	code, err = GenerateSklearnPipeline(taskWorkflow(TaskForecasting, RoleSplitter), "", GenerateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(code), "cut = len(df) - max(1, int(len(df) * 0.2))") {
+		t.Error("forecasting pipeline without a splitter does not hold out the latest samples")
+	}
+}
+
+func TestInvalidTaskType(t *testing.T) {
+	_, err := GenerateExecutableScript(taskWorkflow("ranking"), "", GenerateOptions{})
+	if err == nil || !strings.Contains(err.Error(), `invalid task type "ranking"`) {
+		t.Fatalf("err = %v, want an invalid task type error", err)
+	}
+}
+
+func TestTaskTypeRequirements(t *testing.T) {
+	if got := WorkflowRequirements(taskWorkflow(TaskClassification), ""); !containsString(got, "scikit-learn") {
+		t.Errorf("classification requirements = %v, want scikit-learn for the label encoder", got)
+	}
+	if got := WorkflowRequirements(taskWorkflow(TaskRegression), ""); containsString(got, "scikit-learn") {
+		t.Errorf("regression requirements = %v, want no scikit-learn", got)
+	}
+}
+
+func TestDefaultTaskTypeKeepsHash(t *testing.T) {
+	if WorkflowHash(taskWorkflow(""), "") != WorkflowHash(taskWorkflow(TaskClassification), "") {
+		t.Error("an explicit classification task type changes the hash")
+	}
+	if WorkflowHash(taskWorkflow(""), "") == WorkflowHash(taskWorkflow(TaskRegression), "") {
+		t.Error("the task type does not change the hash")
+	}
+}
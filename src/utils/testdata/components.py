@@ -0,0 +1,24 @@
+def remove_duplicates(df):
+    return df.drop_duplicates()
+
+
+def train_test_split_data(df, target_column, test_size=0.2):
+    from sklearn.model_selection import train_test_split
+    X = df.drop(columns=[target_column])
+    y = df[target_column]
+    return train_test_split(X, y, test_size=test_size, random_state=42)
+
+
+def scale_features(df, method='standard'):
+    from sklearn.preprocessing import StandardScaler
+    return pd.DataFrame(StandardScaler().fit_transform(df), columns=df.columns, index=df.index)
+
+
+def train_random_forest(X, y, n_estimators=100, random_state=None):
+    from sklearn.ensemble import RandomForestClassifier
+    return RandomForestClassifier(n_estimators=n_estimators, random_state=random_state).fit(X, y)
+
+
+def classification_metrics(y_true, y_pred, y_pred_proba=None):
+    from sklearn.metrics import accuracy_score
+    return {'accuracy': accuracy_score(y_true, y_pred)}
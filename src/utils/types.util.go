@@ -0,0 +1,374 @@
+// src/utils/types.util.go
+package utils
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"builder.ai/src/apperror"
+	"builder.ai/src/models"
+)
+
+// DataType is a type of component inputs and outputs, defined in models
+// where custom types are stored
+type DataType = models.DataTypeDefinition
+
+// Built-in type names
+const (
+	TypeString     = "string"
+	TypeInt        = "int"
+	TypeFloat      = "float"
+	TypeBool       = "bool"
+	TypeList       = "list"
+	TypeDict       = "dict"
+	TypeAny        = "any"
+	TypeDataFrame  = "DataFrame"
+	TypeSeries     = "Series"
+	TypeTuple      = "tuple"
+	TypeArray      = "array"
+	TypeObject     = "object"
+	TypeIterable   = "iterable"
+	TypeDateTime   = "datetime"
+	TypeNdArray    = "ndarray"
+	TypeTensor     = "tensor"
+	TypeFunction   = "function"
+	TypeKerasModel = "keras.model"
+	TypeCallable   = "callable"
+	TypeSparse     = "sparse_matrix"
+	TypeNone       = "none"
+)
+
+// literalSequence accepts list and tuple literals and keeps their kind
+const literalSequence = "sequence"
+
+// LiteralKinds lists how variables of a type can be written
+var LiteralKinds = []string{
+	literalString, literalInt, literalFloat, literalBool, literalList,
+	literalTuple, literalDict, literalSequence, literalNone, literalAny,
+}
+
+// builtinTypes are the types every installation has, in listing order
+var builtinTypes = []DataType{
+	{Name: TypeString, Aliases: []string{"str"}, Parent: TypeObject, PythonHint: "str", Literal: literalString, Description: "Text"},
+	{Name: TypeInt, Aliases: []string{"integer"}, Parent: TypeFloat, PythonHint: "int", Literal: literalInt, Description: "Whole number, accepted where a float is"},
+	{Name: TypeFloat, Aliases: []string{"double", "number"}, Parent: TypeObject, PythonHint: "float", Literal: literalFloat, Description: "Floating point number"},
+	{Name: TypeBool, Aliases: []string{"boolean"}, Parent: TypeInt, PythonHint: "bool", Literal: literalBool, Description: "True or False"},
+	{Name: TypeList, Parent: TypeIterable, PythonHint: "list", Literal: literalList, Description: "List of values"},
+	{Name: TypeTuple, Parent: TypeIterable, PythonHint: "tuple", Literal: literalTuple, Description: "Tuple of values"},
+	{Name: TypeDict, Aliases: []string{"mapping"}, Parent: TypeIterable, PythonHint: "dict", Literal: literalDict, Description: "Mapping of keys to values"},
+	{Name: TypeDataFrame, Aliases: []string{"df", "pd.DataFrame", "pandas.DataFrame"}, Parent: TypeIterable, PythonHint: "pd.DataFrame", Description: "pandas DataFrame"},
+	{Name: TypeSeries, Aliases: []string{"pd.Series", "pandas.Series"}, Parent: TypeIterable, PythonHint: "pd.Series", Description: "pandas Series"},
+	{Name: TypeArray, Parent: TypeIterable, PythonHint: "Sequence", Literal: literalSequence, Description: "Sequence of values"},
+	{Name: TypeNdArray, Aliases: []string{"np.ndarray", "numpy.ndarray"}, Parent: TypeArray, PythonHint: "np.ndarray", Literal: literalSequence, Description: "NumPy array"},
+	{Name: TypeSparse, Aliases: []string{"csr_matrix", "spmatrix"}, Parent: TypeObject, PythonHint: "scipy.sparse.spmatrix", Description: "SciPy sparse matrix"},
+	{Name: TypeTensor, Aliases: []string{"tf.Tensor"}, Parent: TypeObject, PythonHint: "tf.Tensor", Description: "TensorFlow tensor"},
+	{Name: TypeKerasModel, Aliases: []string{"keras_model"}, Parent: TypeObject, PythonHint: "keras.Model", Description: "Keras model"},
+	{Name: TypeDateTime, Parent: TypeObject, PythonHint: "datetime.datetime", Literal: literalString, Description: "Date and time, written as text"},
+	{Name: TypeFunction, Parent: TypeCallable, PythonHint: "Callable", Description: "Function"},
+	{Name: TypeCallable, Parent: TypeObject, PythonHint: "Callable", Description: "Anything that can be called"},
+	{Name: TypeIterable, Parent: TypeObject, PythonHint: "Iterable", Literal: literalAny, Description: "Anything that can be iterated over"},
+	{Name: TypeObject, Parent: TypeAny, PythonHint: "object", Literal: literalAny, Description: "Any Python object"},
+	{Name: TypeAny, PythonHint: "Any", Literal: literalAny, Description: "Any value, unchecked"},
+	{Name: TypeNone, Aliases: []string{"null"}, PythonHint: "None", Literal: literalNone, Description: "No result", OutputOnly: true},
+}
+
+var (
+	typesMu     sync.RWMutex
+	customTypes []DataType
+)
+
+// Types returns the built-in types followed by the custom types by name
+func Types() []DataType {
+	typesMu.RLock()
+	defer typesMu.RUnlock()
+	return append(append([]DataType(nil), builtinTypes...), customTypes...)
+}
+
+// GetType returns the type with the given name or alias
+func GetType(name string) (DataType, bool) {
+	name = strings.TrimSpace(name)
+	for _, t := range Types() {
+		if strings.EqualFold(t.Name, name) {
+			return t, true
+		}
+		for _, alias := range t.Aliases {
+			if strings.EqualFold(alias, name) {
+				return t, true
+			}
+		}
+	}
+	return DataType{}, false
+}
+
+// CanonicalType returns the canonical name of a type, or name unchanged
+// when no type has it
+func CanonicalType(name string) string {
+	if t, ok := GetType(name); ok {
+		return t.Name
+	}
+	return name
+}
+
+// IsValidInputType reports whether name is a type of inputs
+func IsValidInputType(name string) bool {
+	t, ok := GetType(name)
+	return ok && !t.OutputOnly
+}
+
+// IsValidOutputType reports whether name is a type of outputs
+func IsValidOutputType(name string) bool {
+	_, ok := GetType(name)
+	return ok
+}
+
+// InputTypeNames returns the canonical names of the input types
+func InputTypeNames() []string {
+	var names []string
+	for _, t := range Types() {
+		if !t.OutputOnly {
+			names = append(names, t.Name)
+		}
+	}
+	return names
+}
+
+// OutputTypeNames returns the canonical names of the output types
+func OutputTypeNames() []string {
+	var names []string
+	for _, t := range Types() {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// IsSubtype reports whether values of type t can be used where type of
+// is expected: t is of, one of its ancestors, or of is any
+func IsSubtype(t, of string) bool {
+	target, ok := GetType(of)
+	if !ok {
+		return false
+	}
+	if target.Name == TypeAny {
+		return true
+	}
+	current, ok := GetType(t)
+	for seen := map[string]bool{}; ok && !seen[current.Name]; current, ok = GetType(current.Parent) {
+		if current.Name == target.Name {
+			return true
+		}
+		seen[current.Name] = true
+	}
+	return false
+}
+
+// PythonTypeHint returns the Python annotation of a type, Any when the
+// type is unknown
+func PythonTypeHint(name string) string {
+	if t, ok := GetType(name); ok {
+		return t.PythonHint
+	}
+	return "Any"
+}
+
+// literalKind returns how variables of a declared type are written; an
+// undeclared type accepts any literal and an unknown one none
+func literalKind(declared string) string {
+	if strings.TrimSpace(declared) == "" {
+		return literalAny
+	}
+	t, _ := GetType(declared)
+	return t.Literal
+}
+
+// typeNamePattern matches names such as "probability" or "geo.point"
+var typeNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_.]{0,63}$`)
+
+// IsValidTypeName checks a type name or alias
+func IsValidTypeName(name string) bool {
+	return typeNamePattern.MatchString(name)
+}
+
+// SetCustomTypes replaces the user-defined types. Each one extends a
+// parent, built-in or custom, and writes its variables like the parent
+// unless it states a literal kind.
+func SetCustomTypes(types []DataType) error {
+	resolved, err := resolveCustomTypes(types)
+	if err != nil {
+		return err
+	}
+
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	customTypes = resolved
+	return nil
+}
+
+// CheckCustomTypes reports the first problem with a set of custom types
+// without registering them
+func CheckCustomTypes(types []DataType) error {
+	_, err := resolveCustomTypes(types)
+	return err
+}
+
+// resolveCustomTypes checks custom types and fills in inherited literal
+// kinds, parents first; the result is sorted by name
+func resolveCustomTypes(types []DataType) ([]DataType, error) {
+	taken := map[string]string{}
+	claim := func(name, owner string) error {
+		key := strings.ToLower(name)
+		if other, ok := taken[key]; ok {
+			return fmt.Errorf("type %q: %q is already a name or alias of %q", owner, name, other)
+		}
+		taken[key] = owner
+		return nil
+	}
+	for _, t := range builtinTypes {
+		claim(t.Name, t.Name)
+		for _, alias := range t.Aliases {
+			claim(alias, t.Name)
+		}
+	}
+
+	byName := map[string]DataType{}
+	for _, t := range types {
+		if err := checkType(t); err != nil {
+			return nil, fmt.Errorf("type %q: %w", t.Name, err)
+		}
+		if err := claim(t.Name, t.Name); err != nil {
+			return nil, err
+		}
+		for _, alias := range t.Aliases {
+			if err := claim(alias, t.Name); err != nil {
+				return nil, err
+			}
+		}
+		t.Custom = true
+		byName[strings.ToLower(t.Name)] = t
+	}
+
+	// Resolve parents depth first, detecting cycles
+	literals := map[string]string{}
+	for _, t := range builtinTypes {
+		literals[t.Name] = t.Literal
+	}
+	var resolve func(name string, path []string) (string, error)
+	resolve = func(name string, path []string) (string, error) {
+		canonical, ok := taken[strings.ToLower(name)]
+		if !ok {
+			return "", fmt.Errorf("type %q: unknown parent type %q", path[len(path)-1], name)
+		}
+		if literal, ok := literals[canonical]; ok {
+			return literal, nil
+		}
+		if containsString(path, canonical) {
+			return "", fmt.Errorf("type %q: parent types form a cycle: %s", canonical, strings.Join(append(path, canonical), " -> "))
+		}
+		t := byName[strings.ToLower(canonical)]
+		literal, err := resolve(t.Parent, append(path, canonical))
+		if err != nil {
+			return "", err
+		}
+		if t.Literal != "" {
+			literal = t.Literal
+		}
+		literals[canonical] = literal
+		return literal, nil
+	}
+
+	resolved := make([]DataType, 0, len(byName))
+	for _, t := range byName {
+		literal, err := resolve(t.Name, nil)
+		if err != nil {
+			return nil, err
+		}
+		t.Literal = literal
+		t.Parent = taken[strings.ToLower(t.Parent)]
+		resolved = append(resolved, t)
+	}
+	sort.Slice(resolved, func(i, j int) bool { return resolved[i].Name < resolved[j].Name })
+	return resolved, nil
+}
+
+// ValidateDataType checks the fields of a type sent to the API on their
+// own and returns one FieldError per problem; clashes with other types are
+// checked when the types are registered
+func ValidateDataType(t *models.DataType) []apperror.FieldError {
+	var fields []apperror.FieldError
+
+	if !IsValidTypeName(t.Name) {
+		fields = append(fields, apperror.Field("name", "type_name",
+			"must be 1-64 letters, digits, '_' or '.' starting with a letter"))
+	}
+	for i, alias := range t.Aliases {
+		if !IsValidTypeName(alias) {
+			fields = append(fields, apperror.Field(fmt.Sprintf("aliases[%d]", i), "type_name",
+				"must be 1-64 letters, digits, '_' or '.' starting with a letter"))
+		}
+	}
+	if strings.TrimSpace(t.Parent) == "" {
+		fields = append(fields, apperror.Field("parent", "required", "is required"))
+	}
+	if t.Literal != "" && !containsString(LiteralKinds, t.Literal) {
+		fields = append(fields, apperror.Field("literal", "oneof",
+			fmt.Sprintf("invalid literal %q, must be one of: %s", t.Literal, strings.Join(LiteralKinds, ", "))))
+	}
+
+	return fields
+}
+
+// DefaultPythonHint annotates a type without a Python hint like its parent
+func DefaultPythonHint(t *models.DataType) {
+	if t.PythonHint == "" {
+		t.PythonHint = PythonTypeHint(t.Parent)
+	}
+}
+
+// checkType reports the first problem with a custom type definition
+func checkType(t DataType) error {
+	switch {
+	case !IsValidTypeName(t.Name):
+		return fmt.Errorf("invalid name %q, must be 1-64 letters, digits, '_' or '.' starting with a letter", t.Name)
+	case strings.TrimSpace(t.Parent) == "":
+		return fmt.Errorf("parent is required")
+	case t.Literal != "" && !containsString(LiteralKinds, t.Literal):
+		return fmt.Errorf("invalid literal %q, must be one of: %s", t.Literal, strings.Join(LiteralKinds, ", "))
+	}
+	for _, alias := range t.Aliases {
+		if !IsValidTypeName(alias) {
+			return fmt.Errorf("invalid alias %q, must be 1-64 letters, digits, '_' or '.' starting with a letter", alias)
+		}
+	}
+	return nil
+}
+
+// TypeHintConflict is an input whose declared type and the annotation of
+// the matching Python parameter are unrelated types
+type TypeHintConflict struct {
+	Input    string
+	Declared string
+	Hint     string
+}
+
+// TypeHintConflicts compares the declared input types of a Python
+// component with the annotations of its function. Annotations that are not
+// a known type, such as Optional[int], are not checked.
+func TypeHintConflicts(code string, inputTypes map[string]string) []TypeHintConflict {
+	var conflicts []TypeHintConflict
+	for _, param := range signatureParams(code, FunctionName(code)) {
+		declared, ok := inputTypes[param.Name]
+		if !ok || param.Annotation == "" {
+			continue
+		}
+		hint, ok := GetType(param.Annotation)
+		if !ok || !IsValidInputType(declared) {
+			continue
+		}
+		if !IsSubtype(declared, hint.Name) && !IsSubtype(hint.Name, declared) {
+			conflicts = append(conflicts, TypeHintConflict{Input: param.Name, Declared: CanonicalType(declared), Hint: param.Annotation})
+		}
+	}
+	return conflicts
+}
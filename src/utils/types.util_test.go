@@ -0,0 +1,129 @@
+package utils
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// setTestTypes registers custom types for the test and removes them after
+func setTestTypes(t *testing.T, types []DataType) {
+	t.Helper()
+	if err := SetCustomTypes(types); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { SetCustomTypes(nil) })
+}
+
+func TestGetTypeMatchesAliases(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"DataFrame", TypeDataFrame},
+		{"dataframe", TypeDataFrame},
+		{"pd.DataFrame", TypeDataFrame},
+		{"series", TypeSeries},
+		{"str", TypeString},
+		{"Boolean", TypeBool},
+		{"sparse_matrix", TypeSparse},
+	}
+	for _, tt := range tests {
+		if got := CanonicalType(tt.name); got != tt.want {
+			t.Errorf("CanonicalType(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+	if got := CanonicalType("matrix"); got != "matrix" {
+		t.Errorf("CanonicalType of an unknown type = %q", got)
+	}
+	if IsValidInputType("none") || !IsValidOutputType("None") {
+		t.Error("none must be an output type only")
+	}
+}
+
+func TestIsSubtype(t *testing.T) {
+	tests := []struct {
+		t, of string
+		want  bool
+	}{
+		{TypeSeries, TypeIterable, true},
+		{"dataframe", TypeObject, true},
+		{TypeBool, TypeFloat, true},
+		{TypeFloat, TypeInt, false},
+		{TypeNdArray, TypeArray, true},
+		{TypeList, TypeDataFrame, false},
+		{TypeDataFrame, TypeAny, true},
+		{"matrix", TypeAny, true},
+		{TypeString, "matrix", false},
+	}
+	for _, tt := range tests {
+		if got := IsSubtype(tt.t, tt.of); got != tt.want {
+			t.Errorf("IsSubtype(%q, %q) = %v, want %v", tt.t, tt.of, got, tt.want)
+		}
+	}
+}
+
+func TestCustomTypes(t *testing.T) {
+	setTestTypes(t, []DataType{
+		{Name: "probability", Aliases: []string{"prob"}, Parent: "number"},
+		{Name: "geo.point", Parent: "coordinates", PythonHint: "Point"},
+		{Name: "coordinates", Parent: TypeTuple},
+	})
+
+	if got := strings.Join(InputTypeNames()[len(builtinTypes)-1:], ","); got != "coordinates,geo.point,probability" {
+		t.Errorf("custom types = %s, want them sorted by name after the built-in types", got)
+	}
+	prob, ok := GetType("PROB")
+	if !ok || !prob.Custom || prob.Parent != TypeFloat || prob.Literal != literalFloat {
+		t.Errorf("GetType(PROB) = %+v, %v", prob, ok)
+	}
+	if !IsSubtype("geo.point", TypeIterable) || PythonTypeHint("geo.point") != "Point" {
+		t.Error("geo.point does not extend coordinates")
+	}
+
+	if got, err := PythonLiteral("0.5", "prob"); err != nil || got != "0.5" {
+		t.Errorf("PythonLiteral(0.5, prob) = %q, %v", got, err)
+	}
+	if _, err := PythonLiteral("'high'", "probability"); err == nil {
+		t.Error("string accepted for a float type")
+	}
+	if got, err := PythonLiteral("[1, 2]", "geo.point"); err != nil || got != "(1, 2)" {
+		t.Errorf("PythonLiteral([1, 2], geo.point) = %q, %v", got, err)
+	}
+}
+
+func TestCustomTypesRejectsInvalidTypes(t *testing.T) {
+	tests := []struct {
+		name  string
+		types []DataType
+		want  string
+	}{
+		{"bad name", []DataType{{Name: "1x", Parent: TypeAny}}, "invalid name"},
+		{"no parent", []DataType{{Name: "x"}}, "parent is required"},
+		{"bad literal", []DataType{{Name: "x", Parent: TypeAny, Literal: "set"}}, `invalid literal "set"`},
+		{"builtin name", []DataType{{Name: "Dataframe", Parent: TypeAny}}, `already a name or alias of "DataFrame"`},
+		{"alias taken", []DataType{{Name: "x", Parent: TypeAny}, {Name: "y", Aliases: []string{"X"}, Parent: TypeAny}}, `already a name or alias of "x"`},
+		{"unknown parent", []DataType{{Name: "x", Parent: "y"}}, `unknown parent type "y"`},
+		{"cycle", []DataType{{Name: "x", Parent: "y"}, {Name: "y", Parent: "x"}}, "cycle"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := SetCustomTypes(tt.types); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("SetCustomTypes() error = %v, want %q", err, tt.want)
+			}
+			if len(Types()) != len(builtinTypes) {
+				t.Error("invalid types were registered")
+			}
+		})
+	}
+}
+
+func TestTypeHintConflicts(t *testing.T) {
+	code := "def f(df: pd.DataFrame, n: int = 3, name: str = 'a', cols: Optional[list] = None, rate: float = 0.1):\n    return df\n"
+	types := map[string]string{"df": "dataframe", "n": TypeBool, "name": TypeList, "cols": TypeString, "rate": TypeInt}
+
+	want := []TypeHintConflict{{Input: "name", Declared: TypeList, Hint: "str"}}
+	if got := TypeHintConflicts(code, types); !reflect.DeepEqual(got, want) {
+		t.Errorf("TypeHintConflicts() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,270 @@
+// src/utils/xlsxProfile.util.go
+package utils
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// profileXLSX profiles the first worksheet of an Excel workbook, whose
+// first row is the header. Rows are streamed; only the shared strings are
+// held in memory. Dates stored as serial numbers are profiled as numbers.
+func profileXLSX(r io.ReaderAt, size int64) (*DatasetProfile, error) {
+	archive, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, fmt.Errorf("not an XLSX file: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range archive.File {
+		files[f.Name] = f
+	}
+
+	sheet, err := firstSheet(files)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := sharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+
+	rc, err := sheet.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	var p *profiler
+	err = readSheetRows(rc, shared, func(cells []string) {
+		if p == nil {
+			p = newProfiler(cells)
+			return
+		}
+		p.addRow(cells)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, errors.New("empty worksheet")
+	}
+	return p.result(), nil
+}
+
+// firstSheet finds the first worksheet listed in the workbook
+func firstSheet(files map[string]*zip.File) (*zip.File, error) {
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	var rels struct {
+		Relationships []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(files["xl/workbook.xml"], &workbook); err == nil && len(workbook.Sheets) > 0 {
+		if err := decodeZipXML(files["xl/_rels/workbook.xml.rels"], &rels); err == nil {
+			for _, rel := range rels.Relationships {
+				if rel.ID != workbook.Sheets[0].ID {
+					continue
+				}
+				name := path.Clean(path.Join("xl", rel.Target))
+				if strings.HasPrefix(rel.Target, "/") {
+					name = strings.TrimPrefix(rel.Target, "/")
+				}
+				if f, ok := files[name]; ok {
+					return f, nil
+				}
+			}
+		}
+	}
+	if f, ok := files["xl/worksheets/sheet1.xml"]; ok {
+		return f, nil
+	}
+	return nil, errors.New("not an XLSX file: no worksheet")
+}
+
+func decodeZipXML(f *zip.File, v interface{}) error {
+	if f == nil {
+		return errors.New("missing part")
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return err
+	}
+	defer rc.Close()
+	return xml.NewDecoder(rc).Decode(v)
+}
+
+// maxSharedStrings caps the entries of a workbook's string table, which is
+// held in memory while the sheet is read
+const maxSharedStrings = 1 << 20
+
+// maxSharedStringsSize caps the decompressed size of the string table
+var maxSharedStringsSize int64 = 64 << 20
+
+// sharedStrings reads the workbook's string table; rich text runs are
+// joined
+func sharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	limited := &io.LimitedReader{R: rc, N: maxSharedStringsSize + 1}
+	var strs []string
+	var sb strings.Builder
+	inText := false
+	dec := xml.NewDecoder(limited)
+	for {
+		tok, err := dec.Token()
+		if limited.N <= 0 {
+			return nil, fmt.Errorf("shared strings exceed %d bytes", maxSharedStringsSize)
+		}
+		if errors.Is(err, io.EOF) {
+			return strs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "si":
+				sb.Reset()
+			case "t":
+				inText = true
+			case "rPh":
+				// Phonetic hints are not part of the text
+				if err := dec.Skip(); err != nil {
+					return nil, err
+				}
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "si":
+				if len(strs) == maxSharedStrings {
+					return nil, fmt.Errorf("more than %d shared strings", maxSharedStrings)
+				}
+				strs = append(strs, sb.String())
+			case "t":
+				inText = false
+			}
+		case xml.CharData:
+			if inText {
+				sb.Write(t)
+			}
+		}
+	}
+}
+
+// readSheetRows calls fn with the cell values of every row of a worksheet,
+// placed by their column reference so empty cells stay empty
+func readSheetRows(r io.Reader, shared []string, fn func(cells []string)) error {
+	var row []string
+	var cellType, cellRef string
+	var value strings.Builder
+	inValue := false
+
+	dec := xml.NewDecoder(r)
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "row":
+				row = row[:0]
+			case "c":
+				cellType, cellRef = "", ""
+				for _, attr := range t.Attr {
+					switch attr.Name.Local {
+					case "t":
+						cellType = attr.Value
+					case "r":
+						cellRef = attr.Value
+					}
+				}
+				value.Reset()
+			case "v", "t":
+				inValue = true
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "v", "t":
+				inValue = false
+			case "c":
+				col := len(row)
+				if i, ok := columnIndex(cellRef); ok {
+					col = i
+				}
+				for len(row) <= col {
+					row = append(row, "")
+				}
+				row[col] = cellValue(cellType, value.String(), shared)
+			case "row":
+				fn(row)
+			}
+		case xml.CharData:
+			if inValue {
+				value.Write(t)
+			}
+		}
+	}
+}
+
+// cellValue turns a cell into the text a CSV cell would hold
+func cellValue(cellType, raw string, shared []string) string {
+	switch cellType {
+	case "s":
+		i, err := strconv.Atoi(raw)
+		if err != nil || i < 0 || i >= len(shared) {
+			return ""
+		}
+		return shared[i]
+	case "b":
+		if raw == "1" {
+			return "true"
+		}
+		return "false"
+	case "e":
+		// Formula errors such as #N/A are missing values
+		return ""
+	}
+	return raw
+}
+
+// columnIndex returns the zero-based column of a cell reference such as
+// "AB12"
+func columnIndex(ref string) (int, bool) {
+	col := 0
+	n := 0
+	for _, r := range ref {
+		if r < 'A' || r > 'Z' {
+			break
+		}
+		col = col*26 + int(r-'A'+1)
+		n++
+	}
+	if n == 0 || n > 3 {
+		return 0, false
+	}
+	return col - 1, true
+}